@@ -0,0 +1,83 @@
+package summer
+
+import (
+	"mime"
+	"strconv"
+	"strings"
+)
+
+// acceptMediaRange a media range of "Accept" header with quality factor
+type acceptMediaRange struct {
+	mediaType string
+	q         float64
+}
+
+// parseAccept parse "Accept" header into media ranges, invalid entries are skipped
+func parseAccept(s string) (ranges []acceptMediaRange) {
+	for _, item := range strings.Split(s, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(item))
+		if err != nil {
+			continue
+		}
+		r := acceptMediaRange{mediaType: mediaType, q: 1}
+		if v, ok := params["q"]; ok {
+			if r.q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		ranges = append(ranges, r)
+	}
+	return
+}
+
+// qualityOf returns quality factor of media type t in ranges, most specific media range wins
+func qualityOf(ranges []acceptMediaRange, t string) float64 {
+	t = strings.ToLower(t)
+	major, _, _ := strings.Cut(t, "/")
+
+	q, specificity := 0.0, -1
+	for _, r := range ranges {
+		var s int
+		switch {
+		case r.mediaType == t:
+			s = 2
+		case r.mediaType == major+"/*":
+			s = 1
+		case r.mediaType == "*/*":
+			s = 0
+		default:
+			continue
+		}
+		if s > specificity {
+			q, specificity = r.q, s
+		}
+	}
+	return q
+}
+
+// accepts check if "Accept" header accepts any of types, with a quality factor greater than 0
+//
+// Most specific media range wins, for example "text/*;q=0, text/plain" accepts "text/plain"
+func accepts(accept string, types []string) bool {
+	ranges := parseAccept(accept)
+	for _, t := range types {
+		if qualityOf(ranges, t) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// negotiate returns the type with the highest quality factor in "Accept" header, earlier types win ties
+//
+// The first type is returned if "Accept" header is empty or accepts none of types
+func negotiate(accept string, types []string) string {
+	ranges := parseAccept(accept)
+	best, bestQ := types[0], 0.0
+	for _, t := range types {
+		if q := qualityOf(ranges, t); q > bestQ {
+			best, bestQ = t, q
+		}
+	}
+	return best
+}
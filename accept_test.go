@@ -0,0 +1,55 @@
+package summer
+
+import (
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAccepts(t *testing.T) {
+	types := []string{"application/json"}
+	require.True(t, accepts("*/*", types))
+	require.True(t, accepts("application/*", types))
+	require.True(t, accepts("text/html, Application/JSON;q=0.5", types))
+	require.True(t, accepts("application/*;q=0, application/json", types))
+	require.False(t, accepts("application/json;q=0", types))
+	require.False(t, accepts("*/*, application/json;q=0", types))
+	require.False(t, accepts("text/html", types))
+	require.False(t, accepts("", types))
+}
+
+func TestNegotiate(t *testing.T) {
+	types := []string{"application/json", "application/msgpack", "application/xml"}
+	require.Equal(t, "application/json", negotiate("", types))
+	require.Equal(t, "application/json", negotiate("*/*", types))
+	require.Equal(t, "application/json", negotiate("text/html", types))
+	require.Equal(t, "application/msgpack", negotiate("application/msgpack", types))
+	require.Equal(t, "application/xml", negotiate("application/json;q=0.5, application/xml", types))
+	require.Equal(t, "application/msgpack", negotiate("application/*, application/json;q=0", types))
+}
+
+func TestAppRequiredAccept(t *testing.T) {
+	a := Basic(WithRequiredAccept([]string{"application/json", "application/msgpack"}))
+	a.HandleFunc("/test", func(ctx Context) {
+		ctx.Text("OK")
+	})
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set("Accept", "text/html")
+	rw := httptest.NewRecorder()
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusNotAcceptable, rw.Code)
+	require.Equal(t, "NOT ACCEPTABLE, SUPPORTED: application/json, application/msgpack", rw.Body.String())
+
+	req = httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set("Accept", "*/*")
+	rw = httptest.NewRecorder()
+	a.ServeHTTP(rw, req)
+	require.Equal(t, "OK", rw.Body.String())
+
+	a.HandleFunc("/any", func(ctx Context) {
+		ctx.Text("OK")
+	}, WithRequiredAccept(nil))
+	require.Equal(t, "OK", a.TestRequest("GET", "/any", nil).Body.String())
+}
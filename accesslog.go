@@ -0,0 +1,49 @@
+package summer
+
+import (
+	"log/slog"
+	"time"
+)
+
+// AccessLogEntry an entry of access log, passed to function set by [WithAccessLogger]
+type AccessLogEntry struct {
+	Method string
+	Path   string
+	// Route pattern of the matched route, empty if no route matched
+	Route    string
+	Status   int
+	Bytes    int64
+	Duration time.Duration
+	ClientIP string
+	// TraceID trace id of the request span, empty if tracing is not set up
+	TraceID string
+	// RequestID request id, see [WithRequestIDHeader]
+	RequestID string
+}
+
+// accessLogRoute route information filled by the matched route, for [AccessLogEntry]
+type accessLogRoute struct {
+	pattern   string
+	traceID   string
+	requestID string
+}
+
+type contextKeyAccessLogRoute struct{}
+
+// SlogAccessLogger create an access log function writing entries to logger at info level, for [WithAccessLogger]
+func SlogAccessLogger(logger *slog.Logger) func(entry AccessLogEntry) {
+	return func(entry AccessLogEntry) {
+		logger.Info(
+			"access",
+			"method", entry.Method,
+			"path", entry.Path,
+			"route", entry.Route,
+			"status", entry.Status,
+			"bytes", entry.Bytes,
+			"duration", entry.Duration,
+			"client_ip", entry.ClientIP,
+			"trace_id", entry.TraceID,
+			"request_id", entry.RequestID,
+		)
+	}
+}
@@ -1,12 +1,27 @@
 package summer
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"io"
+	"io/fs"
+	"net"
 	"net/http"
+	"net/http/httptest"
 	"net/http/pprof"
+	"net/url"
+	"path"
+	"runtime/debug"
 	"strings"
+	"sync"
 	"sync/atomic"
+	"time"
 )
 
 // HandlerFunc handler func with [Context] as argument
@@ -20,102 +35,1070 @@ type App[T Context] interface {
 	// Registry inherit [Registry]
 	Registry
 
+	// MethodRouter inherit [MethodRouter]
+	MethodRouter[T]
+
 	// HandleFunc register an action function with given path pattern
 	//
-	// This function is similar with [http.ServeMux.HandleFunc]
-	HandleFunc(pattern string, fn HandlerFunc[T])
+	// This function is similar with [http.ServeMux.HandleFunc], patterns like "/users/{id}" are supported, see [Context.PathParam]
+	//
+	// The pattern, instead of the actual path, is used as route tag of metrics and traces
+	//
+	// Additional [Option] overrides options of [App] for this route
+	HandleFunc(pattern string, fn HandlerFunc[T], opts ...Option)
+
+	// HandleFuncUnthrottled register an action function like [App.HandleFunc], but bypassing the concurrency control
+	HandleFuncUnthrottled(pattern string, fn HandlerFunc[T], opts ...Option)
+
+	// HandleFuncUpgrade register an action function for long-lived connections, like WebSocket, taking over the connection with [Context.Hijack]
+	//
+	// The route bypasses the concurrency control and GET deduplication
+	HandleFuncUpgrade(pattern string, fn HandlerFunc[T], opts ...Option)
+
+	// HandleWebSocket register a WebSocket handler with given path pattern, like [App.HandleFuncUpgrade]
+	//
+	// The connection is upgraded with [websocket.Upgrader] set by [WithWebSocketUpgrader], and closed after fn returns.
+	// Span of the request lasts for the connection lifetime, active connections are exposed as gauge "summer_websocket_connections"
+	HandleWebSocket(pattern string, fn WebSocketHandlerFunc[T], opts ...Option)
+
+	// HandleFS register a file server of [fs.FS] with given path pattern, suitable for [embed.FS]
+	//
+	// Path prefix of pattern is stripped before looking up files, method and host of pattern are allowed
+	HandleFS(pattern string, fsys fs.FS)
+
+	// Static serve files of OS directory dir under path prefix, like [App.StaticFS]
+	Static(prefix string, dir string, opts ...StaticOption)
+
+	// StaticFS serve files of [fs.FS] under path prefix, with "Cache-Control" and "ETag" headers, see [StaticOption]
+	//
+	// Directories are served with their "index.html", without listing. ETag of files without modification time,
+	// like files of [embed.FS], is a hash of content, computed once per file for [embed.FS]
+	StaticFS(prefix string, fsys fs.FS, opts ...StaticOption)
+
+	// OnEachRequest register a function to be called synchronously before every non-debug request
+	//
+	// Functions are called in registration order, without access to the [http.ResponseWriter]
+	OnEachRequest(fn func(req *http.Request))
+
+	// Use register middlewares wrapping every [HandlerFunc] registered by [App.HandleFunc] and its variants
+	//
+	// Middlewares are called in registration order, outside of middlewares of [Group], including routes registered before.
+	// It is safe to call while serving, requests already started are not affected
+	Use(mws ...MiddlewareFunc[T])
+
+	// Group create a [Group] with path prefix and middlewares
+	Group(prefix string, mws ...MiddlewareFunc[T]) Group[T]
+
+	// DeregisterRoute deregister a route with pattern, requests matching it are responded with 404
+	//
+	// [http.ServeMux] does not support deregistration, requests are NOT routed to other patterns
+	DeregisterRoute(pattern string) error
+
+	// ReplaceRoute hot-swap the action function of a registered route, with options like [App.HandleFunc]
+	//
+	// In-flight requests finish with the previous action function, deregistration state is kept
+	ReplaceRoute(pattern string, fn HandlerFunc[T], opts ...Option) error
+
+	// RegisterRoute re-enable a route deregistered by [App.DeregisterRoute]
+	RegisterRoute(pattern string) error
+
+	// Patterns returns all registered path patterns, in registration order
+	Patterns() []string
+
+	// ListRoutes returns registered routes filtered by method and path prefix, in registration order
+	//
+	// method is case-insensitive, empty method matches all routes, routes without method match any method
+	ListRoutes(method, prefix string) []RouteEntry
+
+	// OpenAPI generate an OpenAPI 3.1 document of registered routes, documented by [WithOperation]
+	//
+	// Only routes with method, like registered by [MethodRouter], are included. Served at [OpenAPIPath] if enabled by [WithOpenAPI]
+	OpenAPI() map[string]any
+
+	// CheckStartupFunc register a check of startup probe, replacing the check with the same name
+	//
+	// Startup probe at path set by [WithStartupPath] responds 503 until all checks pass, and 200 forever afterwards
+	CheckStartupFunc(name string, fn LifecycleFunc)
+
+	// CheckLiveFunc register a check of liveness probe, replacing the check with the same name
+	//
+	// Liveness probe responds 500 if any check fails, checks of [Registry] are only used by readiness probe
+	CheckLiveFunc(name string, fn LifecycleFunc)
+
+	// CheckHTTP register a component named name, checking url responds with a 2xx status code
+	//
+	// All checks share a [http.Client], dialer can be customized by [WithCheckHTTPDialer]
+	CheckHTTP(name string, url string) Registration
+
+	// Go register a long-running worker named name, started and cancelled with components, see [Registry]
+	//
+	// fn should return after ctx is done. A returned error or panic restarts fn with backoff set by [WithWorkerBackoff],
+	// while the check of component named name fails with [ErrWorkerNotRunning]. Returning nil finishes the worker
+	Go(name string, fn func(ctx context.Context) error)
+
+	// Cron register a component named name, running fn at times of spec, a standard 5-field cron expression or descriptor like "@hourly" and "@every 5m"
+	//
+	// Runs never overlap, times missed by a long run are skipped. ctx of fn is cancelled on shutdown.
+	// Each run is traced and recorded by "summer_cron_*" metrics, invalid spec or spec never matching like "0 0 30 2 *" causes a panic
+	Cron(spec string, name string, fn func(ctx context.Context) error)
+
+	// RegisterDebugHandler register a [http.Handler] created by fn at path of debug endpoints
+	//
+	// path must start with "/debug/", and must not be a path of built-in debug endpoints like "/debug/ready" and "/debug/metrics", or it panics.
+	// fn is called immediately with the [App]
+	RegisterDebugHandler(path string, fn func(app App[T]) http.Handler)
+
+	// Run startup all components, serve http on addr, and shutdown everything after ctx is done or a shutdown signal received
+	//
+	// See [WithShutdownSignals], [WithDrainPeriod] and [WithShutdownTimeout] for graceful shutdown
+	Run(ctx context.Context, addr string) (err error)
+
+	// TestRequest serve a request created by [httptest.NewRequest], returns the recorded response, for testing
+	TestRequest(method, target string, body io.Reader) *httptest.ResponseRecorder
+
+	// OptionsSnapshot returns a snapshot of configured options, for debugging
+	OptionsSnapshot() OptionsInfo
 }
 
 type app[T Context] struct {
 	// before-init
 	Registry
+	registry *registry
 
 	cf   ContextFactory[T]
 	opts options
 
-	mux *http.ServeMux
+	mux      *http.ServeMux
+	routesMu sync.RWMutex
+	routes   map[string]*route
+	patterns []string
 
 	hMain http.Handler
 	hProm http.Handler
-	hProf http.Handler
+	hProf *http.ServeMux
+
+	cc       chan struct{}
+	ccQueued int64
+
+	mQueueDepth       prometheus.Gauge
+	mAvailable        prometheus.Gauge
+	mRequestDuration  *prometheus.HistogramVec
+	mOpenConnections  prometheus.Gauge
+	mRejectedRequests *prometheus.CounterVec
+	mCascade          prometheus.Counter
+	mRouteInFlight    *prometheus.GaugeVec
+	mRouteQueueDepth  *prometheus.GaugeVec
+	mPanics           *prometheus.CounterVec
+	mWebSockets       prometheus.Gauge
+	mWorkerRunning    *prometheus.GaugeVec
+	mWorkerFailures   *prometheus.CounterVec
+	mCronRuns         *prometheus.CounterVec
+	mCronDuration     *prometheus.HistogramVec
+	mCronLastSuccess  *prometheus.GaugeVec
+	mHTTPRequests     *prometheus.CounterVec
+	mHTTPRequestSize  *prometheus.HistogramVec
+	mHTTPResponseSize *prometheus.HistogramVec
+	mHTTPInFlight     *prometheus.GaugeVec
+
+	requests  int64
+	startedAt time.Time
+
+	onEachRequest []func(req *http.Request)
+
+	// mws middlewares of Use, replaced as a whole under mwsMu, safe to load while serving
+	mws   atomic.Pointer[[]MiddlewareFunc[T]]
+	mwsMu sync.Mutex
+
+	dedup dedupGroup
+	// dedupUsed any route registered with deduplication enabled
+	dedupUsed atomic.Bool
+
+	checkClient *http.Client
+
+	readinessFailed      int64
+	readinessFailedSince int64
 
-	cc chan struct{}
+	saturationWarnedAt int64
 
-	readinessFailed int64
+	draining int32
+
+	startupChecks probeChecks
+	liveChecks    probeChecks
+	started       int32
+
+	cors *corsPolicy
+}
+
+func (a *app[T]) HandleFunc(pattern string, fn HandlerFunc[T], opts ...Option) {
+	a.handleFunc(pattern, fn, opts...)
+}
+
+// handleFunc register fn with pattern, returns the registered route, flags of a previous registration are reset
+func (a *app[T]) handleFunc(pattern string, fn HandlerFunc[T], opts ...Option) *route {
+	r := a.handle(pattern, a.wrap(pattern, fn, opts...), a.routeDeduplication(opts))
+	r.operation.Store(routeOperation(fn, opts))
+	r.unthrottled.Store(false)
+	r.upgrade.Store(false)
+	return r
 }
 
-func (a *app[T]) HandleFunc(pattern string, fn HandlerFunc[T]) {
+// routeDeduplication returns if deduplication is enabled for a route with opts, see [WithGetDeduplication]
+//
+// Routes with middlewares or rate limit are never deduplicated, since waiters receive the response without passing them
+func (a *app[T]) routeDeduplication(opts []Option) bool {
+	ropts := a.opts
+	for _, opt := range opts {
+		opt(&ropts)
+	}
+	enabled := ropts.getDeduplication && !ropts.routeMiddlewares && ropts.rateLimit.Limit <= 0
+	if enabled {
+		a.dedupUsed.Store(true)
+	}
+	return enabled
+}
+
+// wrap create a [http.Handler] serving fn with options of route
+func (a *app[T]) wrap(pattern string, fn HandlerFunc[T], opts ...Option) http.Handler {
+	ropts := a.opts
+	for _, opt := range opts {
+		opt(&ropts)
+	}
+
+	// limiters of groups, from outer to inner, then of route
+	limiters := append([]*routeLimiter{}, ropts.groupLimiters...)
+	if rc := ropts.routeConcurrency; rc.Limit > 0 {
+		limiters = append(limiters, newRouteLimiter(rc, a.mRouteInFlight.WithLabelValues(pattern), a.mRouteQueueDepth.WithLabelValues(pattern)))
+	}
+
+	var rateLimiter *rateLimiter
+	if ropts.rateLimit.Limit > 0 {
+		rateLimiter = newRateLimiter(ropts.rateLimit)
+	}
+
+	if ropts.jsonSchema != nil {
+		fn = validateRequestSchema(fn, ropts.jsonSchema)
+	}
+
+	var compression *compressionPolicy
+	if ropts.compression != nil {
+		compression = newCompressionPolicy(*ropts.compression)
+	}
+
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		defer a.recoverEscaped(rw, req, pattern)
+		req = req.WithContext(context.WithValue(context.WithValue(req.Context(), contextKeyOptions{}, &ropts), contextKeyForwarder{}, a))
+		if rateLimiter != nil {
+			if allowed, retryAfter, err := rateLimiter.take(req); err != nil {
+				a.opts.logger.Warn("failed to take rate limit", "route", pattern, "error", err.Error())
+			} else if !allowed {
+				a.mRejectedRequests.WithLabelValues("rate_limited").Inc()
+				rw.Header().Set("Retry-After", retryAfterSeconds(retryAfter))
+				a.respondText(rw, "TOO MANY REQUESTS", http.StatusTooManyRequests)
+				return
+			}
+		}
+		slot := concurrencySlotFromContext(req.Context())
+		for _, limiter := range limiters {
+			if !limiter.acquire(req.Context(), slot) {
+				a.mRejectedRequests.WithLabelValues("route_queue_full").Inc()
+				a.respondText(rw, "OVERLOADED", limiter.status)
+				return
+			}
+			defer limiter.release()
+		}
+		if len(ropts.requiredAccept) > 0 && !accepts(req.Header.Get("Accept"), ropts.requiredAccept) {
+			a.respondText(rw, "NOT ACCEPTABLE, SUPPORTED: "+strings.Join(ropts.requiredAccept, ", "), http.StatusNotAcceptable)
+			return
+		}
+		if code, message := prepareRequestBody(rw, req, &ropts); code != 0 {
+			if code == http.StatusRequestEntityTooLarge {
+				a.mRejectedRequests.WithLabelValues("body_too_large").Inc()
+			}
+			a.respondText(rw, message, code)
+			return
+		}
+		timeout := ropts.requestTimeoutOf(req)
+		if timeout > 0 {
+			ctx, cancel := context.WithTimeout(req.Context(), timeout)
+			defer cancel()
+			req = req.WithContext(ctx)
+		}
+		if compression != nil {
+			if cw := newCompressWriter(rw, req, compression); cw != nil {
+				defer cw.close()
+				rw = newResponseWriter(cw)
+			}
+		}
+		if timeout > 0 {
+			tw := newTimeoutWriter(req.Context(), rw, timeout)
+			defer tw.stop()
+			rw = newResponseWriter(tw)
+		}
+		c := a.cf(rw, req)
+		if isNil(c) {
+			a.opts.logger.Error("ContextFactory returned a nil Context, check the ContextFactory passed to summer.New", "route", pattern)
+			a.respondText(rw, "NIL CONTEXT", http.StatusInternalServerError)
+			return
+		}
+		defer func() {
+			// request may be replaced by Inject
+			removeMultipartForm(c.Req())
+		}()
+		func() {
+			defer c.Perform()
+			if timeout > 0 {
+				defer respondTimeout(c, timeout)
+			}
+			if ropts.recoveryHandler != nil {
+				defer recoverWith(c, ropts.recoveryHandler)
+			}
+			a.Inject(c)
+			chainMiddlewares(fn, a.middlewares())(c)
+		}()
+	})
+}
+
+func (a *app[T]) HandleFuncUnthrottled(pattern string, fn HandlerFunc[T], opts ...Option) {
+	a.handleFunc(pattern, fn, opts...).unthrottled.Store(true)
+}
+
+func (a *app[T]) HandleFuncUpgrade(pattern string, fn HandlerFunc[T], opts ...Option) {
+	r := a.handleFunc(pattern, fn, opts...)
+	r.unthrottled.Store(true)
+	r.upgrade.Store(true)
+}
+
+// routeOf returns the registered route matching request, nil if not found
+func (a *app[T]) routeOf(req *http.Request) *route {
+	_, pattern := a.mux.Handler(req)
+	if pattern == "" {
+		return nil
+	}
+	a.routesMu.RLock()
+	defer a.routesMu.RUnlock()
+	return a.routes[pattern]
+}
+
+// isUnthrottled check if request matches a route registered by [App.HandleFuncUnthrottled]
+func (a *app[T]) isUnthrottled(req *http.Request) bool {
+	r := a.routeOf(req)
+	return r != nil && r.unthrottled.Load()
+}
+
+// isUpgrade check if request matches a route registered by [App.HandleFuncUpgrade]
+func (a *app[T]) isUpgrade(req *http.Request) bool {
+	r := a.routeOf(req)
+	return r != nil && r.upgrade.Load()
+}
+
+// strictSlashTarget returns redirect target without trailing slash, if the request does not match a subtree pattern and the target matches a route
+func (a *app[T]) strictSlashTarget(req *http.Request) (string, bool) {
+	if req.URL.Path == "/" || !strings.HasSuffix(req.URL.Path, "/") {
+		return "", false
+	}
+	if _, pattern := a.mux.Handler(req); strings.HasSuffix(pattern, "/") {
+		return "", false
+	}
+	u := *req.URL
+	u.Path = strings.TrimRight(u.Path, "/")
+	u.RawPath = ""
+	if _, pattern := a.mux.Handler(&http.Request{Method: req.Method, Host: req.Host, URL: &u}); pattern == "" {
+		return "", false
+	}
+	return u.RequestURI(), true
+}
+
+func (a *app[T]) HandleFS(pattern string, fsys fs.FS) {
+	// path of pattern, without method like "GET /static/" or host like "example.com/static/"
+	p := newRouteEntry(pattern, 0).Path
+	if i := strings.Index(p, "/"); i > 0 {
+		p = p[i:]
+	}
+	a.handle(pattern, http.StripPrefix(
+		strings.TrimSuffix(p, "/"),
+		http.FileServer(http.FS(fsys)),
+	), a.routeDeduplication(nil))
+}
+
+// handle register a [http.Handler] to mux with route tag and metrics, returns the registered route
+//
+// Duplicated pattern is handled with [DuplicateRoutePolicy]
+func (a *app[T]) handle(pattern string, h http.Handler, dedup bool) *route {
+	site := callSite()
+
+	a.routesMu.Lock()
+	defer a.routesMu.Unlock()
+
+	if r, ok := a.routes[pattern]; ok {
+		if a.opts.duplicateRoutePolicy != OverwriteOnDuplicate {
+			panic(fmt.Sprintf("summer: duplicated route %q registered at %s, previously registered at %s", pattern, site, r.callSite))
+		}
+		r.callSite = site
+		r.swap(h, dedup)
+		return r
+	}
+
+	r := &route{pattern: pattern, callSite: site}
+	r.swap(h, dedup)
+	a.routes[pattern] = r
+	a.patterns = append(a.patterns, pattern)
+
 	a.mux.Handle(
 		pattern,
 		otelhttp.WithRouteTag(
 			pattern,
 			http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
-				c := a.cf(rw, req)
-				func() {
-					defer c.Perform()
-					a.Inject(c)
-					fn(c)
+				w := newResponseWriter(rw)
+				start := time.Now()
+				// counted by route in summer_http_requests_total, totaled here for heartbeat
+				atomic.AddInt64(&a.requests, 1)
+				a.mHTTPInFlight.WithLabelValues(pattern).Inc()
+				if req.ContentLength >= 0 {
+					a.mHTTPRequestSize.WithLabelValues(pattern).Observe(float64(req.ContentLength))
+				}
+				traceID := traceIDFromContext(req.Context())
+				if traceID != "" && a.opts.traceIDHeader != "" {
+					w.Header().Set(a.opts.traceIDHeader, traceID)
+				}
+				requestID := a.opts.requestIDOf(req)
+				if requestID != "" {
+					w.Header().Set(a.opts.requestIDHeader, requestID)
+					trace.SpanFromContext(req.Context()).SetAttributes(attribute.String("summer.request_id", requestID))
+					req = req.WithContext(context.WithValue(req.Context(), contextKeyRequestID{}, requestID))
+				}
+				if ar, ok := req.Context().Value(contextKeyAccessLogRoute{}).(*accessLogRoute); ok {
+					ar.pattern = pattern
+					ar.traceID = traceID
+					ar.requestID = requestID
+				}
+				defer func() {
+					class := statusClass(w.status)
+					a.mRequestDuration.WithLabelValues(pattern, class).Observe(time.Since(start).Seconds())
+					a.mHTTPRequests.WithLabelValues(pattern, class).Inc()
+					a.mHTTPResponseSize.WithLabelValues(pattern, class).Observe(float64(w.bytes))
+					a.mHTTPInFlight.WithLabelValues(pattern).Dec()
 				}()
+				r.ServeHTTP(w, req)
 			}),
 		),
 	)
+	return r
 }
 
-func (a *app[T]) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
-	// alive, ready, metrics
-	if req.URL.Path == a.opts.readinessPath {
-		// readiness first, works when readinessPath == livenessPath
-		sb := &strings.Builder{}
-		var failed bool
-		a.Check(req.Context(), func(name string, err error) {
-			if sb.Len() > 0 {
-				sb.WriteString("\n")
+// lookupRoute returns registered route with pattern
+func (a *app[T]) lookupRoute(pattern string) (*route, error) {
+	a.routesMu.RLock()
+	defer a.routesMu.RUnlock()
+
+	r, ok := a.routes[pattern]
+	if !ok {
+		return nil, fmt.Errorf("summer: route %q not registered", pattern)
+	}
+	return r, nil
+}
+
+func (a *app[T]) DeregisterRoute(pattern string) error {
+	r, err := a.lookupRoute(pattern)
+	if err != nil {
+		return err
+	}
+	r.deregistered.Store(true)
+	return nil
+}
+
+func (a *app[T]) ReplaceRoute(pattern string, fn HandlerFunc[T], opts ...Option) error {
+	r, err := a.lookupRoute(pattern)
+	if err != nil {
+		return err
+	}
+	r.swap(a.wrap(pattern, fn, opts...), a.routeDeduplication(opts))
+	r.operation.Store(routeOperation(fn, opts))
+	return nil
+}
+
+func (a *app[T]) RegisterRoute(pattern string) error {
+	r, err := a.lookupRoute(pattern)
+	if err != nil {
+		return err
+	}
+	r.deregistered.Store(false)
+	return nil
+}
+
+// forward dispatch req to handler of route with pattern, with path set to path of pattern and a fresh context
+func (a *app[T]) forward(rw http.ResponseWriter, req *http.Request, pattern string) error {
+	r, err := a.lookupRoute(pattern)
+	if err != nil {
+		return err
+	}
+	if r.deregistered.Load() {
+		return fmt.Errorf("summer: route %q deregistered", pattern)
+	}
+
+	ctx, cancel := context.WithCancel(req.Context())
+	defer cancel()
+
+	fr := req.Clone(ctx)
+	fr.URL.Path = newRouteEntry(pattern, 0).Path
+	fr.URL.RawPath = ""
+	fr.RequestURI = fr.URL.RequestURI()
+
+	r.ServeHTTP(rw, fr)
+	return nil
+}
+
+func (a *app[T]) Patterns() []string {
+	a.routesMu.RLock()
+	defer a.routesMu.RUnlock()
+
+	return append([]string(nil), a.patterns...)
+}
+
+func (a *app[T]) ListRoutes(method, prefix string) (entries []RouteEntry) {
+	a.routesMu.RLock()
+	defer a.routesMu.RUnlock()
+
+	for i, pattern := range a.patterns {
+		entry := newRouteEntry(pattern, i)
+		if method != "" && entry.Method != "" && !strings.EqualFold(method, entry.Method) {
+			continue
+		}
+		if !strings.HasPrefix(entry.Path, prefix) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return
+}
+
+func (a *app[T]) CheckHTTP(name string, url string) Registration {
+	return a.Component(name).Check(func(ctx context.Context) error {
+		return checkHTTP(ctx, a.checkClient, url)
+	})
+}
+
+func (a *app[T]) RegisterDebugHandler(path string, fn func(app App[T]) http.Handler) {
+	if !strings.HasPrefix(path, "/debug/") {
+		panic("summer: debug handler path must start with \"/debug/\": " + path)
+	}
+	if a.isBuiltinDebugPath(path) {
+		panic("summer: debug handler path shadowed by built-in debug endpoint: " + path)
+	}
+	a.hProf.Handle(path, fn(a))
+}
+
+// isBuiltinDebugPath returns true if p is path of a built-in debug endpoint, enabled or not, served before custom debug handlers
+func (a *app[T]) isBuiltinDebugPath(p string) bool {
+	switch p {
+	case a.opts.readinessPath, a.opts.livenessPath, a.opts.startupPath, a.opts.metricsPath,
+		OptionsPath, InfoPath, BuildPath, OpenAPIPath, SwaggerUIPath, a.opts.versionPath:
+		return true
+	}
+	return false
+}
+
+func (a *app[T]) Use(mws ...MiddlewareFunc[T]) {
+	a.mwsMu.Lock()
+	defer a.mwsMu.Unlock()
+
+	var next []MiddlewareFunc[T]
+	if cur := a.mws.Load(); cur != nil {
+		next = append(next, *cur...)
+	}
+	next = append(next, mws...)
+	a.mws.Store(&next)
+}
+
+// middlewares returns middlewares registered by [App.Use] so far
+func (a *app[T]) middlewares() []MiddlewareFunc[T] {
+	if mws := a.mws.Load(); mws != nil {
+		return *mws
+	}
+	return nil
+}
+
+func (a *app[T]) Group(prefix string, mws ...MiddlewareFunc[T]) Group[T] {
+	return &group[T]{app: a, prefix: prefix, mws: mws}
+}
+
+// newGroupLimiter create a limiter shared by routes of group with prefix, metrics are labeled with "group " and prefix
+func (a *app[T]) newGroupLimiter(prefix string, rc RouteConcurrency) *routeLimiter {
+	label := "group " + prefix
+	return newRouteLimiter(rc, a.mRouteInFlight.WithLabelValues(label), a.mRouteQueueDepth.WithLabelValues(label))
+}
+
+func (a *app[T]) CheckStartupFunc(name string, fn LifecycleFunc) {
+	a.startupChecks.set(name, fn)
+}
+
+func (a *app[T]) CheckLiveFunc(name string, fn LifecycleFunc) {
+	a.liveChecks.set(name, fn)
+}
+
+func (a *app[T]) OnEachRequest(fn func(req *http.Request)) {
+	a.onEachRequest = append(a.onEachRequest, fn)
+}
+
+func (a *app[T]) OptionsSnapshot() OptionsInfo {
+	return a.opts.info()
+}
+
+// respondText respond plain text of debug endpoints and internal errors, with trailing newline if enabled by [WithTrailingNewline]
+func (a *app[T]) respondText(rw http.ResponseWriter, s string, code int) {
+	if a.opts.trailingNewline {
+		s += "\n"
+	}
+	respondInternal(rw, s, code)
+}
+
+// serveReadiness serve readiness check, as plain text, or [ReadinessReport] if requested with "?format=json" or "Accept: application/json"
+func (a *app[T]) serveReadiness(rw http.ResponseWriter, req *http.Request) {
+	asJSON := req.URL.Query().Get("format") == "json" ||
+		negotiate(req.Header.Get("Accept"), []string{ContentTypeTextPlain, ContentTypeApplicationJSON}) == ContentTypeApplicationJSON
+
+	if atomic.LoadInt32(&a.draining) != 0 {
+		if asJSON {
+			respondInternalJSON(rw, ReadinessReport{Status: ReadinessStatusDraining, Checks: []CheckResult{}}, http.StatusServiceUnavailable)
+		} else {
+			a.respondText(rw, "DRAINING", http.StatusServiceUnavailable)
+		}
+		return
+	}
+
+	report := ReadinessReport{Status: ReadinessStatusOK, Checks: []CheckResult{}}
+	a.registry.checkTimed(req.Context(), func(name string, err error, d time.Duration) {
+		report.Checks = append(report.Checks, newCheckResult(name, err, d))
+	})
+
+	status := http.StatusOK
+	if report.failed() {
+		report.Status = ReadinessStatusFailed
+		// count failures only after continuously failing for readinessFailureWindow
+		now := time.Now().UnixNano()
+		atomic.CompareAndSwapInt64(&a.readinessFailedSince, 0, now)
+		if time.Duration(now-atomic.LoadInt64(&a.readinessFailedSince)) >= a.opts.readinessWindow {
+			atomic.AddInt64(&a.readinessFailed, 1)
+		}
+		status = http.StatusInternalServerError
+	} else {
+		atomic.StoreInt64(&a.readinessFailedSince, 0)
+		atomic.StoreInt64(&a.readinessFailed, 0)
+	}
+
+	if asJSON {
+		respondInternalJSON(rw, report, status)
+		return
+	}
+
+	out, _ := formatChecks(a.opts.checkOutputFormat, func(fn func(name string, err error)) {
+		for _, item := range report.Checks {
+			fn(item.Name, item.err)
+		}
+	})
+	a.respondText(rw, out, status)
+}
+
+// serveLiveness serve liveness check, cascading from continuous readiness failures, and running checks registered by [App.CheckLiveFunc]
+func (a *app[T]) serveLiveness(rw http.ResponseWriter, req *http.Request) {
+	if failed := atomic.LoadInt64(&a.readinessFailed); a.opts.readinessCascade > 0 && failed > a.opts.readinessCascade {
+		a.mCascade.Inc()
+		a.opts.logger.Warn("readiness failure cascaded to liveness", "readiness_failed", failed)
+		a.respondText(rw, "CASCADED", http.StatusInternalServerError)
+		return
+	}
+	if a.liveChecks.len() == 0 {
+		a.respondText(rw, "OK", http.StatusOK)
+		return
+	}
+	out, failed := formatChecks(a.opts.checkOutputFormat, func(fn func(name string, err error)) {
+		a.liveChecks.run(req.Context(), fn)
+	})
+	if failed {
+		a.respondText(rw, out, http.StatusInternalServerError)
+	} else {
+		a.respondText(rw, out, http.StatusOK)
+	}
+}
+
+// serveStartup serve startup check, running checks registered by [App.CheckStartupFunc], succeeds forever once all checks passed
+func (a *app[T]) serveStartup(rw http.ResponseWriter, req *http.Request) {
+	if atomic.LoadInt32(&a.started) != 0 {
+		a.respondText(rw, a.opts.checkOutputFormat.OKText, http.StatusOK)
+		return
+	}
+	out, failed := formatChecks(a.opts.checkOutputFormat, func(fn func(name string, err error)) {
+		a.startupChecks.run(req.Context(), fn)
+	})
+	if failed {
+		a.respondText(rw, out, http.StatusServiceUnavailable)
+		return
+	}
+	atomic.StoreInt32(&a.started, 1)
+	a.respondText(rw, out, http.StatusOK)
+}
+
+// serveOptions serve snapshot of options
+func (a *app[T]) serveOptions(rw http.ResponseWriter, req *http.Request) {
+	respondInternalJSON(rw, a.OptionsSnapshot(), http.StatusOK)
+}
+
+// serveInfo serve build information
+func (a *app[T]) serveInfo(rw http.ResponseWriter, req *http.Request) {
+	respondInternalJSON(rw, readBuildInfo(a.opts.buildInfo), http.StatusOK)
+}
+
+// serveVersion serve version information
+func (a *app[T]) serveVersion(rw http.ResponseWriter, req *http.Request) {
+	respondInternalJSON(rw, a.opts.versionInfo, http.StatusOK)
+}
+
+// isVersionPath returns true if p is path of version endpoint, see [WithVersionEndpoint]
+func (a *app[T]) isVersionPath(p string) bool {
+	return a.opts.versionInfo != nil && (p == a.opts.versionPath || p == BuildPath)
+}
+
+// lookupDebug returns handler of debug endpoint for request, or nil if request is not for debug endpoints
+func (a *app[T]) lookupDebug(req *http.Request) http.Handler {
+	switch p := req.URL.Path; {
+	// readiness first, works when readinessPath == livenessPath
+	case p == a.opts.readinessPath:
+		return http.HandlerFunc(a.serveReadiness)
+	case p == a.opts.livenessPath:
+		return http.HandlerFunc(a.serveLiveness)
+	case p == a.opts.startupPath:
+		return http.HandlerFunc(a.serveStartup)
+	case p == a.opts.metricsPath:
+		return a.hProm
+	case a.opts.optionsEndpoint && p == OptionsPath:
+		return http.HandlerFunc(a.serveOptions)
+	case p == InfoPath:
+		return http.HandlerFunc(a.serveInfo)
+	case a.isVersionPath(p):
+		return http.HandlerFunc(a.serveVersion)
+	case a.opts.openAPI != nil && p == OpenAPIPath:
+		return http.HandlerFunc(a.serveOpenAPI)
+	case a.opts.openAPI != nil && a.opts.openAPI.SwaggerUI && p == SwaggerUIPath:
+		return http.HandlerFunc(a.serveSwaggerUI)
+	// pprof and custom debug handlers
+	case strings.HasPrefix(p, "/debug/"):
+		return a.hProf
+	}
+	return nil
+}
+
+// serveDebug serve debug endpoints with authentication, returns false if request is not for debug endpoints
+func (a *app[T]) serveDebug(rw http.ResponseWriter, req *http.Request) bool {
+	h := a.lookupDebug(req)
+	if h == nil {
+		return false
+	}
+	for _, item := range a.opts.debugAuths {
+		if ok, _ := path.Match(item.glob, req.URL.Path); ok && !item.fn(req) {
+			a.respondText(rw, "UNAUTHORIZED", http.StatusUnauthorized)
+			return true
+		}
+	}
+	h.ServeHTTP(rw, req)
+	return true
+}
+
+// debugHandler create a [http.Handler] serving only given debug paths, paths ending with "/" match as prefix, all debug endpoints if paths is empty
+func (a *app[T]) debugHandler(paths []string) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if len(paths) == 0 {
+			if !a.serveDebug(rw, req) {
+				http.NotFound(rw, req)
 			}
-			sb.WriteString(name)
-			if err == nil {
-				sb.WriteString(": OK")
-			} else {
-				failed = true
-				sb.WriteString(": ")
-				sb.WriteString(err.Error())
+			return
+		}
+		for _, p := range paths {
+			if req.URL.Path == p || (strings.HasSuffix(p, "/") && strings.HasPrefix(req.URL.Path, p)) {
+				if a.serveDebug(rw, req) {
+					return
+				}
+				break
 			}
-		})
-		if sb.Len() == 0 {
-			sb.WriteString("OK")
 		}
-		status := http.StatusOK
-		if failed {
-			atomic.AddInt64(&a.readinessFailed, 1)
-			status = http.StatusInternalServerError
-		} else {
-			atomic.StoreInt64(&a.readinessFailed, 0)
+		http.NotFound(rw, req)
+	})
+}
+
+// addDebugServer register lifecycle hooks of a server named name, serving h on addr
+//
+// The server is not a checked component, it is excluded from readiness and [Registry.CheckNames].
+// A failure of serving is logged, and returned by shutdown
+func (a *app[T]) addDebugServer(name string, addr string, h http.Handler) {
+	s := &http.Server{Addr: addr, Handler: h, ReadHeaderTimeout: a.opts.serverTimeouts.ReadHeaderTimeout}
+	errs := make(chan error, 1)
+	a.registry.hook(&registration{
+		name: name,
+		startup: func(ctx context.Context) (err error) {
+			var l net.Listener
+			if l, err = net.Listen("tcp", s.Addr); err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			go func() {
+				if err := s.Serve(l); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					a.opts.logger.Error("debug server failed", "name", name, "addr", addr, "error", err.Error())
+					errs <- fmt.Errorf("%s: %w", name, err)
+				}
+			}()
+			return
+		},
+		shutdown: func(ctx context.Context) error {
+			if err := s.Shutdown(ctx); err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			select {
+			case err := <-errs:
+				return err
+			default:
+				return nil
+			}
+		},
+	})
+}
+
+func (a *app[T]) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	// grpc
+	if a.opts.grpcHandler != nil && isGRPCRequest(req) {
+		a.opts.grpcHandler.ServeHTTP(rw, req)
+		return
+	}
+
+	// url length
+	if a.opts.maxURLLength > 0 && len(req.URL.String()) > a.opts.maxURLLength {
+		a.mRejectedRequests.WithLabelValues("url_too_long").Inc()
+		a.respondText(rw, "URI TOO LONG", http.StatusRequestURITooLong)
+		return
+	}
+
+	// path prefix
+	if a.opts.pathPrefix != "" {
+		req = a.stripPathPrefix(req)
+	}
+
+	// debug endpoints, unless served by a separate debug server, version endpoint is public anyway
+	if a.opts.debugAddr == "" {
+		if a.serveDebug(rw, req) {
+			return
 		}
-		respondInternal(rw, sb.String(), status)
+	} else if a.isVersionPath(req.URL.Path) {
+		a.serveVersion(rw, req)
 		return
-	} else if req.URL.Path == a.opts.livenessPath {
-		if a.opts.readinessCascade > 0 && atomic.LoadInt64(&a.readinessFailed) > a.opts.readinessCascade {
-			respondInternal(rw, "CASCADED", http.StatusInternalServerError)
-		} else {
-			respondInternal(rw, "OK", http.StatusOK)
+	}
+
+	// cors
+	if a.cors != nil && a.cors.serve(rw, req, func() {
+		a.mRejectedRequests.WithLabelValues("cors_not_allowed").Inc()
+		a.respondText(rw, "FORBIDDEN", http.StatusForbidden)
+	}) {
+		return
+	}
+
+	// strict slash
+	if a.opts.strictSlash {
+		if target, ok := a.strictSlashTarget(req); ok {
+			http.Redirect(rw, req, target, http.StatusPermanentRedirect)
+			return
 		}
+	}
+
+	// ip allowlist
+	if !a.opts.isIPAllowed(req) {
+		a.mRejectedRequests.WithLabelValues("ip_not_allowed").Inc()
+		a.respondText(rw, "FORBIDDEN", http.StatusForbidden)
 		return
-	} else if req.URL.Path == a.opts.metricsPath {
-		a.hProm.ServeHTTP(rw, req)
+	}
+
+	// each request hooks
+	for _, fn := range a.onEachRequest {
+		fn(req)
+	}
+
+	// deduplication
+	if key, ok := a.dedupKeyOf(req); ok {
+		a.serveDedup(rw, req, key)
 		return
 	}
 
-	// pprof
-	if strings.HasPrefix(req.URL.Path, "/debug/") {
-		a.hProf.ServeHTTP(rw, req)
+	a.serveMain(rw, req)
+}
+
+// dedupKeyOf returns key of deduplication, false if deduplication is disabled for the request or its route
+func (a *app[T]) dedupKeyOf(req *http.Request) (string, bool) {
+	// middlewares of [App.Use] are skipped by waiters, like authentication
+	if !a.dedupUsed.Load() || len(a.middlewares()) > 0 {
+		return "", false
+	}
+	key, ok := dedupKey(req)
+	if !ok {
+		return "", false
+	}
+	if r := a.routeOf(req); r == nil || !r.dedup.Load() || r.upgrade.Load() {
+		return "", false
+	}
+	return key, true
+}
+
+// serveDedup serve request with key deduplicated, only the leader request is handled, others wait for its buffered response
+//
+// The leader is handled without cancellation of its own request, so an aborted leader does not fail waiters.
+// "Set-Cookie" headers are only sent to the leader, and waiters are handled independently if response varies by other headers
+func (a *app[T]) serveDedup(rw http.ResponseWriter, req *http.Request, key string) {
+	c, leader := a.dedup.join(key)
+	if leader {
+		br := newBufferedResponse()
+		func() {
+			defer func() {
+				a.dedup.finish(key, c, br)
+			}()
+			a.serveMain(br, req.WithContext(context.WithoutCancel(req.Context())))
+		}()
+		br.replay(rw, false)
+		return
+	}
+	select {
+	case <-c.done:
+		// response varies by other headers, not shareable
+		if dedupVaries(c.res.header) {
+			a.serveMain(rw, req)
+			return
+		}
+		c.res.replay(rw, true)
+	case <-req.Context().Done():
+	}
+}
+
+// saturationWarnInterval minimum interval between saturation warnings
+const saturationWarnInterval = time.Minute
+
+// recoverEscaped recover a panic escaped from [Context.Perform], like panics of [ContextFactory] or a custom [Context], must be called with defer
+//
+// The panic is logged with stack, counted by "summer_panics_total", recorded to current span, and responded with 500 if nothing written
+func (a *app[T]) recoverEscaped(rw http.ResponseWriter, req *http.Request, pattern string) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	if r == http.ErrAbortHandler {
+		panic(r)
+	}
+	err, ok := r.(error)
+	if !ok {
+		err = fmt.Errorf("panic: %v", r)
+	}
+	a.mPanics.WithLabelValues(pattern).Inc()
+	a.opts.logger.Error("panic escaped from Context.Perform", "route", pattern, "error", err.Error(), "stack", string(debug.Stack()))
+	recordError(req.Context(), err, http.StatusInternalServerError)
+	if w, ok := rw.(*responseWriter); ok && w.wroteHeader {
 		return
 	}
+	a.respondText(rw, "INTERNAL SERVER ERROR", http.StatusInternalServerError)
+}
+
+// checkSaturation update available concurrency slots, and log a throttled warning if below watermark
+func (a *app[T]) checkSaturation() {
+	available := len(a.cc)
+	a.mAvailable.Set(float64(available))
 
+	if a.opts.concurrencyWatermark <= 0 || float64(available) >= a.opts.concurrencyWatermark*float64(cap(a.cc)) {
+		return
+	}
+	now := time.Now().UnixNano()
+	last := atomic.LoadInt64(&a.saturationWarnedAt)
+	if now-last < int64(saturationWarnInterval) || !atomic.CompareAndSwapInt64(&a.saturationWarnedAt, last, now) {
+		return
+	}
+	a.opts.logger.Warn("concurrency slots below watermark", "available", available, "concurrency", cap(a.cc))
+}
+
+// stripPathPrefix returns a shallow copy of request with path prefix set by [WithPathPrefix] stripped
+func (a *app[T]) stripPathPrefix(req *http.Request) *http.Request {
+	p := req.URL.Path
+	if p != a.opts.pathPrefix && !strings.HasPrefix(p, a.opts.pathPrefix+"/") {
+		if a.lookupDebug(req) == nil {
+			a.opts.logger.Warn("path prefix not found in request", "prefix", a.opts.pathPrefix, "path", p)
+		}
+		return req
+	}
+	r := new(http.Request)
+	*r = *req
+	r.URL = new(url.URL)
+	*r.URL = *req.URL
+	r.URL.Path = strings.TrimPrefix(p, a.opts.pathPrefix)
+	r.URL.RawPath = strings.TrimPrefix(req.URL.RawPath, a.opts.pathPrefix)
+	if r.URL.Path == "" {
+		r.URL.Path = "/"
+	}
+	return r
+}
+
+// heartbeat log a heartbeat line on every interval set by [WithHeartbeat], until done is closed
+func (a *app[T]) heartbeat(done chan struct{}) {
+	logger := a.opts.heartbeatLogger
+	if logger == nil {
+		logger = a.opts.logger
+	}
+	ticker := time.NewTicker(a.opts.heartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			logger.Info("heartbeat", "uptime", time.Since(a.startedAt).Round(time.Second).String(), "requests", atomic.LoadInt64(&a.requests))
+		}
+	}
+}
+
+// serveMain serve non-debug requests with concurrency control
+func (a *app[T]) serveMain(rw http.ResponseWriter, req *http.Request) {
 	// concurrency control
-	if a.cc != nil {
-		<-a.cc
+	if a.cc != nil && !a.isUnthrottled(req) {
+		select {
+		case <-a.cc:
+		default:
+			if queued := atomic.AddInt64(&a.ccQueued, 1); a.opts.maxQueueDepth > 0 && queued > int64(a.opts.maxQueueDepth) {
+				atomic.AddInt64(&a.ccQueued, -1)
+				a.mRejectedRequests.WithLabelValues("queue_full").Inc()
+				a.respondText(rw, "OVERLOADED", http.StatusServiceUnavailable)
+				return
+			}
+			a.mQueueDepth.Inc()
+			<-a.cc
+			a.mQueueDepth.Dec()
+			atomic.AddInt64(&a.ccQueued, -1)
+		}
+		a.checkSaturation()
+		slot := &concurrencySlot{cc: a.cc, held: true}
+		req = req.WithContext(context.WithValue(req.Context(), contextKeyConcurrencySlot{}, slot))
 		defer func() {
-			a.cc <- struct{}{}
+			slot.release()
+			a.mAvailable.Set(float64(len(a.cc)))
+		}()
+	}
+
+	// access log
+	if a.opts.accessLogger != nil {
+		w := newResponseWriter(rw)
+		start := time.Now()
+		ar := &accessLogRoute{}
+		req = req.WithContext(context.WithValue(req.Context(), contextKeyAccessLogRoute{}, ar))
+		defer func() {
+			a.opts.accessLogger(AccessLogEntry{
+				Method:    req.Method,
+				Path:      req.URL.Path,
+				Route:     ar.pattern,
+				Status:    w.status,
+				Bytes:     w.bytes,
+				Duration:  time.Since(start),
+				ClientIP:  extractClientIP(req),
+				TraceID:   ar.traceID,
+				RequestID: ar.requestID,
+			})
 		}()
+		rw = w
 	}
 
 	a.hMain.ServeHTTP(rw, req)
@@ -124,28 +1107,55 @@ func (a *app[T]) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 // New create an [App] with a custom [ContextFactory] and additional [Option]
 func New[T Context](cf ContextFactory[T], opts ...Option) App[T] {
 	a := &app[T]{
-
-		opts: options{
-			concurrency:      128,
-			readinessCascade: 5,
-			readinessPath:    DefaultReadinessPath,
-			livenessPath:     DefaultLivenessPath,
-			metricsPath:      DefaultMetricsPath,
-		},
+		opts: defaultOptions(),
 	}
 
 	for _, opt := range opts {
 		opt(&a.opts)
 	}
 
-	a.Registry = NewRegistry()
+	for _, c := range a.opts.configs {
+		copts := c.opts
+		if a.opts.validator != nil {
+			copts = append([]ConfigOption{ConfigWithValidator(a.opts.validator)}, copts...)
+		}
+		if err := LoadConfig(c.cfg, copts...); err != nil {
+			panic(err)
+		}
+	}
+
+	a.registry = newRegistry(RegistryWithCheckTimeout(a.opts.checkTimeout))
+	a.Registry = a.registry
+
+	if a.opts.accessLog && a.opts.accessLogger == nil {
+		a.opts.accessLogger = SlogAccessLogger(a.opts.logger)
+	}
+
+	if a.opts.cors != nil {
+		a.cors = newCORSPolicy(*a.opts.cors)
+	}
 
 	a.cf = cf
 
 	a.mux = &http.ServeMux{}
+	a.routes = map[string]*route{}
 
-	a.hMain = otelhttp.NewHandler(a.mux, "http")
-	a.hProm = promhttp.Handler()
+	a.checkClient = newCheckHTTPClient(a.opts.checkHTTPDialer)
+
+	if a.opts.otelDisabled {
+		a.hMain = a.mux
+	} else {
+		a.hMain = otelhttp.NewHandler(a.mux, "http", append([]otelhttp.Option{
+			otelhttp.WithSpanOptions(trace.WithSpanKind(a.opts.otelSpanKind)),
+		}, a.opts.otelOptions...)...)
+	}
+	var reg prometheus.Registerer = prometheus.DefaultRegisterer
+	if a.opts.promRegistry != nil {
+		reg = a.opts.promRegistry
+		a.hProm = promhttp.HandlerFor(a.opts.promRegistry, promhttp.HandlerOpts{})
+	} else {
+		a.hProm = promhttp.Handler()
+	}
 	m := &http.ServeMux{}
 	m.HandleFunc("/debug/pprof/", pprof.Index)
 	m.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
@@ -154,12 +1164,127 @@ func New[T Context](cf ContextFactory[T], opts ...Option) App[T] {
 	m.HandleFunc("/debug/pprof/trace", pprof.Trace)
 	a.hProf = m
 
+	a.mQueueDepth = registerCollector(reg, prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "summer_concurrency_queue_depth",
+		Help: "number of requests waiting for a concurrency slot",
+	}))
+	a.mAvailable = registerCollector(reg, prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "summer_concurrency_available",
+		Help: "number of available concurrency slots",
+	}))
+	a.mRequestDuration = registerCollector(reg, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "summer_request_duration_seconds",
+		Help:    "duration of requests in seconds, by route and status class",
+		Buckets: a.opts.durationBuckets,
+	}, []string{"route", "status_class"}))
+	a.mOpenConnections = registerCollector(reg, prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "summer_open_connections",
+		Help: "number of open connections",
+	}))
+	a.mRejectedRequests = registerCollector(reg, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "summer_rejected_requests_total",
+		Help: "number of requests rejected before handling",
+	}, []string{"reason"}))
+	a.mCascade = registerCollector(reg, prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "summer_cascade_total",
+		Help: "number of liveness failures cascaded from readiness failures",
+	}))
+	a.mHTTPRequests = registerCollector(reg, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "summer_http_requests_total",
+		Help: "number of requests handled by registered routes, by route and status class",
+	}, []string{"route", "status_class"}))
+	a.mHTTPRequestSize = registerCollector(reg, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "summer_http_request_size_bytes",
+		Help:    "size of request bodies in bytes, by route",
+		Buckets: prometheus.ExponentialBuckets(64, 4, 10),
+	}, []string{"route"}))
+	a.mHTTPResponseSize = registerCollector(reg, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "summer_http_response_size_bytes",
+		Help:    "size of response bodies in bytes, by route and status class",
+		Buckets: prometheus.ExponentialBuckets(64, 4, 10),
+	}, []string{"route", "status_class"}))
+	a.mHTTPInFlight = registerCollector(reg, prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "summer_http_in_flight_requests",
+		Help: "number of in-flight requests, by route",
+	}, []string{"route"}))
+	a.mWebSockets = registerCollector(reg, prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "summer_websocket_connections",
+		Help: "number of active WebSocket connections",
+	}))
+	a.mWorkerRunning = registerCollector(reg, prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "summer_worker_running",
+		Help: "whether a worker started by App.Go is running, by worker",
+	}, []string{"worker"}))
+	a.mWorkerFailures = registerCollector(reg, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "summer_worker_failures_total",
+		Help: "number of failures of workers started by App.Go, by worker",
+	}, []string{"worker"}))
+	a.mCronRuns = registerCollector(reg, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "summer_cron_runs_total",
+		Help: "number of runs of cron jobs, by job and result",
+	}, []string{"job", "result"}))
+	a.mCronDuration = registerCollector(reg, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "summer_cron_duration_seconds",
+		Help:    "duration of runs of cron jobs, by job",
+		Buckets: prometheus.ExponentialBuckets(0.005, 4, 10),
+	}, []string{"job"}))
+	a.mCronLastSuccess = registerCollector(reg, prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "summer_cron_last_success_timestamp_seconds",
+		Help: "unix timestamp of the last successful run of cron jobs, by job",
+	}, []string{"job"}))
+	a.mPanics = registerCollector(reg, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "summer_panics_total",
+		Help: "number of panics escaped from Context.Perform",
+	}, []string{"route"}))
+	a.mRouteInFlight = registerCollector(reg, prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "summer_route_in_flight",
+		Help: "number of in-flight requests of routes with concurrency limit",
+	}, []string{"route"}))
+	a.mRouteQueueDepth = registerCollector(reg, prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "summer_route_queue_depth",
+		Help: "number of requests waiting for a concurrency slot of routes with concurrency limit",
+	}, []string{"route"}))
+
+	a.startedAt = time.Now()
+
+	// heartbeat
+	if a.opts.heartbeatInterval > 0 {
+		// stop of current run, closing done once, replaced on every startup
+		var stop func()
+		a.registry.hook(&registration{
+			name: "heartbeat",
+			startup: func(ctx context.Context) error {
+				done := make(chan struct{})
+				stop = sync.OnceFunc(func() { close(done) })
+				go a.heartbeat(done)
+				return nil
+			},
+			shutdown: func(ctx context.Context) error {
+				if stop != nil {
+					stop()
+				}
+				return nil
+			},
+		})
+	}
+
+	// debug server
+	if a.opts.debugAddr != "" {
+		a.addDebugServer("debug-server", a.opts.debugAddr, a.debugHandler(nil))
+	}
+
+	// extra debug servers
+	for _, eds := range a.opts.extraDebugServers {
+		a.addDebugServer("extra-debug-server-"+eds.addr, eds.addr, a.debugHandler(eds.paths))
+	}
+
 	// concurrency control
 	if a.opts.concurrency > 0 {
 		a.cc = make(chan struct{}, a.opts.concurrency)
 		for i := 0; i < a.opts.concurrency; i++ {
 			a.cc <- struct{}{}
 		}
+		a.mAvailable.Set(float64(a.opts.concurrency))
 	}
 	return a
 }
@@ -1,12 +1,26 @@
 package summer
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"io"
+	"log/slog"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"testing/fstest"
+	"time"
 )
 
 func TestApp(t *testing.T) {
@@ -48,6 +62,10 @@ func TestApp(t *testing.T) {
 	require.Equal(t, http.StatusInternalServerError, rw.Code)
 	require.Equal(t, "CASCADED", rw.Body.String())
 
+	rw, req = httptest.NewRecorder(), httptest.NewRequest("GET", "https://exmaple.com/debug/metrics", nil)
+	a.ServeHTTP(rw, req)
+	require.Contains(t, rw.Body.String(), "summer_cascade_total ")
+
 	bad = false
 
 	rw, req = httptest.NewRecorder(), httptest.NewRequest("GET", "https://exmaple.com/debug/ready", nil)
@@ -66,3 +84,885 @@ func TestApp(t *testing.T) {
 	a.ServeHTTP(rw, req)
 
 }
+
+func TestAppOnEachRequest(t *testing.T) {
+	var calls []string
+
+	a := Basic()
+	a.OnEachRequest(func(req *http.Request) {
+		calls = append(calls, "1:"+req.URL.Path)
+	})
+	a.OnEachRequest(func(req *http.Request) {
+		calls = append(calls, "2:"+req.URL.Path)
+	})
+	a.HandleFunc("/test", func(ctx Context) {
+		ctx.Text("OK")
+	})
+
+	rw, req := httptest.NewRecorder(), httptest.NewRequest("GET", "https://exmaple.com/debug/alive", nil)
+	a.ServeHTTP(rw, req)
+	require.Empty(t, calls)
+
+	rw, req = httptest.NewRecorder(), httptest.NewRequest("GET", "https://exmaple.com/test", nil)
+	a.ServeHTTP(rw, req)
+	require.Equal(t, "OK", rw.Body.String())
+	require.Equal(t, []string{"1:/test", "2:/test"}, calls)
+}
+
+func TestAppMaxQueueDepth(t *testing.T) {
+	a := Basic(WithConcurrency(1), WithMaxQueueDepth(1))
+
+	block := make(chan struct{})
+	a.HandleFunc("/test", func(ctx Context) {
+		<-block
+		ctx.Text("OK")
+	})
+
+	done := make(chan int, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			rw, req := httptest.NewRecorder(), httptest.NewRequest("GET", "https://exmaple.com/test", nil)
+			a.ServeHTTP(rw, req)
+			done <- rw.Code
+		}()
+	}
+
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt64(&a.(*app[Context]).ccQueued) == 1
+	}, time.Second, time.Millisecond)
+
+	rw, req := httptest.NewRecorder(), httptest.NewRequest("GET", "https://exmaple.com/test", nil)
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusServiceUnavailable, rw.Code)
+	require.Equal(t, "OVERLOADED", rw.Body.String())
+
+	close(block)
+	require.Equal(t, http.StatusOK, <-done)
+	require.Equal(t, http.StatusOK, <-done)
+}
+
+func TestAppOptionsEndpoint(t *testing.T) {
+	a := Basic(WithConcurrency(2))
+
+	rw, req := httptest.NewRecorder(), httptest.NewRequest("GET", "https://exmaple.com/debug/options", nil)
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusNotFound, rw.Code)
+
+	a = Basic(WithConcurrency(2), WithOptionsEndpoint(true))
+	require.Equal(t, 2, a.OptionsSnapshot().Concurrency)
+
+	rw, req = httptest.NewRecorder(), httptest.NewRequest("GET", "https://exmaple.com/debug/options", nil)
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Equal(t, "application/json; charset=utf-8", rw.Header().Get("Content-Type"))
+
+	var info OptionsInfo
+	require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &info))
+	require.Equal(t, a.OptionsSnapshot(), info)
+}
+
+func TestAppHandleFS(t *testing.T) {
+	a := Basic()
+	a.HandleFS("/static/", fstest.MapFS{
+		"openapi.json": &fstest.MapFile{Data: []byte(`{}`)},
+	})
+
+	rw, req := httptest.NewRecorder(), httptest.NewRequest("GET", "https://exmaple.com/static/openapi.json", nil)
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Equal(t, "{}", rw.Body.String())
+
+	rw, req = httptest.NewRecorder(), httptest.NewRequest("GET", "https://exmaple.com/static/missing.json", nil)
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusNotFound, rw.Code)
+
+	// method and host in pattern
+	fsys := fstest.MapFS{
+		"app.js": &fstest.MapFile{Data: []byte(`JS`)},
+	}
+	a.HandleFS("GET /assets/", fsys)
+	a.HandleFS("cdn.exmaple.com/files/", fsys)
+
+	rw = a.TestRequest("GET", "/assets/app.js", nil)
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Equal(t, "JS", rw.Body.String())
+
+	rw = a.TestRequest("POST", "/assets/app.js", nil)
+	require.Equal(t, http.StatusMethodNotAllowed, rw.Code)
+
+	rw, req = httptest.NewRecorder(), httptest.NewRequest("GET", "https://cdn.exmaple.com/files/app.js", nil)
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Equal(t, "JS", rw.Body.String())
+}
+
+func TestAppHandleFuncOptions(t *testing.T) {
+	var rows []map[string]any
+
+	a := Basic(WithCSVComma(';'))
+	a.HandleFunc("/app", func(ctx Context) {
+		rows = Bind[struct {
+			Rows []map[string]any `json:"rows"`
+		}](ctx).Rows
+	})
+	a.HandleFunc("/route", func(ctx Context) {
+		rows = Bind[struct {
+			Rows []map[string]any `json:"rows"`
+		}](ctx).Rows
+	}, WithCSVComma('\t'))
+
+	rw, req := httptest.NewRecorder(), httptest.NewRequest("POST", "https://exmaple.com/app", strings.NewReader("name;age\nalice;18\n"))
+	req.Header.Set("Content-Type", "text/csv")
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Equal(t, []map[string]any{{"name": "alice", "age": "18"}}, rows)
+
+	rw, req = httptest.NewRecorder(), httptest.NewRequest("POST", "https://exmaple.com/route", strings.NewReader("name\tage\nbob\t20\n"))
+	req.Header.Set("Content-Type", "text/csv")
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Equal(t, []map[string]any{{"name": "bob", "age": "20"}}, rows)
+}
+
+func TestAppAccessLogger(t *testing.T) {
+	var entries []AccessLogEntry
+
+	a := Basic(WithAccessLogger(func(entry AccessLogEntry) {
+		entries = append(entries, entry)
+	}))
+	a.HandleFunc("/test", func(ctx Context) {
+		ctx.Code(http.StatusCreated)
+		ctx.Text("OK")
+	})
+
+	rw, req := httptest.NewRecorder(), httptest.NewRequest("POST", "https://exmaple.com/test", nil)
+	req.Header.Set("X-Forwarded-For", "10.0.0.1")
+	a.ServeHTTP(rw, req)
+
+	rw, req = httptest.NewRecorder(), httptest.NewRequest("GET", "https://exmaple.com/debug/alive", nil)
+	a.ServeHTTP(rw, req)
+
+	require.Len(t, entries, 1)
+	require.Equal(t, "POST", entries[0].Method)
+	require.Equal(t, "/test", entries[0].Path)
+	require.Equal(t, http.StatusCreated, entries[0].Status)
+	require.Equal(t, int64(2), entries[0].Bytes)
+	require.Equal(t, "10.0.0.1", entries[0].ClientIP)
+	require.Equal(t, "/test", entries[0].Route)
+	require.Empty(t, entries[0].TraceID)
+}
+
+func TestAppAccessLog(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	a := Basic(WithAccessLog(true), WithLogger(slog.New(slog.NewJSONHandler(buf, nil))))
+	a.HandleFunc("/users/{id}", func(ctx Context) {
+		ctx.Text("OK")
+	})
+
+	traceID := trace.TraceID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
+	req := httptest.NewRequest("GET", "https://exmaple.com/users/1", nil)
+	req = req.WithContext(trace.ContextWithSpanContext(req.Context(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  trace.SpanID{1, 2, 3, 4, 5, 6, 7, 8},
+	})))
+	a.ServeHTTP(httptest.NewRecorder(), req)
+
+	a.TestRequest("GET", "/none", nil)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+
+	var m map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &m))
+	require.Equal(t, "access", m["msg"])
+	require.Equal(t, "/users/1", m["path"])
+	require.Equal(t, "/users/{id}", m["route"])
+	require.Equal(t, float64(http.StatusOK), m["status"])
+	require.Equal(t, traceID.String(), m["trace_id"])
+
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &m))
+	require.Equal(t, "", m["route"])
+	require.Equal(t, float64(http.StatusNotFound), m["status"])
+}
+
+func TestAppExtraDebugServer(t *testing.T) {
+	a := Basic(WithExtraDebugServer("127.0.0.1:0", []string{"/debug/ready", "/debug/pprof/"}))
+	require.NoError(t, a.Startup(context.Background()))
+	defer a.Shutdown(context.Background())
+
+	h := a.(*app[Context]).debugHandler([]string{"/debug/ready", "/debug/pprof/"})
+
+	rw, req := httptest.NewRecorder(), httptest.NewRequest("GET", "https://exmaple.com/debug/ready", nil)
+	h.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.NotContains(t, rw.Body.String(), "extra-debug-server")
+	require.Empty(t, a.CheckNames())
+
+	rw, req = httptest.NewRecorder(), httptest.NewRequest("GET", "https://exmaple.com/debug/pprof/cmdline", nil)
+	h.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusOK, rw.Code)
+
+	rw, req = httptest.NewRecorder(), httptest.NewRequest("GET", "https://exmaple.com/debug/alive", nil)
+	h.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusNotFound, rw.Code)
+}
+
+func TestAppDebugServer(t *testing.T) {
+	addr := freeAddr(t)
+	a := Basic(WithDebugServer(addr), WithVersionEndpoint(VersionInfo{Version: "v1.0.0"}))
+	a.HandleFunc("/test", func(ctx Context) {
+		ctx.Text("OK")
+	})
+	require.NoError(t, a.Startup(context.Background()))
+	defer a.Shutdown(context.Background())
+
+	// not checked
+	require.Empty(t, a.CheckNames())
+
+	// address in use
+	b := Basic(WithDebugServer(addr))
+	require.ErrorContains(t, b.Startup(context.Background()), "debug-server: listen tcp")
+
+	get := func(path string) int {
+		res, err := http.Get("http://" + addr + path)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		return res.StatusCode
+	}
+
+	require.Equal(t, http.StatusOK, get("/debug/ready"))
+	require.Equal(t, http.StatusOK, get("/debug/metrics"))
+	require.Equal(t, http.StatusOK, get("/debug/pprof/cmdline"))
+	require.Equal(t, http.StatusNotFound, get("/test"))
+
+	// not exposed by main handler
+	rw, req := httptest.NewRecorder(), httptest.NewRequest("GET", "https://exmaple.com/debug/ready", nil)
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusNotFound, rw.Code)
+
+	rw, req = httptest.NewRecorder(), httptest.NewRequest("GET", "https://exmaple.com/debug/pprof/cmdline", nil)
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusNotFound, rw.Code)
+
+	rw, req = httptest.NewRecorder(), httptest.NewRequest("GET", "https://exmaple.com/test", nil)
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusOK, rw.Code)
+
+	// version endpoint stays public
+	rw, req = httptest.NewRecorder(), httptest.NewRequest("GET", "https://exmaple.com/version", nil)
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Contains(t, rw.Body.String(), "v1.0.0")
+}
+
+func TestAppRequestDuration(t *testing.T) {
+	a := Basic()
+	a.HandleFunc("/test-duration", func(ctx Context) {
+		ctx.Code(http.StatusAccepted)
+		ctx.Text("OK")
+	})
+
+	rw, req := httptest.NewRecorder(), httptest.NewRequest("GET", "https://exmaple.com/test-duration", nil)
+	a.ServeHTTP(rw, req)
+
+	rw, req = httptest.NewRecorder(), httptest.NewRequest("GET", "https://exmaple.com/debug/metrics", nil)
+	a.ServeHTTP(rw, req)
+	require.Contains(t, rw.Body.String(), `summer_request_duration_seconds_count{route="/test-duration",status_class="2xx"} 1`)
+}
+
+func TestAppRegisterDebugHandler(t *testing.T) {
+	a := Basic(WithConcurrency(3))
+	a.RegisterDebugHandler("/debug/concurrency", func(app App[Context]) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			_, _ = rw.Write([]byte(strconv.Itoa(app.OptionsSnapshot().Concurrency)))
+		})
+	})
+
+	rw, req := httptest.NewRecorder(), httptest.NewRequest("GET", "https://exmaple.com/debug/concurrency", nil)
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Equal(t, "3", rw.Body.String())
+
+	h := func(app App[Context]) http.Handler {
+		return http.NotFoundHandler()
+	}
+	require.PanicsWithValue(t, `summer: debug handler path must start with "/debug/": /concurrency`, func() {
+		a.RegisterDebugHandler("/concurrency", h)
+	})
+	require.PanicsWithValue(t, "summer: debug handler path shadowed by built-in debug endpoint: /debug/ready", func() {
+		a.RegisterDebugHandler("/debug/ready", h)
+	})
+	require.PanicsWithValue(t, "summer: debug handler path shadowed by built-in debug endpoint: /debug/metrics", func() {
+		a.RegisterDebugHandler("/debug/metrics", h)
+	})
+	require.PanicsWithValue(t, "summer: debug handler path shadowed by built-in debug endpoint: /debug/swagger", func() {
+		a.RegisterDebugHandler("/debug/swagger", h)
+	})
+}
+
+func TestAppMaxURLLength(t *testing.T) {
+	a := Basic(WithMaxURLLength(32))
+	a.HandleFunc("/test", func(ctx Context) {
+		ctx.Text("OK")
+	})
+
+	rw, req := httptest.NewRecorder(), httptest.NewRequest("GET", "https://exmaple.com/test", nil)
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusOK, rw.Code)
+
+	rw, req = httptest.NewRecorder(), httptest.NewRequest("GET", "https://exmaple.com/test?"+strings.Repeat("a", 32), nil)
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusRequestURITooLong, rw.Code)
+}
+
+func TestAppHandleFuncUnthrottled(t *testing.T) {
+	a := Basic(WithConcurrency(1))
+
+	block := make(chan struct{})
+	a.HandleFunc("/slow", func(ctx Context) {
+		<-block
+		ctx.Text("OK")
+	})
+	a.HandleFuncUnthrottled("/fast", func(ctx Context) {
+		ctx.Text("FAST")
+	})
+
+	done := make(chan struct{})
+	go func() {
+		rw, req := httptest.NewRecorder(), httptest.NewRequest("GET", "https://exmaple.com/slow", nil)
+		a.ServeHTTP(rw, req)
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool {
+		return len(a.(*app[Context]).cc) == 0
+	}, time.Second, time.Millisecond)
+
+	rw, req := httptest.NewRecorder(), httptest.NewRequest("GET", "https://exmaple.com/fast", nil)
+	a.ServeHTTP(rw, req)
+	require.Equal(t, "FAST", rw.Body.String())
+
+	close(block)
+	<-done
+}
+
+func TestAppHandleFuncUnthrottledWhileServing(t *testing.T) {
+	a := Basic(WithConcurrency(1))
+	a.HandleFunc("/throttled", func(ctx Context) {
+		ctx.Text("OK")
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			a.HandleFuncUnthrottled("/fast-"+strconv.Itoa(i), func(ctx Context) {})
+			a.HandleFuncUpgrade("/upgrade-"+strconv.Itoa(i), func(ctx Context) {})
+		}(i)
+		go func() {
+			defer wg.Done()
+			require.Equal(t, http.StatusOK, a.TestRequest("GET", "/throttled", nil).Code)
+		}()
+	}
+	wg.Wait()
+
+	require.True(t, a.(*app[Context]).isUnthrottled(httptest.NewRequest("GET", "/fast-3", nil)))
+	require.False(t, a.(*app[Context]).isUnthrottled(httptest.NewRequest("GET", "/throttled", nil)))
+	require.True(t, a.(*app[Context]).isUpgrade(httptest.NewRequest("GET", "/upgrade-3", nil)))
+}
+
+func TestAppGetDeduplication(t *testing.T) {
+	a := Basic(WithGetDeduplication(true))
+
+	var count int64
+	block := make(chan struct{})
+	a.HandleFunc("/test", func(ctx Context) {
+		atomic.AddInt64(&count, 1)
+		<-block
+		ctx.SetCookie(&http.Cookie{Name: "a", Value: "b"})
+		ctx.Code(http.StatusAccepted)
+		ctx.Text("OK")
+	})
+
+	var arrived int64
+	a.OnEachRequest(func(req *http.Request) {
+		atomic.AddInt64(&arrived, 1)
+	})
+
+	results := make(chan *httptest.ResponseRecorder, 3)
+	for i := 0; i < 3; i++ {
+		go func() {
+			rw, req := httptest.NewRecorder(), httptest.NewRequest("GET", "https://exmaple.com/test?a=b", nil)
+			a.ServeHTTP(rw, req)
+			results <- rw
+		}()
+	}
+
+	// leader blocked in handler, and the others arrived
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt64(&arrived) == 3 && atomic.LoadInt64(&count) == 1
+	}, time.Second, time.Millisecond)
+	time.Sleep(time.Millisecond * 20)
+	close(block)
+
+	var cookies int
+	for i := 0; i < 3; i++ {
+		rw := <-results
+		require.Equal(t, http.StatusAccepted, rw.Code)
+		require.Equal(t, "OK", rw.Body.String())
+		require.Equal(t, "text/plain; charset=utf-8", rw.Header().Get("Content-Type"))
+		cookies += len(rw.Header().Values("Set-Cookie"))
+	}
+	require.Equal(t, int64(1), atomic.LoadInt64(&count))
+	// only leader receives cookies
+	require.Equal(t, 1, cookies)
+}
+
+func TestAppGetDeduplicationAuth(t *testing.T) {
+	a := Basic(WithGetDeduplication(true))
+	g := a.Group("/api", Authenticate(APIKeyAuth[Context]("", APIKeys[Context](map[string]string{"key-1": "alice"}))))
+
+	block := make(chan struct{})
+	g.HandleFunc("/whoami", func(ctx Context) {
+		<-block
+		ctx.Text("hello " + ctx.Principal().Subject)
+	})
+
+	authed := make(chan *httptest.ResponseRecorder, 1)
+	go func() {
+		rw, req := httptest.NewRecorder(), httptest.NewRequest("GET", "/api/whoami", nil)
+		req.Header.Set(DefaultAPIKeyHeader, "key-1")
+		a.ServeHTTP(rw, req)
+		authed <- rw
+	}()
+
+	// anonymous request never waits for the authenticated one
+	res := a.TestRequest("GET", "/api/whoami", nil)
+	require.Equal(t, http.StatusUnauthorized, res.Code)
+	require.NotContains(t, res.Body.String(), "alice")
+
+	close(block)
+	require.Equal(t, "hello alice", (<-authed).Body.String())
+
+	// custom header of api key, with middlewares of Use
+	b := Basic(WithGetDeduplication(true))
+	b.Use(Authenticate(APIKeyAuth[Context]("X-Token", APIKeys[Context](map[string]string{"key-1": "alice"}))))
+	b.HandleFunc("/whoami", func(ctx Context) {})
+	_, ok := b.(*app[Context]).dedupKeyOf(httptest.NewRequest("GET", "/whoami", nil))
+	require.False(t, ok)
+}
+
+func TestDedupKey(t *testing.T) {
+	req := httptest.NewRequest("GET", "/test?a=b", nil)
+	req.Header.Set("Accept", "application/json")
+	key, ok := dedupKey(req)
+	require.True(t, ok)
+	require.Equal(t, "GET /test?a=b\napplication/json\n\n", key)
+
+	for _, h := range []string{"Authorization", "Cookie", DefaultAPIKeyHeader} {
+		req = httptest.NewRequest("GET", "/test", nil)
+		req.Header.Set(h, "x")
+		_, ok = dedupKey(req)
+		require.False(t, ok)
+	}
+
+	req = httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set("Accept", ContentTypeTextEventStream)
+	_, ok = dedupKey(req)
+	require.False(t, ok)
+
+	_, ok = dedupKey(httptest.NewRequest("POST", "/test", nil))
+	require.False(t, ok)
+
+	require.False(t, dedupVaries(http.Header{"Vary": {"Accept-Encoding, accept"}}))
+	require.True(t, dedupVaries(http.Header{"Vary": {"Accept-Encoding, X-Tenant"}}))
+	require.True(t, dedupVaries(http.Header{"Vary": {"*"}}))
+}
+
+func TestAppGetDeduplicationRoute(t *testing.T) {
+	a := Basic(WithGetDeduplication(true))
+	a.HandleFunc("/stream", func(ctx Context) {}, WithGetDeduplication(false))
+	a.HandleFunc("/test", func(ctx Context) {})
+
+	a.HandleFunc("/limited", func(ctx Context) {}, WithRateLimit(RateLimit{Limit: 10, Burst: 10}))
+	a.Group("/group", func(ctx Context, next func()) { next() }).HandleFunc("/test", func(ctx Context) {})
+
+	b := Basic()
+	b.HandleFunc("/test", func(ctx Context) {}, WithGetDeduplication(true))
+	b.HandleFunc("/other", func(ctx Context) {})
+	b.HandleFunc("/replaced", func(ctx Context) {})
+	require.NoError(t, b.ReplaceRoute("/replaced", func(ctx Context) {}, WithGetDeduplication(true)))
+	require.NoError(t, b.ReplaceRoute("/test", func(ctx Context) {}))
+
+	for _, item := range []struct {
+		a       App[Context]
+		path    string
+		enabled bool
+	}{
+		{a, "/stream", false},
+		{a, "/test", true},
+		{a, "/limited", false},
+		{a, "/group/test", false},
+		{b, "/test", false},
+		{b, "/other", false},
+		{b, "/replaced", true},
+	} {
+		_, ok := item.a.(*app[Context]).dedupKeyOf(httptest.NewRequest("GET", item.path, nil))
+		require.Equal(t, item.enabled, ok, item.path)
+	}
+}
+
+func TestAppDebugAuth(t *testing.T) {
+	isSRE := func(req *http.Request) bool {
+		return req.Header.Get("X-Role") == "sre"
+	}
+	isAdmin := func(req *http.Request) bool {
+		return req.Header.Get("X-Admin") == "true"
+	}
+
+	a := Basic(
+		WithDebugAuth("/debug/pprof/*", isSRE),
+		WithDebugAuth("/debug/pprof/cmdline", isAdmin),
+	)
+
+	rw, req := httptest.NewRecorder(), httptest.NewRequest("GET", "https://exmaple.com/debug/ready", nil)
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusOK, rw.Code)
+
+	rw, req = httptest.NewRecorder(), httptest.NewRequest("GET", "https://exmaple.com/debug/pprof/symbol", nil)
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusUnauthorized, rw.Code)
+
+	rw, req = httptest.NewRecorder(), httptest.NewRequest("GET", "https://exmaple.com/debug/pprof/symbol", nil)
+	req.Header.Set("X-Role", "sre")
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusOK, rw.Code)
+
+	rw, req = httptest.NewRecorder(), httptest.NewRequest("GET", "https://exmaple.com/debug/pprof/cmdline", nil)
+	req.Header.Set("X-Role", "sre")
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusUnauthorized, rw.Code)
+
+	req.Header.Set("X-Admin", "true")
+	rw = httptest.NewRecorder()
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusOK, rw.Code)
+}
+
+func TestAppReadinessFailureWindow(t *testing.T) {
+	a := Basic(WithReadinessCascade(1), WithReadinessFailureWindow(time.Millisecond*100))
+	a.Component("test-1").Check(func(ctx context.Context) (err error) {
+		return errors.New("test-failed")
+	})
+
+	probe := func(path string) int {
+		rw, req := httptest.NewRecorder(), httptest.NewRequest("GET", "https://exmaple.com"+path, nil)
+		a.ServeHTTP(rw, req)
+		return rw.Code
+	}
+
+	for i := 0; i < 3; i++ {
+		require.Equal(t, http.StatusInternalServerError, probe("/debug/ready"))
+	}
+	require.Equal(t, http.StatusOK, probe("/debug/alive"))
+
+	time.Sleep(time.Millisecond * 100)
+
+	for i := 0; i < 2; i++ {
+		require.Equal(t, http.StatusInternalServerError, probe("/debug/ready"))
+	}
+	require.Equal(t, http.StatusInternalServerError, probe("/debug/alive"))
+}
+
+func TestAppNilContext(t *testing.T) {
+	logs := &bytes.Buffer{}
+
+	a := New(func(rw http.ResponseWriter, req *http.Request) Context {
+		return nil
+	}, WithLogger(slog.New(slog.NewTextHandler(logs, nil))))
+	a.HandleFunc("/test", func(ctx Context) {
+		ctx.Text("OK")
+	})
+
+	rw := a.TestRequest("GET", "/test", nil)
+	require.Equal(t, http.StatusInternalServerError, rw.Code)
+	require.Contains(t, logs.String(), "ContextFactory returned a nil Context")
+}
+
+func TestAppStrictSlash(t *testing.T) {
+	a := Basic(WithStrictSlash(true))
+	a.HandleFunc("/foo", func(ctx Context) {
+		ctx.Text("foo")
+	})
+	a.HandleFunc("/bar/", func(ctx Context) {
+		ctx.Text("bar")
+	})
+
+	rw := a.TestRequest("GET", "/foo/?a=b", nil)
+	require.Equal(t, http.StatusPermanentRedirect, rw.Code)
+	require.Equal(t, "/foo?a=b", rw.Header().Get("Location"))
+
+	rw = a.TestRequest("GET", "/bar/", nil)
+	require.Equal(t, "bar", rw.Body.String())
+
+	rw = a.TestRequest("GET", "/bar/baz/", nil)
+	require.Equal(t, "bar", rw.Body.String())
+
+	rw = a.TestRequest("GET", "/missing/", nil)
+	require.Equal(t, http.StatusNotFound, rw.Code)
+
+	rw = a.TestRequest("GET", "/debug/pprof/", nil)
+	require.Equal(t, http.StatusOK, rw.Code)
+}
+
+func TestAppTrailingNewline(t *testing.T) {
+	a := Basic()
+	res := a.TestRequest("GET", DefaultLivenessPath, nil)
+	require.Equal(t, "OK", res.Body.String())
+	require.Equal(t, ContentTypeTextPlainUTF8, res.Header().Get("Content-Type"))
+
+	a = Basic(WithTrailingNewline(true))
+	res = a.TestRequest("GET", DefaultLivenessPath, nil)
+	require.Equal(t, "OK\n", res.Body.String())
+	require.Equal(t, "3", res.Header().Get("Content-Length"))
+}
+
+func TestAppHandleFuncUpgrade(t *testing.T) {
+	a := Basic(WithConcurrency(1), WithGetDeduplication(true))
+	a.HandleFuncUpgrade("/echo", func(ctx Context) {
+		conn, brw, err := ctx.Hijack()
+		require.NoError(t, err)
+		defer conn.Close()
+		_, _ = brw.WriteString("HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: echo\r\n\r\n")
+		_ = brw.Flush()
+		line, _ := brw.ReadString('\n')
+		_, _ = brw.WriteString(line)
+		_ = brw.Flush()
+	})
+	a.HandleFunc("/test", func(ctx Context) {
+		ctx.Text("OK")
+	})
+
+	s := httptest.NewServer(a)
+	defer s.Close()
+
+	conn, err := net.Dial("tcp", s.Listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte("GET /echo HTTP/1.1\r\nHost: example.com\r\nConnection: Upgrade\r\nUpgrade: echo\r\n\r\n"))
+	require.NoError(t, err)
+
+	br := bufio.NewReader(conn)
+	res, err := http.ReadResponse(br, nil)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusSwitchingProtocols, res.StatusCode)
+
+	// concurrency slot is not held by the upgraded connection
+	res, err = http.Get(s.URL + "/test")
+	require.NoError(t, err)
+	buf, _ := io.ReadAll(res.Body)
+	_ = res.Body.Close()
+	require.Equal(t, "OK", string(buf))
+
+	_, err = conn.Write([]byte("hello\n"))
+	require.NoError(t, err)
+	line, err := br.ReadString('\n')
+	require.NoError(t, err)
+	require.Equal(t, "hello\n", line)
+}
+
+func TestAppConcurrencyWatermark(t *testing.T) {
+	buf := &bytes.Buffer{}
+	a := Basic(WithConcurrency(2), WithConcurrencyWatermark(0.6), WithLogger(slog.New(slog.NewTextHandler(buf, nil))))
+	a.HandleFunc("/test", func(ctx Context) {
+		ctx.Text("OK")
+	})
+
+	for i := 0; i < 3; i++ {
+		require.Equal(t, "OK", a.TestRequest("GET", "/test", nil).Body.String())
+	}
+	require.Equal(t, 1, strings.Count(buf.String(), "concurrency slots below watermark"))
+	require.Equal(t, 2, len(a.(*app[Context]).cc))
+}
+
+func TestAppCheckOutputFormat(t *testing.T) {
+	a := Basic(WithCheckOutputFormat(CheckOutputFormat{LineSeparator: "; ", NameSeparator: "=", OKText: "UP"}))
+	a.Component("a").Check(func(ctx context.Context) error {
+		return nil
+	})
+	a.Component("b").Check(func(ctx context.Context) error {
+		return errors.New("down")
+	})
+	res := a.TestRequest("GET", DefaultReadinessPath, nil)
+	require.Equal(t, http.StatusInternalServerError, res.Code)
+	require.Equal(t, "a=UP; b=down", res.Body.String())
+
+	a = Basic(WithCheckOutputFormat(CheckOutputFormat{OKText: "UP"}))
+	require.Equal(t, "UP", a.TestRequest("GET", DefaultReadinessPath, nil).Body.String())
+}
+
+func TestAppIPAllowlist(t *testing.T) {
+	require.Panics(t, func() {
+		WithIPAllowlist([]string{"POST"}, []string{"bad"})
+	})
+
+	a := Basic(
+		WithIPAllowlist([]string{"post", "DELETE"}, []string{"10.0.0.0/8"}),
+		WithTrustedProxies([]string{"192.168.0.0/16"}),
+	)
+	a.HandleFunc("/test", func(ctx Context) {
+		ctx.Text("OK")
+	})
+
+	serve := func(method, remoteAddr, xff string) int {
+		rw, req := httptest.NewRecorder(), httptest.NewRequest(method, "/test", nil)
+		req.RemoteAddr = remoteAddr
+		if xff != "" {
+			req.Header.Set("X-Forwarded-For", xff)
+		}
+		a.ServeHTTP(rw, req)
+		return rw.Code
+	}
+
+	require.Equal(t, http.StatusOK, serve("GET", "1.2.3.4:1234", ""))
+	require.Equal(t, http.StatusOK, serve("POST", "10.1.2.3:1234", ""))
+	require.Equal(t, http.StatusForbidden, serve("POST", "1.2.3.4:1234", ""))
+	require.Equal(t, http.StatusForbidden, serve("DELETE", "1.2.3.4:1234", "10.1.2.3"))
+	require.Equal(t, http.StatusOK, serve("DELETE", "192.168.1.1:1234", "10.1.2.3"))
+	require.Equal(t, http.StatusForbidden, serve("DELETE", "192.168.1.1:1234", "1.2.3.4"))
+}
+
+func TestAppPathPrefix(t *testing.T) {
+	require.Panics(t, func() {
+		WithPathPrefix("prod")
+	})
+
+	buf := &bytes.Buffer{}
+	a := Basic(WithPathPrefix("/prod/"), WithLogger(slog.New(slog.NewTextHandler(buf, nil))))
+	a.HandleFunc("/", func(ctx Context) {
+		ctx.Text("ROOT " + ctx.Req().URL.Path)
+	})
+	a.HandleFunc("/users", func(ctx Context) {
+		ctx.Text("USERS")
+	})
+
+	require.Equal(t, "USERS", a.TestRequest("GET", "/prod/users", nil).Body.String())
+	require.Equal(t, "ROOT /", a.TestRequest("GET", "/prod", nil).Body.String())
+	require.Equal(t, "ROOT /production/users", a.TestRequest("GET", "/production/users", nil).Body.String())
+	require.Contains(t, buf.String(), "path prefix not found")
+
+	buf.Reset()
+	require.Equal(t, http.StatusOK, a.TestRequest("GET", DefaultLivenessPath, nil).Code)
+	require.Equal(t, http.StatusOK, a.TestRequest("GET", "/prod"+DefaultLivenessPath, nil).Code)
+	require.Empty(t, buf.String())
+}
+
+func TestAppHeartbeat(t *testing.T) {
+	var lines int64
+	logger := slog.New(slog.NewTextHandler(writerFunc(func(p []byte) (int, error) {
+		if bytes.Contains(p, []byte("msg=heartbeat")) && bytes.Contains(p, []byte("requests=1")) {
+			atomic.AddInt64(&lines, 1)
+		}
+		return len(p), nil
+	}), nil))
+
+	a := Basic(WithHeartbeat(time.Millisecond*10, logger))
+	a.HandleFunc("/test", func(ctx Context) {})
+	a.TestRequest("GET", "/test", nil)
+
+	// not a checked component, name is free for components
+	require.Empty(t, a.CheckNames())
+	require.NotContains(t, a.TestRequest("GET", "/debug/ready", nil).Body.String(), "heartbeat")
+	require.NotPanics(t, func() {
+		a.Component("heartbeat")
+	})
+
+	require.NoError(t, a.Startup(context.Background()))
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt64(&lines) > 0
+	}, time.Second, time.Millisecond*10)
+	require.NoError(t, a.Shutdown(context.Background()))
+	require.NoError(t, a.Shutdown(context.Background()))
+
+	// restarted
+	require.NoError(t, a.Startup(context.Background()))
+	require.NoError(t, a.Shutdown(context.Background()))
+
+	// heartbeat shutdown called twice, like a failed startup followed by shutdown
+	r := a.(*app[Context]).registry
+	for _, reg := range r.regs {
+		if reg.name == "heartbeat" && reg.hooked {
+			require.NoError(t, reg.startup(context.Background()))
+			require.NoError(t, reg.shutdown(context.Background()))
+			require.NoError(t, reg.shutdown(context.Background()))
+		}
+	}
+
+	res := a.TestRequest("GET", "/debug/metrics", nil)
+	require.NotContains(t, res.Body.String(), "summer_requests_total")
+	require.Contains(t, res.Body.String(), `summer_http_requests_total{route="/test",status_class="2xx"} 1`)
+}
+
+type writerFunc func(p []byte) (int, error)
+
+func (fn writerFunc) Write(p []byte) (int, error) {
+	return fn(p)
+}
+
+func TestAppOTelOptions(t *testing.T) {
+	traceIDOf := func(ctx Context) {
+		ctx.Text(trace.SpanContextFromContext(ctx).TraceID().String())
+	}
+	const traceparent = "00-0102030405060708090a0b0c0d0e0f10-0102030405060708-01"
+
+	a := Basic(
+		WithOTelPropagators(propagation.TraceContext{}),
+		WithOTelFilter(func(req *http.Request) bool {
+			return !strings.HasPrefix(req.URL.Path, "/static/")
+		}),
+	)
+	a.HandleFunc("/traced", traceIDOf)
+	a.HandleFunc("/static/", traceIDOf)
+
+	request := func(a App[Context], path string) string {
+		rw, req := httptest.NewRecorder(), httptest.NewRequest("GET", path, nil)
+		req.Header.Set("traceparent", traceparent)
+		a.ServeHTTP(rw, req)
+		return rw.Body.String()
+	}
+
+	require.Equal(t, "0102030405060708090a0b0c0d0e0f10", request(a, "/traced"))
+	require.Equal(t, trace.TraceID{}.String(), request(a, "/static/a.js"))
+
+	a = Basic(WithOTel(false), WithOTelPropagators(propagation.TraceContext{}))
+	a.HandleFunc("/traced", traceIDOf)
+	require.Equal(t, trace.TraceID{}.String(), request(a, "/traced"))
+}
+
+func TestAppTraceID(t *testing.T) {
+	const traceparent = "00-0102030405060708090a0b0c0d0e0f10-0102030405060708-01"
+
+	request := func(a App[Context]) *httptest.ResponseRecorder {
+		a.HandleFunc("/test", func(ctx Context) {
+			ctx.Text(ctx.TraceID())
+		})
+		rw, req := httptest.NewRecorder(), httptest.NewRequest("GET", "/test", nil)
+		req.Header.Set("traceparent", traceparent)
+		a.ServeHTTP(rw, req)
+		return rw
+	}
+
+	rw := request(Basic(WithOTelPropagators(propagation.TraceContext{})))
+	require.Equal(t, "0102030405060708090a0b0c0d0e0f10", rw.Body.String())
+	require.Equal(t, "0102030405060708090a0b0c0d0e0f10", rw.Header().Get("X-Trace-Id"))
+
+	rw = request(Basic(WithOTelPropagators(propagation.TraceContext{}), WithTraceIDHeader("")))
+	require.Equal(t, "0102030405060708090a0b0c0d0e0f10", rw.Body.String())
+	require.Empty(t, rw.Header().Get("X-Trace-Id"))
+
+	rw = request(Basic())
+	require.Empty(t, rw.Body.String())
+	require.Empty(t, rw.Header().Get("X-Trace-Id"))
+}
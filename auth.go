@@ -0,0 +1,222 @@
+package summer
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// Principal authenticated identity of a request, see [Context.Principal]
+type Principal struct {
+	// Subject identifier of principal, like user id, name of api key or username
+	Subject string `json:"subject"`
+	// Method authentication method, like "bearer", "api_key" or "basic"
+	Method string `json:"method"`
+	// Claims additional claims, like claims of a JWT
+	Claims map[string]any `json:"claims,omitempty"`
+}
+
+const (
+	AuthMethodBearer = "bearer"
+	AuthMethodAPIKey = "api_key"
+	AuthMethodBasic  = "basic"
+)
+
+var (
+	// ErrCredentialsMissing no credentials found in request by any [Authenticator]
+	ErrCredentialsMissing = errors.New("credentials missing")
+	// ErrCredentialsInvalid credentials found but not accepted
+	ErrCredentialsInvalid = errors.New("credentials invalid")
+)
+
+// Authenticator authenticate a request
+//
+// Authenticate returns nil [Principal] and nil error if no credentials of its kind found, so that next [Authenticator] is tried,
+// and a non-nil error if credentials found but invalid
+type Authenticator[T Context] interface {
+	Authenticate(ctx T) (p *Principal, err error)
+}
+
+// AuthenticatorFunc func adapter of [Authenticator]
+type AuthenticatorFunc[T Context] func(ctx T) (p *Principal, err error)
+
+// Authenticate implements [Authenticator]
+func (fn AuthenticatorFunc[T]) Authenticate(ctx T) (*Principal, error) {
+	return fn(ctx)
+}
+
+// authChallenger optional interface of [Authenticator], returning value of "WWW-Authenticate" header
+type authChallenger interface {
+	challenge() string
+}
+
+type contextKeyPrincipalType int
+
+const contextKeyPrincipal contextKeyPrincipalType = 0
+
+// principalFromContext returns [Principal] stored by [Authenticate], nil if not authenticated
+func principalFromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(contextKeyPrincipal).(*Principal)
+	return p
+}
+
+// haltUnauthorized set "WWW-Authenticate" header with challenges of auths, and panic with a 401 [Error] caused by err
+func haltUnauthorized[T Context](ctx T, auths []Authenticator[T], err error) {
+	for _, auth := range auths {
+		if c, ok := auth.(authChallenger); ok {
+			ctx.Res().Header().Add("WWW-Authenticate", c.challenge())
+		}
+	}
+	panic(NewError(http.StatusUnauthorized, "unauthorized").WithCause(err))
+}
+
+// authenticate try auths in order, returns the first [Principal] found
+func authenticate[T Context](ctx T, auths []Authenticator[T]) (p *Principal, err error) {
+	for _, auth := range auths {
+		if p, err = auth.Authenticate(ctx); err != nil {
+			if !errors.Is(err, ErrCredentialsInvalid) {
+				err = errors.Join(ErrCredentialsInvalid, err)
+			}
+			return
+		}
+		if p != nil {
+			return
+		}
+	}
+	return
+}
+
+// Authenticate create a middleware authenticating requests with auths in order, the first [Principal] found is available from [Context.Principal]
+//
+// Requests without credentials, or with invalid credentials, are rejected with 401 and "WWW-Authenticate" header
+func Authenticate[T Context](auths ...Authenticator[T]) MiddlewareFunc[T] {
+	return authenticateMiddleware(auths, false)
+}
+
+// AuthenticateOptional like [Authenticate], but requests without credentials continue anonymously, with a nil [Context.Principal]
+//
+// Requests with invalid credentials are still rejected with 401
+func AuthenticateOptional[T Context](auths ...Authenticator[T]) MiddlewareFunc[T] {
+	return authenticateMiddleware(auths, true)
+}
+
+func authenticateMiddleware[T Context](auths []Authenticator[T], optional bool) MiddlewareFunc[T] {
+	return func(ctx T, next func()) {
+		p, err := authenticate(ctx, auths)
+		if err != nil {
+			haltUnauthorized(ctx, auths, err)
+		}
+		if p == nil {
+			if !optional {
+				haltUnauthorized(ctx, auths, ErrCredentialsMissing)
+			}
+			next()
+			return
+		}
+		ctx.Inject(func(c context.Context) context.Context {
+			return context.WithValue(c, contextKeyPrincipal, p)
+		})
+		next()
+	}
+}
+
+// Authorize create a middleware rejecting requests not authenticated by [Authenticate] with 401, and requests fn returns false with 403
+func Authorize[T Context](fn func(ctx T, p *Principal) bool) MiddlewareFunc[T] {
+	return func(ctx T, next func()) {
+		p := ctx.Principal()
+		if p == nil {
+			panic(NewError(http.StatusUnauthorized, "unauthorized").WithCause(ErrCredentialsMissing))
+		}
+		if !fn(ctx, p) {
+			panic(NewError(http.StatusForbidden, "forbidden"))
+		}
+		next()
+	}
+}
+
+type basicAuthenticator[T Context] struct {
+	realm string
+	fn    func(ctx T, username, password string) (*Principal, error)
+}
+
+func (a *basicAuthenticator[T]) Authenticate(ctx T) (p *Principal, err error) {
+	username, password, ok := ctx.Req().BasicAuth()
+	if !ok {
+		return
+	}
+	if p, err = a.fn(ctx, username, password); err == nil && p == nil {
+		err = ErrCredentialsInvalid
+	}
+	return
+}
+
+func (a *basicAuthenticator[T]) challenge() string {
+	return `Basic realm="` + strings.ReplaceAll(a.realm, `"`, `\"`) + `"`
+}
+
+// BasicAuth create an [Authenticator] of HTTP basic authentication, fn verifies username and password
+//
+// fn returns nil [Principal] or an error for invalid credentials, see [BasicAuthUsers] for a static user list
+func BasicAuth[T Context](realm string, fn func(ctx T, username, password string) (*Principal, error)) Authenticator[T] {
+	return &basicAuthenticator[T]{realm: realm, fn: fn}
+}
+
+// BasicAuthUsers create a verify function of [BasicAuth] with static username and password pairs, compared in constant time
+func BasicAuthUsers[T Context](users map[string]string) func(ctx T, username, password string) (*Principal, error) {
+	return func(ctx T, username, password string) (*Principal, error) {
+		expected, ok := users[username]
+		if !ok || subtle.ConstantTimeCompare([]byte(expected), []byte(password)) != 1 {
+			return nil, ErrCredentialsInvalid
+		}
+		return &Principal{Subject: username, Method: AuthMethodBasic}, nil
+	}
+}
+
+type apiKeyAuthenticator[T Context] struct {
+	header string
+	fn     func(ctx T, key string) (*Principal, error)
+}
+
+func (a *apiKeyAuthenticator[T]) Authenticate(ctx T) (p *Principal, err error) {
+	key := ctx.Req().Header.Get(a.header)
+	if key == "" {
+		return
+	}
+	if p, err = a.fn(ctx, key); err == nil && p == nil {
+		err = ErrCredentialsInvalid
+	}
+	return
+}
+
+// APIKeyAuth create an [Authenticator] of api key from header, defaults to "X-API-Key" if header is empty, fn verifies the key
+//
+// fn returns nil [Principal] or an error for invalid key, see [APIKeys] for a static key list
+func APIKeyAuth[T Context](header string, fn func(ctx T, key string) (*Principal, error)) Authenticator[T] {
+	if header == "" {
+		header = DefaultAPIKeyHeader
+	}
+	return &apiKeyAuthenticator[T]{header: header, fn: fn}
+}
+
+// APIKeys create a verify function of [APIKeyAuth] with static keys mapped to subjects, compared in constant time
+func APIKeys[T Context](keys map[string]string) func(ctx T, key string) (*Principal, error) {
+	return func(ctx T, key string) (*Principal, error) {
+		for k, subject := range keys {
+			if subtle.ConstantTimeCompare([]byte(k), []byte(key)) == 1 {
+				return &Principal{Subject: subject, Method: AuthMethodAPIKey}, nil
+			}
+		}
+		return nil, ErrCredentialsInvalid
+	}
+}
+
+// bearerToken extract bearer token from "Authorization" header of req, empty if absent
+func bearerToken(req *http.Request) string {
+	scheme, token, ok := strings.Cut(req.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
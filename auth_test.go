@@ -0,0 +1,135 @@
+package summer
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAuthenticate(t *testing.T) {
+	a := Basic()
+	a.Use(Authenticate(
+		APIKeyAuth("", APIKeys[Context](map[string]string{"key-1": "service-1"})),
+		BasicAuth("test", BasicAuthUsers[Context](map[string]string{"user-1": "pass-1"})),
+	))
+	a.HandleFunc("/test-auth", func(ctx Context) {
+		p := ctx.Principal()
+		ctx.Text(p.Method + ":" + p.Subject)
+	})
+
+	request := func(fn func(req *http.Request)) *httptest.ResponseRecorder {
+		rw, req := httptest.NewRecorder(), httptest.NewRequest("GET", "https://example.com/test-auth", nil)
+		fn(req)
+		a.ServeHTTP(rw, req)
+		return rw
+	}
+
+	rw := request(func(req *http.Request) {})
+	require.Equal(t, http.StatusUnauthorized, rw.Code)
+	require.Equal(t, []string{`Basic realm="test"`}, rw.Header().Values("WWW-Authenticate"))
+	var m map[string]any
+	require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &m))
+	require.Equal(t, "unauthorized", m["message"])
+
+	rw = request(func(req *http.Request) {
+		req.Header.Set(DefaultAPIKeyHeader, "key-1")
+	})
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Equal(t, "api_key:service-1", rw.Body.String())
+
+	rw = request(func(req *http.Request) {
+		req.SetBasicAuth("user-1", "pass-1")
+	})
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Equal(t, "basic:user-1", rw.Body.String())
+
+	// invalid credentials are not passed to next authenticator
+	rw = request(func(req *http.Request) {
+		req.Header.Set(DefaultAPIKeyHeader, "key-2")
+		req.SetBasicAuth("user-1", "pass-1")
+	})
+	require.Equal(t, http.StatusUnauthorized, rw.Code)
+
+	rw = request(func(req *http.Request) {
+		req.SetBasicAuth("user-1", "pass-2")
+	})
+	require.Equal(t, http.StatusUnauthorized, rw.Code)
+	require.Equal(t, `Basic realm="test"`, rw.Header().Get("WWW-Authenticate"))
+
+	rw = request(func(req *http.Request) {
+		req.SetBasicAuth("user-2", "pass-1")
+	})
+	require.Equal(t, http.StatusUnauthorized, rw.Code)
+}
+
+func TestAuthenticateOptional(t *testing.T) {
+	a := Basic()
+	a.Use(AuthenticateOptional(
+		AuthenticatorFunc[Context](func(ctx Context) (*Principal, error) {
+			switch ctx.Req().Header.Get("X-User") {
+			case "":
+				return nil, nil
+			case "bad":
+				return nil, errors.New("bad user")
+			default:
+				return &Principal{Subject: ctx.Req().Header.Get("X-User"), Method: "header"}, nil
+			}
+		}),
+	))
+	a.HandleFunc("/test-auth-optional", func(ctx Context) {
+		if p := ctx.Principal(); p != nil {
+			ctx.Text(p.Subject)
+		} else {
+			ctx.Text("anonymous")
+		}
+	})
+
+	rw := a.TestRequest("GET", "/test-auth-optional", nil)
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Equal(t, "anonymous", rw.Body.String())
+
+	rw, req := httptest.NewRecorder(), httptest.NewRequest("GET", "https://example.com/test-auth-optional", nil)
+	req.Header.Set("X-User", "alice")
+	a.ServeHTTP(rw, req)
+	require.Equal(t, "alice", rw.Body.String())
+
+	rw, req = httptest.NewRecorder(), httptest.NewRequest("GET", "https://example.com/test-auth-optional", nil)
+	req.Header.Set("X-User", "bad")
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusUnauthorized, rw.Code)
+	require.Empty(t, rw.Header().Get("WWW-Authenticate"))
+	require.NotContains(t, rw.Body.String(), "bad user")
+}
+
+func TestAuthorize(t *testing.T) {
+	a := Basic()
+	g := a.Group("/admin", Authenticate(
+		APIKeyAuth("X-Key", APIKeys[Context](map[string]string{"key-admin": "admin", "key-user": "user"})),
+	), Authorize(func(ctx Context, p *Principal) bool {
+		return p.Subject == "admin"
+	}))
+	g.HandleFunc("/test", func(ctx Context) {
+		ctx.Text("OK")
+	})
+	a.Group("/unauthenticated", Authorize(func(ctx Context, p *Principal) bool {
+		return true
+	})).HandleFunc("/test", func(ctx Context) {
+		ctx.Text("OK")
+	})
+
+	request := func(path, key string) int {
+		rw, req := httptest.NewRecorder(), httptest.NewRequest("GET", "https://example.com"+path, nil)
+		req.Header.Set("X-Key", key)
+		a.ServeHTTP(rw, req)
+		return rw.Code
+	}
+
+	require.Equal(t, http.StatusOK, request("/admin/test", "key-admin"))
+	require.Equal(t, http.StatusForbidden, request("/admin/test", "key-user"))
+	require.Equal(t, http.StatusUnauthorized, request("/admin/test", "key-none"))
+	require.Equal(t, http.StatusUnauthorized, request("/admin/test", ""))
+	require.Equal(t, http.StatusUnauthorized, request("/unauthenticated/test", "key-admin"))
+}
@@ -0,0 +1,189 @@
+package summer
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// bindTags struct tags binding values directly from request, in order of precedence
+var bindTags = []string{"path", "query", "header"}
+
+// taggedJSONNames returns json names of fields with "path", "query" or "header" tags, of struct pointed by data
+func taggedJSONNames(data any) (names []string) {
+	rt := reflect.TypeOf(data)
+	if rt == nil || rt.Kind() != reflect.Pointer || rt.Elem().Kind() != reflect.Struct {
+		return
+	}
+	var walk func(rt reflect.Type)
+	walk = func(rt reflect.Type) {
+		for i := 0; i < rt.NumField(); i++ {
+			field := rt.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			if field.Anonymous && field.Type.Kind() == reflect.Struct {
+				walk(field.Type)
+				continue
+			}
+			for _, tag := range bindTags {
+				if _, ok := field.Tag.Lookup(tag); ok {
+					name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+					if name == "" {
+						name = field.Name
+					}
+					names = append(names, name)
+					break
+				}
+			}
+		}
+	}
+	walk(rt.Elem())
+	return
+}
+
+// unmarshalExcluding unmarshal json object buf into data, excluding keys matching names case-insensitively, like [json.Unmarshal] does
+func unmarshalExcluding(buf []byte, data any, names []string) (err error) {
+	if len(names) > 0 {
+		var m map[string]json.RawMessage
+		if err = json.Unmarshal(buf, &m); err != nil {
+			return
+		}
+		for k := range m {
+			for _, name := range names {
+				if strings.EqualFold(k, name) {
+					delete(m, k)
+					break
+				}
+			}
+		}
+		if buf, err = json.Marshal(m); err != nil {
+			return
+		}
+	}
+	return json.Unmarshal(buf, data)
+}
+
+// bindTagged set fields of struct pointed by data with "path", "query" and "header" tags from req
+//
+// Fields of embedded structs are included, missing values are skipped
+func bindTagged(req *http.Request, data any) error {
+	rv := reflect.ValueOf(data)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return nil
+	}
+	rv = rv.Elem()
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+	return bindTaggedStruct(req, rv)
+}
+
+func bindTaggedStruct(req *http.Request, rv reflect.Value) (err error) {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field, fv := rt.Field(i), rv.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			if err = bindTaggedStruct(req, fv); err != nil {
+				return
+			}
+			continue
+		}
+		for _, tag := range bindTags {
+			name, ok := field.Tag.Lookup(tag)
+			if !ok || name == "" || name == "-" {
+				continue
+			}
+			var values []string
+			switch tag {
+			case "path":
+				if v := req.PathValue(name); v != "" {
+					values = []string{v}
+				}
+			case "query":
+				values = req.URL.Query()[name]
+			case "header":
+				values = req.Header.Values(name)
+			}
+			if len(values) == 0 {
+				continue
+			}
+			if err = setFieldStrings(fv, values); err != nil {
+				return NewHaltError(fmt.Errorf("invalid %s %q: %w", tag, name, err), HaltWithBadRequest())
+			}
+			break
+		}
+	}
+	return
+}
+
+// setFieldStrings set a field from string values, slices take all values, others take the first one
+func setFieldStrings(fv reflect.Value, values []string) error {
+	if fv.Kind() == reflect.Slice {
+		s := reflect.MakeSlice(fv.Type(), len(values), len(values))
+		for i, v := range values {
+			if err := setFieldString(s.Index(i), v); err != nil {
+				return err
+			}
+		}
+		fv.Set(s)
+		return nil
+	}
+	return setFieldString(fv, values[0])
+}
+
+// setFieldString set a field of basic kind from a string
+func setFieldString(fv reflect.Value, s string) error {
+	if fv.Kind() == reflect.Pointer {
+		if fv.IsNil() {
+			fv.Set(reflect.New(fv.Type().Elem()))
+		}
+		fv = fv.Elem()
+	}
+	if fv.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(int64(d))
+		return nil
+	}
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(s)
+	case reflect.Bool:
+		v, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(v)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v, err := strconv.ParseInt(s, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetInt(v)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v, err := strconv.ParseUint(s, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetUint(v)
+	case reflect.Float32, reflect.Float64:
+		v, err := strconv.ParseFloat(s, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(v)
+	default:
+		return fmt.Errorf("unsupported field type %s", fv.Type())
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package summer
+
+import (
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestContextBindTagged(t *testing.T) {
+	type Common struct {
+		Tenant string `header:"X-Tenant"`
+	}
+	type args struct {
+		Common
+		ID      int64         `path:"id"`
+		Page    *int          `query:"page"`
+		Tags    []string      `query:"tag"`
+		Timeout time.Duration `query:"timeout"`
+		Debug   bool          `query:"debug" header:"X-Debug"`
+		Name    string        `json:"name"`
+	}
+
+	a := Basic()
+	a.HandleFunc("/users/{id}", func(ctx Context) {
+		var o args
+		if err := ctx.ShouldBind(&o); err != nil {
+			ctx.Code(http.StatusBadRequest)
+			ctx.Text(err.Error())
+			return
+		}
+		ctx.JSON(o)
+	})
+
+	rw, req := httptest.NewRecorder(), httptest.NewRequest("POST", "/users/42?page=2&tag=a&tag=b&timeout=3s", strings.NewReader(`{"name":"alice"}`))
+	req.Header.Set("Content-Type", ContentTypeApplicationJSON)
+	req.Header.Set("X-Tenant", "acme")
+	req.Header.Set("X-Debug", "true")
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.JSONEq(t, `{"Tenant":"acme","ID":42,"Page":2,"Tags":["a","b"],"Timeout":3000000000,"Debug":true,"name":"alice"}`, rw.Body.String())
+
+	rw = a.TestRequest("GET", "/users/abc", nil)
+	require.Equal(t, http.StatusBadRequest, rw.Code)
+	require.Contains(t, rw.Body.String(), `invalid path "id"`)
+
+	a.HandleFunc("/bind/{id}", func(ctx Context) {
+		ctx.Text(strings.Join(Bind[args](ctx).Tags, ","))
+	})
+	require.Equal(t, "x", a.TestRequest("GET", "/bind/1?tag=x", nil).Body.String())
+	require.Equal(t, http.StatusBadRequest, a.TestRequest("GET", "/bind/1?page=x", nil).Code)
+}
@@ -0,0 +1,78 @@
+package summer
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// requestBody a decompressed request body, closing both the decompressor and the underlying body
+type requestBody struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (b *requestBody) Close() (err error) {
+	for _, c := range b.closers {
+		if err1 := c.Close(); err1 != nil && err == nil {
+			err = err1
+		}
+	}
+	return
+}
+
+// prepareRequestBody decompress body of req if enabled by [WithRequestDecompression], and limit it by [WithMaxBodyBytes]
+//
+// Both compressed and decompressed body are limited. Returns a non-zero status code and a message if req is rejected
+func prepareRequestBody(rw http.ResponseWriter, req *http.Request, opts *options) (code int, message string) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return
+	}
+
+	limit := opts.maxBodyBytes
+	if limit > 0 {
+		if req.ContentLength > limit {
+			return http.StatusRequestEntityTooLarge, "REQUEST BODY TOO LARGE"
+		}
+		req.Body = http.MaxBytesReader(rw, req.Body, limit)
+	}
+
+	encoding := strings.ToLower(strings.TrimSpace(req.Header.Get("Content-Encoding")))
+	if !opts.requestDecompression || encoding == "" || encoding == "identity" {
+		return
+	}
+
+	var (
+		r   io.ReadCloser
+		err error
+	)
+	switch encoding {
+	case EncodingGzip, "x-gzip":
+		r, err = gzip.NewReader(req.Body)
+	case EncodingDeflate:
+		r, err = zlib.NewReader(req.Body)
+	default:
+		return http.StatusUnsupportedMediaType, "UNSUPPORTED CONTENT ENCODING: " + encoding
+	}
+	if err != nil {
+		var mbe *http.MaxBytesError
+		if errors.As(err, &mbe) {
+			return http.StatusRequestEntityTooLarge, "REQUEST BODY TOO LARGE"
+		}
+		return http.StatusBadRequest, "INVALID " + strings.ToUpper(encoding) + " BODY"
+	}
+
+	body := &requestBody{Reader: r, closers: []io.Closer{r, req.Body}}
+	if limit > 0 {
+		req.Body = http.MaxBytesReader(rw, body, limit)
+	} else {
+		req.Body = body
+	}
+	req.Header.Del("Content-Encoding")
+	req.Header.Del("Content-Length")
+	req.ContentLength = -1
+	return
+}
@@ -0,0 +1,125 @@
+package summer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWithMaxBodyBytesEarly(t *testing.T) {
+	var called bool
+
+	a := Basic(WithMaxBodyBytes(16))
+	a.HandleFunc("/test-body-bind", func(ctx Context) {
+		called = true
+		args := Bind[struct {
+			Name string `json:"name"`
+		}](ctx)
+		ctx.Text(args.Name)
+	})
+	a.HandleFunc("/test-body-raw", func(ctx Context) {
+		_, err := io.ReadAll(ctx.Req().Body)
+		var mbe *http.MaxBytesError
+		require.True(t, errors.As(err, &mbe))
+		ctx.Text("LIMITED")
+	})
+	a.HandleFunc("/test-body-route", func(ctx Context) {
+		buf, err := ctx.RawBody()
+		require.NoError(t, err)
+		ctx.Text(string(buf))
+	}, WithMaxBodyBytes(1024))
+
+	large := `{"name":"` + strings.Repeat("a", 100) + `"}`
+
+	// rejected by content length
+	rw, req := httptest.NewRecorder(), httptest.NewRequest("POST", "https://example.com/test-body-bind", strings.NewReader(large))
+	req.Header.Set("Content-Type", ContentTypeApplicationJSON)
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusRequestEntityTooLarge, rw.Code)
+	require.Equal(t, "REQUEST BODY TOO LARGE", rw.Body.String())
+	require.False(t, called)
+
+	// rejected while reading body without content length
+	rw, req = httptest.NewRecorder(), httptest.NewRequest("POST", "https://example.com/test-body-bind", io.MultiReader(strings.NewReader(large)))
+	req.Header.Set("Content-Type", ContentTypeApplicationJSON)
+	req.ContentLength = -1
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusRequestEntityTooLarge, rw.Code)
+	require.True(t, called)
+
+	rw, req = httptest.NewRecorder(), httptest.NewRequest("POST", "https://example.com/test-body-raw", io.MultiReader(strings.NewReader(large)))
+	req.ContentLength = -1
+	a.ServeHTTP(rw, req)
+	require.Equal(t, "LIMITED", rw.Body.String())
+
+	rw, req = httptest.NewRecorder(), httptest.NewRequest("POST", "https://example.com/test-body-route", strings.NewReader(large))
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Equal(t, large, rw.Body.String())
+
+	res := a.TestRequest("GET", "/debug/metrics", nil)
+	require.Contains(t, res.Body.String(), `summer_rejected_requests_total{reason="body_too_large"} 1`)
+}
+
+func TestWithRequestDecompression(t *testing.T) {
+	a := Basic(WithRequestDecompression(true), WithMaxBodyBytes(1024))
+	a.HandleFunc("/test-decompress", func(ctx Context) {
+		args := Bind[struct {
+			Name string `json:"name"`
+		}](ctx)
+		require.Empty(t, ctx.Req().Header.Get("Content-Encoding"))
+		ctx.Text(args.Name)
+	})
+
+	compress := func(encoding string, s string) []byte {
+		buf := &bytes.Buffer{}
+		var w io.WriteCloser
+		if encoding == EncodingGzip {
+			w = gzip.NewWriter(buf)
+		} else {
+			w = zlib.NewWriter(buf)
+		}
+		_, _ = w.Write([]byte(s))
+		_ = w.Close()
+		return buf.Bytes()
+	}
+
+	request := func(encoding string, body []byte) *httptest.ResponseRecorder {
+		rw, req := httptest.NewRecorder(), httptest.NewRequest("POST", "https://example.com/test-decompress", bytes.NewReader(body))
+		req.Header.Set("Content-Type", ContentTypeApplicationJSON)
+		req.Header.Set("Content-Encoding", encoding)
+		a.ServeHTTP(rw, req)
+		return rw
+	}
+
+	rw := request(EncodingGzip, compress(EncodingGzip, `{"name":"gzipped"}`))
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Equal(t, "gzipped", rw.Body.String())
+
+	rw = request(EncodingDeflate, compress(EncodingDeflate, `{"name":"deflated"}`))
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Equal(t, "deflated", rw.Body.String())
+
+	rw = request("", []byte(`{"name":"plain"}`))
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Equal(t, "plain", rw.Body.String())
+
+	rw = request(EncodingBrotli, []byte("whatever"))
+	require.Equal(t, http.StatusUnsupportedMediaType, rw.Code)
+
+	rw = request(EncodingGzip, []byte("not gzipped"))
+	require.Equal(t, http.StatusBadRequest, rw.Code)
+
+	// decompressed body is limited as well
+	bomb := compress(EncodingGzip, `{"name":"`+strings.Repeat("a", 100*1024)+`"}`)
+	require.Less(t, len(bomb), 1024)
+	rw = request(EncodingGzip, bomb)
+	require.Equal(t, http.StatusRequestEntityTooLarge, rw.Code)
+}
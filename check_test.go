@@ -0,0 +1,35 @@
+package summer
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAppCheckHTTP(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/ok" {
+			rw.WriteHeader(http.StatusNoContent)
+		} else {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer s.Close()
+
+	a := Basic(WithCheckHTTPDialer(&net.Dialer{Timeout: time.Second}))
+	require.NotEqual(t, http.DefaultClient, a.(*app[Context]).checkClient)
+
+	a.CheckHTTP("ok", s.URL+"/ok")
+	a.CheckHTTP("bad", s.URL+"/bad")
+
+	results := map[string]error{}
+	a.Check(context.Background(), func(name string, err error) {
+		results[name] = err
+	})
+	require.NoError(t, results["ok"])
+	require.Error(t, results["bad"])
+}
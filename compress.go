@@ -0,0 +1,274 @@
+package summer
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"github.com/andybalholm/brotli"
+	"golang.org/x/net/http/httpguts"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	EncodingBrotli  = "br"
+	EncodingGzip    = "gzip"
+	EncodingDeflate = "deflate"
+)
+
+// Compression response compression, see [WithCompression]
+type Compression struct {
+	// MinSize minimum size of response body to compress, defaults to 1024, a negative value compresses any size
+	MinSize int
+	// ContentTypes compressible media types, a value ending with "/" matches as prefix, defaults to common text, JSON, XML and JavaScript types
+	ContentTypes []string
+	// Encodings enabled encodings in preference order, defaults to "br", "gzip" and "deflate"
+	Encodings []string
+}
+
+var (
+	defaultCompressionContentTypes = []string{
+		"text/html",
+		"text/plain",
+		"text/css",
+		"text/csv",
+		"text/xml",
+		"text/javascript",
+		"application/json",
+		"application/javascript",
+		"application/xml",
+		"application/x-ndjson",
+		"image/svg+xml",
+	}
+	defaultCompressionEncodings = []string{EncodingBrotli, EncodingGzip, EncodingDeflate}
+)
+
+// compressEncoder an encoder of a content coding, reusable with Reset
+type compressEncoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+var compressEncoderPools = map[string]*sync.Pool{
+	EncodingBrotli: {New: func() any {
+		return brotli.NewWriterLevel(nil, brotli.DefaultCompression)
+	}},
+	EncodingGzip: {New: func() any {
+		return gzip.NewWriter(nil)
+	}},
+	EncodingDeflate: {New: func() any {
+		return zlib.NewWriter(nil)
+	}},
+}
+
+// compressionPolicy compiled [Compression]
+type compressionPolicy struct {
+	minSize      int
+	contentTypes []string
+	encodings    []string
+}
+
+// newCompressionPolicy compile c, unsupported encodings cause a panic
+func newCompressionPolicy(c Compression) *compressionPolicy {
+	p := &compressionPolicy{
+		minSize:      c.MinSize,
+		contentTypes: c.ContentTypes,
+		encodings:    c.Encodings,
+	}
+	if p.minSize == 0 {
+		p.minSize = 1024
+	}
+	if len(p.contentTypes) == 0 {
+		p.contentTypes = defaultCompressionContentTypes
+	}
+	if len(p.encodings) == 0 {
+		p.encodings = defaultCompressionEncodings
+	}
+	for _, e := range p.encodings {
+		if _, ok := compressEncoderPools[e]; !ok {
+			panic("summer: unsupported compression encoding: " + e)
+		}
+	}
+	return p
+}
+
+// isCompressible check if media type of content type ct is compressible
+func (p *compressionPolicy) isCompressible(ct string) bool {
+	mediaType, _, err := mime.ParseMediaType(ct)
+	if err != nil {
+		return false
+	}
+	for _, t := range p.contentTypes {
+		if mediaType == t || (strings.HasSuffix(t, "/") && strings.HasPrefix(mediaType, t)) {
+			return true
+		}
+	}
+	return false
+}
+
+// negotiateEncoding returns the enabled encoding with the highest quality factor in "Accept-Encoding" header, earlier encodings win ties
+//
+// Empty if none is acceptable
+func negotiateEncoding(acceptEncoding string, encodings []string) (best string) {
+	qs, wildcard := map[string]float64{}, -1.0
+	for _, item := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(item, ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			var err error
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		if name == "*" {
+			wildcard = q
+		} else {
+			qs[name] = q
+		}
+	}
+
+	bestQ := 0.0
+	for _, e := range encodings {
+		q, ok := qs[e]
+		if !ok {
+			q = wildcard
+		}
+		if q > bestQ {
+			best, bestQ = e, q
+		}
+	}
+	return
+}
+
+// compressWriter a [http.ResponseWriter] compressing body with encoding, once status, content type and size of body are eligible
+//
+// Body is buffered until minimum size reached, [http.Flusher] or close called
+type compressWriter struct {
+	http.ResponseWriter
+
+	policy   *compressionPolicy
+	encoding string
+
+	status  int
+	pending bool
+	decided bool
+	buf     []byte
+	enc     compressEncoder
+}
+
+// newCompressWriter create a [compressWriter] for req, nil if req is a HEAD or upgrade request
+func newCompressWriter(rw http.ResponseWriter, req *http.Request, p *compressionPolicy) *compressWriter {
+	if req.Method == http.MethodHead || httpguts.HeaderValuesContainsToken(req.Header["Connection"], "Upgrade") {
+		return nil
+	}
+	rw.Header().Add("Vary", "Accept-Encoding")
+	return &compressWriter{
+		ResponseWriter: rw,
+		policy:         p,
+		encoding:       negotiateEncoding(req.Header.Get("Accept-Encoding"), p.encodings),
+		status:         http.StatusOK,
+	}
+}
+
+func (w *compressWriter) WriteHeader(code int) {
+	if w.decided || w.pending {
+		return
+	}
+	// informational responses
+	if code >= 100 && code < 200 && code != http.StatusSwitchingProtocols {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	w.status, w.pending = code, true
+}
+
+func (w *compressWriter) Write(p []byte) (int, error) {
+	if !w.decided {
+		if w.policy.minSize > 0 && len(w.buf)+len(p) < w.policy.minSize {
+			w.buf = append(w.buf, p...)
+			return len(p), nil
+		}
+		if err := w.decide(true, p); err != nil {
+			return 0, err
+		}
+	}
+	if w.enc != nil {
+		return w.enc.Write(p)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// decide send header with or without compression, and write buffered body, sniffing content type with buffered body and p
+func (w *compressWriter) decide(sizeReached bool, p []byte) (err error) {
+	w.decided = true
+
+	h := w.Header()
+	if h.Get("Content-Type") == "" && (len(w.buf) > 0 || len(p) > 0) {
+		h.Set("Content-Type", http.DetectContentType(append(w.buf[:len(w.buf):len(w.buf)], p...)))
+	}
+
+	if sizeReached &&
+		w.encoding != "" &&
+		w.status >= http.StatusOK &&
+		w.status != http.StatusNoContent &&
+		w.status != http.StatusNotModified &&
+		w.status != http.StatusPartialContent &&
+		h.Get("Content-Encoding") == "" &&
+		h.Get("Content-Range") == "" &&
+		w.policy.isCompressible(h.Get("Content-Type")) {
+		h.Set("Content-Encoding", w.encoding)
+		h.Del("Content-Length")
+		w.enc = compressEncoderPools[w.encoding].Get().(compressEncoder)
+		w.enc.Reset(w.ResponseWriter)
+	}
+
+	w.ResponseWriter.WriteHeader(w.status)
+
+	if len(w.buf) > 0 {
+		if w.enc != nil {
+			_, err = w.enc.Write(w.buf)
+		} else {
+			_, err = w.ResponseWriter.Write(w.buf)
+		}
+		w.buf = nil
+	}
+	return
+}
+
+func (w *compressWriter) Flush() {
+	if !w.decided && (w.pending || len(w.buf) > 0) {
+		_ = w.decide(true, nil)
+	}
+	if w.enc != nil {
+		_ = w.enc.Flush()
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// close send pending header and body, and finish compression
+func (w *compressWriter) close() {
+	if !w.decided && (w.pending || len(w.buf) > 0) {
+		_ = w.decide(w.policy.minSize <= 0, nil)
+	}
+	if w.enc != nil {
+		_ = w.enc.Close()
+		w.enc.Reset(nil)
+		compressEncoderPools[w.encoding].Put(w.enc)
+		w.enc = nil
+	}
+}
+
+// Unwrap returns the underlying [http.ResponseWriter], for [http.ResponseController]
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,180 @@
+package summer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"github.com/andybalholm/brotli"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	encodings := []string{EncodingBrotli, EncodingGzip, EncodingDeflate}
+	require.Equal(t, "", negotiateEncoding("", encodings))
+	require.Equal(t, "", negotiateEncoding("identity", encodings))
+	require.Equal(t, EncodingGzip, negotiateEncoding("gzip", encodings))
+	require.Equal(t, EncodingBrotli, negotiateEncoding("gzip, deflate, br", encodings))
+	require.Equal(t, EncodingGzip, negotiateEncoding("br;q=0.5, GZIP", encodings))
+	require.Equal(t, EncodingBrotli, negotiateEncoding("*", encodings))
+	require.Equal(t, EncodingDeflate, negotiateEncoding("br;q=0, gzip;q=0, *;q=0.1", encodings))
+	require.Equal(t, "", negotiateEncoding("gzip;q=0, *;q=0", encodings))
+	require.Equal(t, EncodingGzip, negotiateEncoding("gzip;q=invalid, gzip", encodings))
+}
+
+func TestCompressionPolicy(t *testing.T) {
+	p := newCompressionPolicy(Compression{})
+	require.Equal(t, 1024, p.minSize)
+	require.True(t, p.isCompressible("application/json; charset=utf-8"))
+	require.True(t, p.isCompressible("text/html"))
+	require.False(t, p.isCompressible("text/event-stream"))
+	require.False(t, p.isCompressible("image/png"))
+	require.False(t, p.isCompressible(""))
+
+	p = newCompressionPolicy(Compression{ContentTypes: []string{"text/"}})
+	require.True(t, p.isCompressible("text/event-stream"))
+	require.False(t, p.isCompressible("application/json"))
+
+	require.Panics(t, func() {
+		newCompressionPolicy(Compression{Encodings: []string{"zstd"}})
+	})
+}
+
+func TestWithCompression(t *testing.T) {
+	large := strings.Repeat("summer compression ", 200)
+
+	var entries []AccessLogEntry
+	a := Basic(
+		WithCompression(&Compression{}),
+		WithAccessLogger(func(entry AccessLogEntry) {
+			entries = append(entries, entry)
+		}),
+	)
+	a.HandleFunc("/test-compress-large", func(ctx Context) {
+		ctx.Code(http.StatusCreated)
+		ctx.Text(large)
+	})
+	a.HandleFunc("/test-compress-small", func(ctx Context) {
+		ctx.Text("small")
+	})
+	a.HandleFunc("/test-compress-binary", func(ctx Context) {
+		ctx.Res().Header().Set("Content-Type", "image/png")
+		_, _ = ctx.Res().Write([]byte(large))
+	})
+	a.HandleFunc("/test-compress-range", func(ctx Context) {
+		ctx.Res().Header().Set("Content-Range", "bytes 0-9/100")
+		ctx.Text(large)
+	})
+	a.HandleFunc("/test-compress-disabled", func(ctx Context) {
+		ctx.Text(large)
+	}, WithCompression(nil))
+	a.HandleFunc("/test-compress-stream", func(ctx Context) {
+		ctx.Res().Header().Set("Content-Type", ContentTypeTextPlainUTF8)
+		_, _ = ctx.Res().Write([]byte("chunk-1"))
+		http.NewResponseController(ctx.Res()).Flush()
+		_, _ = ctx.Res().Write([]byte("chunk-2"))
+	})
+
+	request := func(method, path, acceptEncoding string) *httptest.ResponseRecorder {
+		rw, req := httptest.NewRecorder(), httptest.NewRequest(method, "https://example.com"+path, nil)
+		if acceptEncoding != "" {
+			req.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+		a.ServeHTTP(rw, req)
+		return rw
+	}
+
+	decode := func(encoding string, body []byte) string {
+		var r io.Reader
+		var err error
+		switch encoding {
+		case EncodingBrotli:
+			r = brotli.NewReader(bytes.NewReader(body))
+		case EncodingGzip:
+			r, err = gzip.NewReader(bytes.NewReader(body))
+		case EncodingDeflate:
+			r, err = zlib.NewReader(bytes.NewReader(body))
+		default:
+			return string(body)
+		}
+		require.NoError(t, err)
+		buf, err := io.ReadAll(r)
+		require.NoError(t, err)
+		return string(buf)
+	}
+
+	for _, encoding := range []string{EncodingBrotli, EncodingGzip, EncodingDeflate} {
+		entries = nil
+		rw := request("GET", "/test-compress-large", encoding)
+		require.Equal(t, http.StatusCreated, rw.Code)
+		require.Equal(t, encoding, rw.Header().Get("Content-Encoding"))
+		require.Equal(t, "Accept-Encoding", rw.Header().Get("Vary"))
+		require.Empty(t, rw.Header().Get("Content-Length"))
+		require.Less(t, rw.Body.Len(), len(large))
+		require.Equal(t, large, decode(encoding, rw.Body.Bytes()))
+
+		// status and wire size are captured
+		require.Len(t, entries, 1)
+		require.Equal(t, http.StatusCreated, entries[0].Status)
+		require.Equal(t, int64(rw.Body.Len()), entries[0].Bytes)
+	}
+
+	rw := request("GET", "/test-compress-large", "")
+	require.Empty(t, rw.Header().Get("Content-Encoding"))
+	require.Equal(t, "Accept-Encoding", rw.Header().Get("Vary"))
+	require.Equal(t, large, rw.Body.String())
+
+	rw = request("HEAD", "/test-compress-large", "gzip")
+	require.Empty(t, rw.Header().Get("Content-Encoding"))
+
+	rw = request("GET", "/test-compress-small", "gzip")
+	require.Empty(t, rw.Header().Get("Content-Encoding"))
+	require.Equal(t, "small", rw.Body.String())
+
+	rw = request("GET", "/test-compress-binary", "gzip")
+	require.Empty(t, rw.Header().Get("Content-Encoding"))
+	require.Equal(t, large, rw.Body.String())
+
+	rw = request("GET", "/test-compress-range", "gzip")
+	require.Empty(t, rw.Header().Get("Content-Encoding"))
+
+	rw = request("GET", "/test-compress-disabled", "gzip")
+	require.Empty(t, rw.Header().Get("Content-Encoding"))
+	require.Empty(t, rw.Header().Get("Vary"))
+
+	rw = request("GET", "/test-compress-stream", "gzip")
+	require.True(t, rw.Flushed)
+	require.Equal(t, EncodingGzip, rw.Header().Get("Content-Encoding"))
+	require.Equal(t, "chunk-1chunk-2", decode(EncodingGzip, rw.Body.Bytes()))
+
+	rw = request("GET", "/not-found", "gzip")
+	require.Equal(t, http.StatusNotFound, rw.Code)
+}
+
+func TestCompressWriterSniff(t *testing.T) {
+	p := newCompressionPolicy(Compression{MinSize: -1})
+
+	rw, req := httptest.NewRecorder(), httptest.NewRequest("GET", "https://example.com/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	cw := newCompressWriter(rw, req, p)
+	_, _ = cw.Write([]byte("<html><body>hello</body></html>"))
+	cw.close()
+	require.Equal(t, "text/html; charset=utf-8", rw.Header().Get("Content-Type"))
+	require.Equal(t, EncodingGzip, rw.Header().Get("Content-Encoding"))
+
+	// status only
+	rw = httptest.NewRecorder()
+	cw = newCompressWriter(rw, req, p)
+	cw.WriteHeader(http.StatusNoContent)
+	cw.close()
+	require.Equal(t, http.StatusNoContent, rw.Code)
+	require.Empty(t, rw.Header().Get("Content-Encoding"))
+
+	// upgrade requests are not compressed
+	req.Header.Set("Connection", "Upgrade")
+	require.Nil(t, newCompressWriter(httptest.NewRecorder(), req, p))
+}
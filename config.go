@@ -0,0 +1,397 @@
+package summer
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var configTextUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// configField a leaf field of config struct
+type configField struct {
+	// path names of field and its parent structs
+	path  []string
+	env   string
+	flag  string
+	field reflect.StructField
+	value reflect.Value
+}
+
+// configEntry a config struct to load in [New], see [WithConfig]
+type configEntry struct {
+	cfg  any
+	opts []ConfigOption
+}
+
+// configLoader load a config struct, see [LoadConfig]
+type configLoader struct {
+	files     []string
+	envPrefix string
+	flagSet   *flag.FlagSet
+	flagArgs  []string
+	validator Validator
+}
+
+// ConfigOption configuration function for [LoadConfig] and [WithConfig]
+type ConfigOption func(l *configLoader)
+
+// ConfigWithFiles load config files in order, later files override earlier ones, missing files are skipped
+//
+// Files are decoded by extension, ".yaml" or ".yml" as YAML, ".json" as JSON
+func ConfigWithFiles(paths ...string) ConfigOption {
+	return func(l *configLoader) {
+		l.files = append(l.files, paths...)
+	}
+}
+
+// ConfigWithEnvPrefix set prefix of environment variable names, for example "APP_"
+func ConfigWithEnvPrefix(prefix string) ConfigOption {
+	return func(l *configLoader) {
+		l.envPrefix = prefix
+	}
+}
+
+// ConfigWithFlags register a flag for each field in fs, and parse args, for example flag.CommandLine and os.Args[1:]
+//
+// A flag set can be used by a single load only, loading again with the same fs, like calling [New] twice with flag.CommandLine,
+// fails with an error of flag already defined, use a new [flag.FlagSet] for each load instead
+func ConfigWithFlags(fs *flag.FlagSet, args []string) ConfigOption {
+	return func(l *configLoader) {
+		l.flagSet, l.flagArgs = fs, args
+	}
+}
+
+// ConfigWithValidator set [Validator] validating the loaded config
+func ConfigWithValidator(v Validator) ConfigOption {
+	return func(l *configLoader) {
+		l.validator = v
+	}
+}
+
+// LoadConfig populate struct pointed by cfg, in order of precedence from lowest to highest:
+//
+//   - "default" tags
+//   - files set by [ConfigWithFiles]
+//   - environment variables
+//   - flags set by [ConfigWithFlags], only those present in args
+//
+// Each field is named by "config" tag, or snake case of field name, nested structs are named as prefix,
+// embedded structs are flattened, and "-" skips a field. Environment variables are named by "env" tag,
+// or prefix set by [ConfigWithEnvPrefix] followed by upper case of names joined by "_", flags are named by "flag" tag,
+// or names joined by "." with "_" replaced by "-", and "-" disables both. Flag usage is set by "usage" tag.
+//
+// Slices are comma separated in tags, environment variables and flags, types implementing
+// [encoding.TextUnmarshaler] are decoded from text, and other non-basic types are decoded from JSON.
+//
+// Fields with `required:"true"` must not be zero, then the config is validated by [Validator] set by
+// [ConfigWithValidator], and by its own Validate() error method if exists
+func LoadConfig(cfg any, opts ...ConfigOption) (err error) {
+	l := &configLoader{}
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	rv := reflect.ValueOf(cfg)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("summer: config must be a pointer to struct, got %T", cfg)
+	}
+
+	fields := l.collect(rv.Elem(), nil)
+
+	for _, f := range fields {
+		if s, ok := f.field.Tag.Lookup("default"); ok {
+			if err = setConfigString(f.value, s); err != nil {
+				return f.error("default", err)
+			}
+		}
+	}
+
+	for _, file := range l.files {
+		if err = l.loadFile(fields, file); err != nil {
+			return
+		}
+	}
+
+	for _, f := range fields {
+		if f.env == "" {
+			continue
+		}
+		if s, ok := os.LookupEnv(f.env); ok {
+			if err = setConfigString(f.value, s); err != nil {
+				return f.error("env "+f.env, err)
+			}
+		}
+	}
+
+	if l.flagSet != nil {
+		for _, f := range fields {
+			if f.flag == "" {
+				continue
+			}
+			// flag.FlagSet.Var panics on redefinition
+			if l.flagSet.Lookup(f.flag) != nil {
+				return fmt.Errorf("summer: config flag %s already defined in flag set %s", f.flag, l.flagSet.Name())
+			}
+			l.flagSet.Var(&configFlagValue{f: f}, f.flag, f.field.Tag.Get("usage"))
+		}
+		if err = l.flagSet.Parse(l.flagArgs); err != nil {
+			return fmt.Errorf("summer: failed to parse config flags: %w", err)
+		}
+	}
+
+	for _, f := range fields {
+		if f.field.Tag.Get("required") == "true" && f.value.IsZero() {
+			return fmt.Errorf("summer: config %s is required", strings.Join(f.path, "."))
+		}
+	}
+
+	if l.validator != nil {
+		if err = l.validator.Validate(cfg); err != nil {
+			return fmt.Errorf("summer: invalid config: %w", err)
+		}
+	}
+	if v, ok := cfg.(interface{ Validate() error }); ok {
+		if err = v.Validate(); err != nil {
+			return fmt.Errorf("summer: invalid config: %w", err)
+		}
+	}
+	return
+}
+
+// collect returns leaf fields of struct rv, nested structs are walked with names prefixed
+func (l *configLoader) collect(rv reflect.Value, path []string) (fields []configField) {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		name := field.Tag.Get("config")
+		if name == "-" {
+			continue
+		}
+
+		fv := rv.Field(i)
+		// promoted like encoding/json, even if embedded type is unexported
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct && isConfigStruct(field.Type) {
+			fields = append(fields, l.collect(fv, path)...)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if isConfigStruct(field.Type) {
+			if field.Type.Kind() == reflect.Pointer {
+				if fv.IsNil() {
+					fv.Set(reflect.New(field.Type.Elem()))
+				}
+				fv = fv.Elem()
+			}
+			if field.Anonymous && name == "" {
+				fields = append(fields, l.collect(fv, path)...)
+				continue
+			}
+		}
+
+		if name == "" {
+			name = snakeCase(field.Name)
+		}
+		fpath := append(append([]string{}, path...), name)
+
+		if isConfigStruct(field.Type) {
+			fields = append(fields, l.collect(fv, fpath)...)
+			continue
+		}
+
+		f := configField{path: fpath, field: field, value: fv}
+		if env, ok := field.Tag.Lookup("env"); ok {
+			if env != "-" {
+				f.env = env
+			}
+		} else {
+			f.env = l.envPrefix + strings.ToUpper(strings.Join(fpath, "_"))
+		}
+		if fl, ok := field.Tag.Lookup("flag"); ok {
+			if fl != "-" {
+				f.flag = fl
+			}
+		} else {
+			f.flag = strings.ReplaceAll(strings.Join(fpath, "."), "_", "-")
+		}
+		fields = append(fields, f)
+	}
+	return
+}
+
+// loadFile decode file and set fields present in it
+func (l *configLoader) loadFile(fields []configField, file string) (err error) {
+	var buf []byte
+	if buf, err = os.ReadFile(file); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("summer: failed to read config file %s: %w", file, err)
+	}
+
+	var m map[string]any
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(buf, &m)
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(buf))
+		dec.UseNumber()
+		err = dec.Decode(&m)
+	default:
+		return fmt.Errorf("summer: unsupported config file %s", file)
+	}
+	if err != nil {
+		return fmt.Errorf("summer: failed to decode config file %s: %w", file, err)
+	}
+
+	for _, f := range fields {
+		v, ok := lookupConfigValue(m, f.path)
+		if !ok {
+			continue
+		}
+		if err = setConfigAny(f.value, v); err != nil {
+			return f.error("file "+file, err)
+		}
+	}
+	return nil
+}
+
+func (f configField) error(source string, err error) error {
+	return fmt.Errorf("summer: invalid config %s from %s: %w", strings.Join(f.path, "."), source, err)
+}
+
+// configFlagValue [flag.Value] setting a config field
+type configFlagValue struct {
+	f configField
+}
+
+func (v *configFlagValue) String() string {
+	// zero value created by flag.PrintDefaults
+	if v == nil || !v.f.value.IsValid() {
+		return ""
+	}
+	return fmt.Sprint(v.f.value.Interface())
+}
+
+func (v *configFlagValue) Set(s string) error {
+	return setConfigString(v.f.value, s)
+}
+
+func (v *configFlagValue) IsBoolFlag() bool {
+	return v.f.value.IsValid() && v.f.value.Kind() == reflect.Bool
+}
+
+// isConfigStruct returns true if t is a struct or pointer to struct, walked for nested fields
+func isConfigStruct(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && !reflect.PointerTo(t).Implements(configTextUnmarshalerType)
+}
+
+// isConfigBasic returns true if t is supported by [setFieldString]
+func isConfigBasic(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// setConfigString set field from text of tag, environment variable or flag
+func setConfigString(fv reflect.Value, s string) error {
+	if u, ok := fv.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(s))
+	}
+	if isConfigBasic(fv.Type()) {
+		return setFieldString(fv, s)
+	}
+	if fv.Kind() == reflect.Slice && isConfigBasic(fv.Type().Elem()) {
+		var values []string
+		if s != "" {
+			values = strings.Split(s, ",")
+			for i := range values {
+				values[i] = strings.TrimSpace(values[i])
+			}
+		}
+		return setFieldStrings(fv, values)
+	}
+	return json.Unmarshal([]byte(s), fv.Addr().Interface())
+}
+
+// setConfigAny set field from value decoded from file
+func setConfigAny(fv reflect.Value, v any) error {
+	switch v := v.(type) {
+	case nil:
+		fv.Set(reflect.Zero(fv.Type()))
+		return nil
+	case string, bool, int, int64, uint64, float64, json.Number:
+		return setConfigString(fv, fmt.Sprint(v))
+	case time.Time:
+		return setConfigString(fv, v.Format(time.RFC3339Nano))
+	case []any:
+		if fv.Kind() == reflect.Slice && isConfigBasic(fv.Type().Elem()) {
+			values := make([]string, len(v))
+			for i, item := range v {
+				values[i] = fmt.Sprint(item)
+			}
+			return setFieldStrings(fv, values)
+		}
+	}
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(buf, fv.Addr().Interface())
+}
+
+// lookupConfigValue lookup value of path in nested maps
+func lookupConfigValue(m map[string]any, path []string) (v any, ok bool) {
+	for i, name := range path {
+		if v, ok = m[name]; !ok {
+			return
+		}
+		if i == len(path)-1 {
+			return
+		}
+		if m, ok = v.(map[string]any); !ok {
+			return
+		}
+	}
+	return
+}
+
+// snakeCase convert a Go field name to snake case, for example "HTTPPort" to "http_port"
+func snakeCase(s string) string {
+	rs := []rune(s)
+	var sb strings.Builder
+	for i, r := range rs {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(rs[i-1]) || unicode.IsDigit(rs[i-1]) ||
+				(i+1 < len(rs) && unicode.IsLower(rs[i+1]) && unicode.IsUpper(rs[i-1]))) {
+				sb.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
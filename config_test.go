@@ -0,0 +1,153 @@
+package summer
+
+import (
+	"errors"
+	"flag"
+	"github.com/stretchr/testify/require"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testConfigDatabase struct {
+	Host     string `default:"localhost"`
+	Port     int    `default:"5432"`
+	Password string `env:"TEST_DB_PASSWORD" flag:"-"`
+}
+
+type testConfigCommon struct {
+	Debug bool
+}
+
+type testConfig struct {
+	testConfigCommon
+	HTTPPort  int               `default:"8080" usage:"port of http server"`
+	Name      string            `required:"true"`
+	Tags      []string          `default:"a,b"`
+	Timeout   time.Duration     `default:"5s"`
+	StartedAt time.Time         `config:"started_at"`
+	Labels    map[string]string `config:"labels"`
+	Database  testConfigDatabase
+	Cache     *testConfigDatabase `config:"cache"`
+	Ignored   string              `config:"-"`
+}
+
+func (c *testConfig) Validate() error {
+	if c.HTTPPort <= 0 {
+		return errors.New("invalid port")
+	}
+	return nil
+}
+
+func TestSnakeCase(t *testing.T) {
+	require.Equal(t, "http_port", snakeCase("HTTPPort"))
+	require.Equal(t, "database", snakeCase("Database"))
+	require.Equal(t, "user_id", snakeCase("UserID"))
+	require.Equal(t, "oauth2_client", snakeCase("Oauth2Client"))
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	fileYAML := filepath.Join(dir, "config.yaml")
+	fileJSON := filepath.Join(dir, "config.json")
+	require.NoError(t, os.WriteFile(fileYAML, []byte(`
+name: from-yaml
+http_port: 9090
+tags: [x, y]
+started_at: 2024-01-02T03:04:05Z
+labels:
+  a: b
+database:
+  host: db.yaml
+cache:
+  port: 6379
+`), 0644))
+	require.NoError(t, os.WriteFile(fileJSON, []byte(`{"name": "from-json", "database": {"port": 3306}}`), 0644))
+
+	t.Setenv("TEST_DATABASE_HOST", "db.env")
+	t.Setenv("TEST_DEBUG", "true")
+	t.Setenv("TEST_DB_PASSWORD", "secret")
+	t.Setenv("TEST_IGNORED", "ignored")
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	var cfg testConfig
+	require.NoError(t, LoadConfig(&cfg,
+		ConfigWithFiles(fileYAML, filepath.Join(dir, "missing.yaml"), fileJSON),
+		ConfigWithEnvPrefix("TEST_"),
+		ConfigWithFlags(fs, []string{"-database.host", "db.flag", "-timeout=10s", "-tags", "m, n"}),
+	))
+
+	require.True(t, cfg.Debug)
+	require.Equal(t, 9090, cfg.HTTPPort)
+	require.Equal(t, "from-json", cfg.Name)
+	require.Equal(t, []string{"m", "n"}, cfg.Tags)
+	require.Equal(t, time.Second*10, cfg.Timeout)
+	require.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), cfg.StartedAt.UTC())
+	require.Equal(t, map[string]string{"a": "b"}, cfg.Labels)
+	require.Equal(t, testConfigDatabase{Host: "db.flag", Port: 3306, Password: "secret"}, cfg.Database)
+	require.Equal(t, &testConfigDatabase{Host: "localhost", Port: 6379, Password: "secret"}, cfg.Cache)
+	require.Empty(t, cfg.Ignored)
+
+	require.Equal(t, "port of http server", fs.Lookup("http-port").Usage)
+	require.Nil(t, fs.Lookup("database.password"))
+	require.Nil(t, fs.Lookup("ignored"))
+
+	// flag not present in args does not override env
+	t.Setenv("TEST_HTTP_PORT", "7070")
+	cfg = testConfig{}
+	require.NoError(t, LoadConfig(&cfg,
+		ConfigWithEnvPrefix("TEST_"),
+		ConfigWithFlags(flag.NewFlagSet("test", flag.ContinueOnError), []string{"-name", "from-flag"}),
+	))
+	require.Equal(t, 7070, cfg.HTTPPort)
+	require.Equal(t, "from-flag", cfg.Name)
+
+	// flag set used twice
+	cfg = testConfig{}
+	require.EqualError(t, LoadConfig(&cfg, ConfigWithFlags(fs, nil)), "summer: config flag debug already defined in flag set test")
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	var cfg testConfig
+	require.EqualError(t, LoadConfig(cfg), "summer: config must be a pointer to struct, got summer.testConfig")
+	require.EqualError(t, LoadConfig(&cfg), "summer: config name is required")
+
+	t.Setenv("NAME", "test")
+	t.Setenv("HTTP_PORT", "invalid")
+	require.ErrorContains(t, LoadConfig(&cfg), "summer: invalid config http_port from env HTTP_PORT: ")
+
+	t.Setenv("HTTP_PORT", "-1")
+	require.EqualError(t, LoadConfig(&cfg), "summer: invalid config: invalid port")
+
+	t.Setenv("HTTP_PORT", "80")
+	require.EqualError(t, LoadConfig(&cfg, ConfigWithValidator(ValidatorFunc(func(data any) error {
+		require.Equal(t, &cfg, data)
+		return errors.New("rejected")
+	}))), "summer: invalid config: rejected")
+
+	file := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, os.WriteFile(file, []byte(""), 0644))
+	require.EqualError(t, LoadConfig(&cfg, ConfigWithFiles(file)), "summer: unsupported config file "+file)
+}
+
+func TestWithConfig(t *testing.T) {
+	t.Setenv("APP_NAME", "test")
+
+	var cfg testConfig
+	Basic(WithConfig(&cfg, ConfigWithEnvPrefix("APP_")))
+	require.Equal(t, "test", cfg.Name)
+	require.Equal(t, 8080, cfg.HTTPPort)
+
+	require.PanicsWithError(t, "summer: invalid config: rejected", func() {
+		Basic(
+			WithValidator(ValidatorFunc(func(data any) error {
+				return errors.New("rejected")
+			})),
+			WithConfig(&testConfig{}, ConfigWithEnvPrefix("APP_")),
+		)
+	})
+}
@@ -4,6 +4,14 @@ const (
 	ContentTypeApplicationJSON = "application/json"
 	ContentTypeTextPlain       = "text/plain"
 	ContentTypeFormURLEncoded  = "application/x-www-form-urlencoded"
+	ContentTypeMultipartForm   = "multipart/form-data"
+	ContentTypeMsgpack         = "application/msgpack"
+	ContentTypeXMsgpack        = "application/x-msgpack"
+	ContentTypeTextCSV         = "text/csv"
+	ContentTypeApplicationXML  = "application/xml"
+	ContentTypeTextXML         = "text/xml"
+	ContentTypeTextEventStream = "text/event-stream"
+	ContentTypeApplicationGRPC = "application/grpc"
 
 	ContentTypeApplicationJSONUTF8 = "application/json; charset=utf-8"
 	ContentTypeTextPlainUTF8       = "text/plain; charset=utf-8"
@@ -11,5 +19,17 @@ const (
 
 	DefaultReadinessPath = "/debug/ready"
 	DefaultLivenessPath  = "/debug/alive"
+	DefaultStartupPath   = "/debug/startup"
 	DefaultMetricsPath   = "/debug/metrics"
+
+	OptionsPath = "/debug/options"
+	InfoPath    = "/debug/info"
+	BuildPath   = "/debug/build"
+
+	OpenAPIPath   = "/debug/openapi.json"
+	SwaggerUIPath = "/debug/swagger"
+
+	DefaultVersionPath = "/version"
+
+	DefaultAPIKeyHeader = "X-API-Key"
 )
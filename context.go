@@ -1,12 +1,28 @@
 package summer
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"crypto/x509"
 	"encoding/json"
+	"encoding/xml"
+	"errors"
 	"fmt"
 	"github.com/guoyk93/rg"
+	"github.com/vmihailenco/msgpack/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"io"
+	"mime/multipart"
+	"net"
 	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
@@ -33,25 +49,100 @@ func Bind[T any](c Context) (o T) {
 // Context the most basic context of a incoming request and corresponding response
 type Context interface {
 	// Context extend the [context.Context] interface by proxying to [http.Request.Context]
+	//
+	// Deadline returns the deadline set by [WithRequestTimeout] or [WithRequestTimeoutHeader], or of the underlying request context
 	context.Context
 
 	// Inject inject underlying [context.Context]
 	Inject(fn func(ctx context.Context) context.Context)
 
+	// TraceID returns trace id of current span, empty if tracing is not set up
+	TraceID() string
+
+	// RequestID returns id of current request, from inbound header set by [WithRequestIDHeader] or generated
+	RequestID() string
+
+	// ClientCert returns the verified certificate of client, nil if not served with TLS or client certificate not verified, see [WithTLSClientCA]
+	ClientCert() *x509.Certificate
+
+	// Principal returns the identity authenticated by [Authenticate] or [AuthenticateOptional], nil if not authenticated
+	Principal() *Principal
+
+	// Cookie returns the named cookie of request, [http.ErrNoCookie] if not found
+	Cookie(name string) (*http.Cookie, error)
+
+	// SetCookie add a "Set-Cookie" header to response, invalid cookies are silently dropped
+	SetCookie(cookie *http.Cookie)
+
+	// Session returns the [Session] of current request, loaded on first call, changes are saved before response sent
+	//
+	// Panics if sessions are not enabled by [WithSessions], or the [SessionStore] fails
+	Session() *Session
+
 	// Req returns the underlying *http.Request
 	Req() *http.Request
-	// Res returns the underlying http.ResponseWriter
+	// Res returns the underlying http.ResponseWriter, implementing [ResponseWriter] when served by [App]
 	Res() http.ResponseWriter
 
+	// Set store a per-request value with key, independent of the underlying [context.Context]
+	//
+	// The store is NOT safe for concurrent access across goroutines spawned from the handler
+	Set(key string, value any)
+
+	// Get retrieve a per-request value stored with [Context.Set]
+	Get(key string) (value any, ok bool)
+
+	// GetString retrieve a per-request string value stored with [Context.Set], returns empty string if missing or not a string
+	GetString(key string) string
+
+	// PathParam returns value of path parameter with name, matched by pattern like "/users/{id}", see [http.Request.PathValue]
+	PathParam(name string) string
+
+	// QueryMap returns all query parameters, with first value only
+	QueryMap() map[string]string
+
+	// QueryAllMap returns all query parameters, with all values
+	QueryAllMap() map[string][]string
+
+	// RawBody returns the raw request body, buffered on first call
+	//
+	// The request body is reset after buffering, [Context.Bind] still works
+	//
+	// Limit set by [WithMaxBodyBytes] applies
+	RawBody() ([]byte, error)
+
+	// BodyBytes returns the raw request body like [Context.RawBody], limited to maxBytes
+	//
+	// If maxBytes <= 0, limit set by [WithMaxBodyBytes] applies, which also caps a larger maxBytes. [ErrBodyTooLarge] is returned if the limit is hit
+	BodyBytes(maxBytes int64) ([]byte, error)
+
 	// Bind unmarshal the request data into any struct with json tags
 	//
 	// HTTP header is prefixed with "header_"
 	//
 	// HTTP query is prefixed with "query_"
 	//
+	// prefixes and key collision policy are configurable by [WithBindPrefixes] and [WithBindCollisionPolicy]
+	//
 	// both JSON and Form are supported
+	//
+	// Fields with "path", "query" or "header" tags are set directly from path parameters, query and header, taking precedence over json tags.
+	// Slice fields take all values, other fields take the first one
+	//
+	// Bound data is validated by [Validator] set by [WithValidator]
+	//
+	// Errors cause a panic, usually resulting in 400 or 500 by [Context.Perform]
 	Bind(data interface{})
 
+	// ShouldBind like [Context.Bind], but returns the error instead of a panic
+	ShouldBind(data interface{}) error
+
+	// FormFile returns the first uploaded file of field name in "multipart/form-data" body, [http.ErrMissingFile] if not found
+	//
+	// Non-file fields are extracted for [Context.Bind]. Files are kept in memory up to [WithMultipartMemory], or stored in temporary files
+	// removed after response performed. Parsing consumes the request body, [Context.RawBody] returns nothing afterwards
+	FormFile(name string) (*multipart.FileHeader, error)
+
 	// Code set the response code, can be called multiple times
 	Code(code int)
 
@@ -64,6 +155,46 @@ type Context interface {
 	// JSON set the response body to json
 	JSON(data interface{})
 
+	// RedirectRelative redirect to path on the same scheme and host of current request
+	//
+	// "X-Forwarded-Proto" and "X-Forwarded-Host" are honored only if request comes from proxies set by [WithTrustedProxies]
+	RedirectRelative(path string, code int)
+
+	// SendFile stream a file from OS filesystem as response, bypassing the buffered response body
+	//
+	// Missing file results in 404, other errors result in 500, with generic text, and the error is returned.
+	// path is opened as is, cleaning does not remove leading "..", so caller must confine path built from request to a root,
+	// for example with [filepath.IsLocal], or serve files of a directory with [App.Static] or [App.StaticFS] instead
+	SendFile(path string) error
+
+	// RespondMsgpack set the response body to msgpack
+	RespondMsgpack(data interface{}) error
+
+	// Render set the response code, and the response body encoded as JSON, msgpack or XML, negotiated by "Accept" header
+	//
+	// JSON is used if "Accept" header is missing or accepts none of them, or data can not be encoded as XML, like maps.
+	// The negotiated format is recorded as attribute "summer.response.format" of current span
+	Render(code int, data interface{})
+
+	// Hijack take over the underlying connection, for protocols like WebSocket, see [App.HandleFuncUpgrade]
+	//
+	// After a successful hijack, [Context.Perform] writes nothing, panics are still recovered
+	Hijack() (conn net.Conn, brw *bufio.ReadWriter, err error)
+
+	// SSE start a Server-Sent Events stream, headers are sent immediately, bypassing the buffered response body
+	//
+	// Heartbeat comments are sent at interval set by [WithSSEHeartbeat]. [Context.Perform] closes the stream when handler returns, and writes nothing afterwards
+	SSE() (*SSE, error)
+
+	// Forward dispatch current request to handler of route registered with pattern, without an additional round trip
+	//
+	// The forwarded request has the same headers and body, path of pattern, and a fresh context derived from current one.
+	// Response is written by the forwarded handler, [Context.Perform] writes nothing after a successful forward
+	Forward(pattern string) error
+
+	// Timer returns the [Timer] of current request, completed phases are sent as "Server-Timing" header
+	Timer() *Timer
+
 	// Perform actually perform the response
 	// it is suggested to use in defer, recover() is included to recover from any panics
 	Perform()
@@ -73,11 +204,28 @@ type basicContext struct {
 	req *http.Request
 	rw  http.ResponseWriter
 
-	buf []byte
+	buf     []byte
+	recvErr error
+
+	raw    []byte
+	rawErr error
+
+	formErr error
 
 	code int
 	body []byte
 
+	values map[string]any
+
+	session *Session
+
+	timer *Timer
+
+	// sse stream started by SSE, closed by Perform
+	sse *SSE
+
+	rawOnce  *sync.Once
+	formOnce *sync.Once
 	recvOnce *sync.Once
 	sendOnce *sync.Once
 }
@@ -98,6 +246,61 @@ func (c *basicContext) Value(key any) any {
 	return c.req.Context().Value(key)
 }
 
+func (c *basicContext) TraceID() string {
+	return traceIDFromContext(c.req.Context())
+}
+
+func (c *basicContext) RequestID() string {
+	return requestIDFromContext(c.req.Context())
+}
+
+func (c *basicContext) ClientCert() *x509.Certificate {
+	if c.req.TLS == nil || len(c.req.TLS.VerifiedChains) == 0 || len(c.req.TLS.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	return c.req.TLS.VerifiedChains[0][0]
+}
+
+func (c *basicContext) Principal() *Principal {
+	return principalFromContext(c.req.Context())
+}
+
+func (c *basicContext) Cookie(name string) (*http.Cookie, error) {
+	return c.req.Cookie(name)
+}
+
+func (c *basicContext) SetCookie(cookie *http.Cookie) {
+	http.SetCookie(c.rw, cookie)
+}
+
+func (c *basicContext) Session() *Session {
+	if c.session == nil {
+		m := optionsFromContext(c.req.Context()).sessions
+		if m == nil {
+			panic(errors.New("summer: sessions not enabled, see summer.WithSessions"))
+		}
+		s, err := m.load(c.req)
+		if err != nil {
+			panic(NewError(http.StatusInternalServerError, "SESSION UNAVAILABLE").WithCause(err))
+		}
+		c.session = s
+	}
+	return c.session
+}
+
+// saveSession save loaded session, failure results in 500
+func (c *basicContext) saveSession() {
+	if c.session == nil {
+		return
+	}
+	if err := c.session.manager.save(c.req.Context(), c.rw, c.session); err != nil {
+		optionsFromContext(c.req.Context()).logger.Error("failed to save session", "error", err.Error())
+		e := NewError(http.StatusInternalServerError, "SESSION UNAVAILABLE").WithCause(err)
+		c.Code(StatusCodeFromError(e))
+		c.JSON(BodyFromError(e))
+	}
+}
+
 func (c *basicContext) Inject(fn func(ctx context.Context) context.Context) {
 	ctx := c.req.Context()
 	neo := fn(ctx)
@@ -114,22 +317,207 @@ func (c *basicContext) Res() http.ResponseWriter {
 	return c.rw
 }
 
+func (c *basicContext) Set(key string, value any) {
+	if c.values == nil {
+		c.values = map[string]any{}
+	}
+	c.values[key] = value
+}
+
+func (c *basicContext) Get(key string) (value any, ok bool) {
+	value, ok = c.values[key]
+	return
+}
+
+func (c *basicContext) GetString(key string) string {
+	s, _ := c.values[key].(string)
+	return s
+}
+
+func (c *basicContext) PathParam(name string) string {
+	return c.req.PathValue(name)
+}
+
+func (c *basicContext) QueryMap() map[string]string {
+	m := map[string]string{}
+	for k, vs := range c.req.URL.Query() {
+		if len(vs) > 0 {
+			m[k] = vs[0]
+		}
+	}
+	return m
+}
+
+func (c *basicContext) QueryAllMap() map[string][]string {
+	return c.req.URL.Query()
+}
+
+func (c *basicContext) readRawBody(limit int64) {
+	if c.req.Body == nil {
+		return
+	}
+	r := io.Reader(c.req.Body)
+	if limit > 0 {
+		r = io.LimitReader(r, limit+1)
+	}
+	c.raw, c.rawErr = io.ReadAll(r)
+	_ = c.req.Body.Close()
+	var mbe *http.MaxBytesError
+	if errors.As(c.rawErr, &mbe) || (c.rawErr == nil && limit > 0 && int64(len(c.raw)) > limit) {
+		c.raw, c.rawErr = nil, ErrBodyTooLarge
+	}
+	c.req.Body = io.NopCloser(bytes.NewReader(c.raw))
+}
+
+func (c *basicContext) RawBody() ([]byte, error) {
+	return c.BodyBytes(0)
+}
+
+func (c *basicContext) BodyBytes(maxBytes int64) ([]byte, error) {
+	if maxBytes <= 0 {
+		maxBytes = optionsFromContext(c.req.Context()).maxBodyBytes
+	}
+	c.rawOnce.Do(func() {
+		c.readRawBody(maxBytes)
+	})
+	if c.rawErr == nil && maxBytes > 0 && int64(len(c.raw)) > maxBytes {
+		return nil, ErrBodyTooLarge
+	}
+	return c.raw, c.rawErr
+}
+
+func (c *basicContext) parseForm() error {
+	c.formOnce.Do(func() {
+		c.formErr = parseMultipartForm(c.req)
+	})
+	return c.formErr
+}
+
+func (c *basicContext) FormFile(name string) (*multipart.FileHeader, error) {
+	if err := c.parseForm(); err != nil {
+		return nil, err
+	}
+	if fhs := c.req.MultipartForm.File[name]; len(fhs) > 0 {
+		return fhs[0], nil
+	}
+	return nil, http.ErrMissingFile
+}
+
 func (c *basicContext) receive() {
+	if isMultipartForm(c.req) {
+		if err := c.parseForm(); err != nil {
+			c.recvErr = err
+			return
+		}
+	} else if _, err := c.RawBody(); err != nil {
+		if errors.Is(err, ErrBodyTooLarge) {
+			c.recvErr = err
+		} else {
+			c.recvErr = NewHaltError(err, HaltWithStatusCode(http.StatusBadRequest))
+		}
+		return
+	}
 	var m = map[string]any{}
 	if err := extractRequest(m, c.req); err != nil {
-		Halt(err, HaltWithStatusCode(http.StatusBadRequest))
+		// errors with status code, like [ErrBodyTooLarge], are kept
+		if _, ok := err.(withStatusCode); ok {
+			c.recvErr = err
+		} else {
+			c.recvErr = NewHaltError(err, HaltWithStatusCode(http.StatusBadRequest))
+		}
+		return
+	}
+	c.buf, c.recvErr = json.Marshal(m)
+}
+
+func (c *basicContext) Hijack() (conn net.Conn, brw *bufio.ReadWriter, err error) {
+	if conn, brw, err = http.NewResponseController(c.rw).Hijack(); err != nil {
+		return
+	}
+	// buffered response is never sent after hijacking
+	c.sendOnce.Do(func() {})
+	return
+}
+
+func (c *basicContext) SSE() (s *SSE, err error) {
+	// buffered response is never sent after streaming
+	c.sendOnce.Do(func() {})
+	s, err = newSSE(c.req.Context(), c.rw, optionsFromContext(c.req.Context()).sseHeartbeat)
+	// heartbeat never outlives the handler
+	c.sse = s
+	return
+}
+
+func (c *basicContext) Forward(pattern string) (err error) {
+	f, ok := c.req.Context().Value(contextKeyForwarder{}).(forwarder)
+	if !ok {
+		return errors.New("summer: forward is only available in handlers registered to App")
+	}
+
+	var buf []byte
+	if buf, err = c.RawBody(); err != nil {
+		return
+	}
+
+	req := c.req.Clone(c.req.Context())
+	req.Body = io.NopCloser(bytes.NewReader(buf))
+	req.ContentLength = int64(len(buf))
+
+	if err = f.forward(c.rw, req, pattern); err != nil {
+		return
+	}
+
+	// response is written by the forwarded handler
+	c.sendOnce.Do(func() {})
+	return
+}
+
+func (c *basicContext) Timer() *Timer {
+	return c.timer
+}
+
+// writeServerTiming set "Server-Timing" header from completed phases of [Timer]
+func (c *basicContext) writeServerTiming() {
+	if s := c.timer.String(); s != "" {
+		c.rw.Header().Set("Server-Timing", s)
 	}
-	c.buf = rg.Must(json.Marshal(m))
 }
 
 func (c *basicContext) send() {
+	// response already written directly, possibly partially, nothing more can be sent
+	if w, ok := c.rw.(ResponseWriter); ok && w.Written() {
+		return
+	}
+	c.saveSession()
+	c.writeServerTiming()
 	c.rw.WriteHeader(c.code)
 	_, _ = c.rw.Write(c.body)
 }
 
-func (c *basicContext) Bind(data interface{}) {
+func (c *basicContext) ShouldBind(data interface{}) (err error) {
 	c.recvOnce.Do(c.receive)
-	rg.Must0(json.Unmarshal(c.buf, data))
+	if c.recvErr != nil {
+		return c.recvErr
+	}
+	// values of tagged fields are bound directly, excluded from unmarshalling
+	if err = unmarshalExcluding(c.buf, data, taggedJSONNames(data)); err != nil {
+		return
+	}
+	if err = bindTagged(c.req, data); err != nil {
+		return
+	}
+	if v := optionsFromContext(c.req.Context()).validator; v != nil {
+		return validate(v, data)
+	}
+	return
+}
+
+func (c *basicContext) Bind(data interface{}) {
+	rg.Must0(c.ShouldBind(data))
+}
+
+func (c *basicContext) responded() bool {
+	return c.code != http.StatusOK || c.body != nil
 }
 
 func (c *basicContext) Code(code int) {
@@ -152,6 +540,115 @@ func (c *basicContext) JSON(data interface{}) {
 	c.Body(ContentTypeApplicationJSONUTF8, buf)
 }
 
+func (c *basicContext) RedirectRelative(path string, code int) {
+	ref, err := url.Parse(path)
+	if err != nil {
+		Halt(err, HaltWithBadRequest())
+	}
+
+	u := &url.URL{
+		Scheme:   "http",
+		Host:     c.req.Host,
+		Path:     ref.Path,
+		RawQuery: ref.RawQuery,
+		Fragment: ref.Fragment,
+	}
+	if c.req.TLS != nil {
+		u.Scheme = "https"
+	}
+	if !strings.HasPrefix(u.Path, "/") {
+		u.Path = "/" + u.Path
+	}
+
+	if optionsFromContext(c.req.Context()).isTrustedProxy(c.req) {
+		if proto := c.req.Header.Get("X-Forwarded-Proto"); proto == "http" || proto == "https" {
+			u.Scheme = proto
+		}
+		if host := c.req.Header.Get("X-Forwarded-Host"); host != "" {
+			u.Host = host
+		}
+	}
+
+	c.rw.Header().Set("Location", u.String())
+	c.Code(code)
+}
+
+func (c *basicContext) SendFile(path string) (err error) {
+	var f *os.File
+	if f, err = os.Open(filepath.Clean(path)); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			c.Code(http.StatusNotFound)
+			c.Text("NOT FOUND")
+		} else {
+			c.Code(http.StatusInternalServerError)
+			c.Text("INTERNAL SERVER ERROR")
+		}
+		return
+	}
+	defer f.Close()
+
+	var info os.FileInfo
+	if info, err = f.Stat(); err != nil {
+		c.Code(http.StatusInternalServerError)
+		c.Text("INTERNAL SERVER ERROR")
+		return
+	}
+	if info.IsDir() {
+		err = errors.New("is a directory: " + path)
+		c.Code(http.StatusNotFound)
+		c.Text("NOT FOUND")
+		return
+	}
+
+	c.sendOnce.Do(func() {
+		c.writeServerTiming()
+		http.ServeContent(c.rw, c.req, info.Name(), info.ModTime(), f)
+	})
+	return
+}
+
+func (c *basicContext) RespondMsgpack(data interface{}) (err error) {
+	var buf []byte
+	if buf, err = msgpack.Marshal(data); err != nil {
+		return
+	}
+	c.Body(ContentTypeMsgpack, buf)
+	return
+}
+
+func (c *basicContext) Render(code int, data interface{}) {
+	contentType := negotiate(c.req.Header.Get("Accept"), []string{
+		ContentTypeApplicationJSON,
+		ContentTypeMsgpack,
+		ContentTypeXMsgpack,
+		ContentTypeApplicationXML,
+		ContentTypeTextXML,
+	})
+
+	var format string
+	switch contentType {
+	case ContentTypeMsgpack, ContentTypeXMsgpack:
+		format = "msgpack"
+		c.Body(contentType, rg.Must(msgpack.Marshal(data)))
+	case ContentTypeApplicationXML, ContentTypeTextXML:
+		// data like maps can not be encoded as XML, fallback to JSON
+		if buf, err := xml.Marshal(data); err == nil {
+			format = "xml"
+			c.Body(contentType+"; charset=utf-8", append([]byte(xml.Header), buf...))
+			break
+		}
+		fallthrough
+	default:
+		format = "json"
+		c.JSON(data)
+	}
+
+	c.rw.Header().Add("Vary", "Accept")
+	c.Code(code)
+
+	trace.SpanFromContext(c.req.Context()).SetAttributes(attribute.String("summer.response.format", format))
+}
+
 func (c *basicContext) Perform() {
 	if r := recover(); r != nil {
 		var (
@@ -161,12 +658,46 @@ func (c *basicContext) Perform() {
 		if e, ok = r.(error); !ok {
 			e = fmt.Errorf("panic: %v", r)
 		}
-		c.Code(StatusCodeFromError(e))
-		c.JSON(BodyFromError(e))
+		respondError(c, e)
+	}
+	if c.sse != nil {
+		c.sse.Close()
 	}
 	c.sendOnce.Do(c.send)
 }
 
+// respondError set response code and body from err, and record err to current span
+func respondError(c Context, err error) {
+	code := StatusCodeFromError(err)
+	c.Code(code)
+	c.JSON(BodyFromError(err))
+	recordError(c.Req().Context(), err, code)
+}
+
+// traceIDFromContext returns trace id of span in ctx, empty if not valid
+func traceIDFromContext(ctx context.Context) string {
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		return sc.TraceID().String()
+	}
+	return ""
+}
+
+// recordError record the error, or cause of [Error], to current span, status of span is set for 5xx
+func recordError(ctx context.Context, err error, code int) {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+	var se *Error
+	if errors.As(err, &se) && se.Cause != nil {
+		err = se.Cause
+	}
+	span.RecordError(err)
+	if code >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
+
 // ContextFactory factory function for creating an extended [Context]
 type ContextFactory[T Context] func(rw http.ResponseWriter, req *http.Request) T
 
@@ -176,6 +707,9 @@ func BasicContext(rw http.ResponseWriter, req *http.Request) Context {
 		req:      req,
 		rw:       rw,
 		code:     http.StatusOK,
+		timer:    &Timer{},
+		rawOnce:  &sync.Once{},
+		formOnce: &sync.Once{},
 		recvOnce: &sync.Once{},
 		sendOnce: &sync.Once{},
 	}
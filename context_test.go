@@ -1,9 +1,17 @@
 package summer
 
 import (
+	"bytes"
+	"errors"
 	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v5"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -85,3 +93,256 @@ func TestContextPanic(t *testing.T) {
 	require.Equal(t, "application/json; charset=utf-8", rw.Header().Get("Content-Type"))
 	require.Equal(t, `{"message":"panic: WWW"}`, rw.Body.String())
 }
+
+type testRecordingSpan struct {
+	trace.Span
+	errs       []error
+	statusCode codes.Code
+}
+
+func (s *testRecordingSpan) IsRecording() bool {
+	return true
+}
+
+func (s *testRecordingSpan) RecordError(err error, options ...trace.EventOption) {
+	s.errs = append(s.errs, err)
+}
+
+func (s *testRecordingSpan) SetStatus(code codes.Code, description string) {
+	s.statusCode = code
+}
+
+func TestContextPerformError(t *testing.T) {
+	cause := errors.New("connection refused")
+	span := &testRecordingSpan{}
+
+	req := httptest.NewRequest("GET", "https://example.com/get", nil)
+	req = req.WithContext(trace.ContextWithSpan(req.Context(), span))
+	rw := httptest.NewRecorder()
+	ctx := BasicContext(rw, req)
+
+	func() {
+		defer ctx.Perform()
+		panic(NewError(http.StatusBadGateway, "upstream failed").WithCause(cause).WithDetail("retry", true))
+	}()
+
+	require.Equal(t, http.StatusBadGateway, rw.Code)
+	require.Equal(t, `{"details":{"retry":true},"message":"upstream failed"}`, rw.Body.String())
+	require.Equal(t, []error{cause}, span.errs)
+	require.Equal(t, codes.Error, span.statusCode)
+}
+
+func TestContextValues(t *testing.T) {
+	req := httptest.NewRequest("GET", "https://example.com/get", nil)
+	rw := httptest.NewRecorder()
+	ctx := BasicContext(rw, req)
+
+	_, ok := ctx.Get("user")
+	require.False(t, ok)
+	require.Equal(t, "", ctx.GetString("user"))
+
+	ctx.Set("user", "alice")
+	ctx.Set("tenant", 1)
+
+	v, ok := ctx.Get("user")
+	require.True(t, ok)
+	require.Equal(t, "alice", v)
+	require.Equal(t, "alice", ctx.GetString("user"))
+	require.Equal(t, "", ctx.GetString("tenant"))
+}
+
+func TestContextRespondMsgpack(t *testing.T) {
+	req := httptest.NewRequest("GET", "https://example.com/get", nil)
+	rw := httptest.NewRecorder()
+	ctx := BasicContext(rw, req)
+
+	func() {
+		defer ctx.Perform()
+		require.NoError(t, ctx.RespondMsgpack(map[string]any{"hello": "world"}))
+	}()
+
+	var m map[string]any
+	require.NoError(t, msgpack.Unmarshal(rw.Body.Bytes(), &m))
+	require.Equal(t, "application/msgpack", rw.Header().Get("Content-Type"))
+	require.Equal(t, map[string]any{"hello": "world"}, m)
+}
+
+func TestContextRender(t *testing.T) {
+	type item struct {
+		Name string `json:"name" xml:"name" msgpack:"name"`
+	}
+
+	render := func(accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "https://example.com/get", nil)
+		req.Header.Set("Accept", accept)
+		rw := httptest.NewRecorder()
+		ctx := BasicContext(rw, req)
+		func() {
+			defer ctx.Perform()
+			ctx.Render(http.StatusCreated, item{Name: "hello"})
+		}()
+		return rw
+	}
+
+	rw := render("")
+	require.Equal(t, http.StatusCreated, rw.Code)
+	require.Equal(t, ContentTypeApplicationJSONUTF8, rw.Header().Get("Content-Type"))
+	require.Equal(t, "Accept", rw.Header().Get("Vary"))
+	require.Equal(t, `{"name":"hello"}`, rw.Body.String())
+
+	rw = render("application/x-msgpack")
+	require.Equal(t, http.StatusCreated, rw.Code)
+	require.Equal(t, ContentTypeXMsgpack, rw.Header().Get("Content-Type"))
+	var m map[string]any
+	require.NoError(t, msgpack.Unmarshal(rw.Body.Bytes(), &m))
+	require.Equal(t, map[string]any{"name": "hello"}, m)
+
+	rw = render("text/xml, application/json;q=0.1")
+	require.Equal(t, http.StatusCreated, rw.Code)
+	require.Equal(t, "text/xml; charset=utf-8", rw.Header().Get("Content-Type"))
+	require.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>`+"\n"+`<item><name>hello</name></item>`, rw.Body.String())
+
+	// not encodable as XML
+	req := httptest.NewRequest("GET", "https://example.com/get", nil)
+	req.Header.Set("Accept", "application/xml")
+	rw = httptest.NewRecorder()
+	ctx := BasicContext(rw, req)
+	func() {
+		defer ctx.Perform()
+		ctx.Render(http.StatusOK, map[string]any{"name": "hello"})
+	}()
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Equal(t, ContentTypeApplicationJSONUTF8, rw.Header().Get("Content-Type"))
+	require.Equal(t, `{"name":"hello"}`, rw.Body.String())
+}
+
+func TestContextQueryMap(t *testing.T) {
+	req := httptest.NewRequest("GET", "https://example.com/get?aaa=bbb&ccc=ddd&ccc=eee", nil)
+	rw := httptest.NewRecorder()
+	ctx := BasicContext(rw, req)
+
+	require.Equal(t, map[string]string{"aaa": "bbb", "ccc": "ddd"}, ctx.QueryMap())
+	require.Equal(t, map[string][]string{"aaa": {"bbb"}, "ccc": {"ddd", "eee"}}, ctx.QueryAllMap())
+}
+
+func TestContextSendFile(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "hello.txt"), []byte("hello"), 0644))
+
+	req := httptest.NewRequest("GET", "https://example.com/get", nil)
+	rw := httptest.NewRecorder()
+	ctx := BasicContext(rw, req)
+
+	func() {
+		defer ctx.Perform()
+		require.NoError(t, ctx.SendFile(filepath.Join(dir, "hello.txt")))
+	}()
+
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Equal(t, "hello", rw.Body.String())
+	require.Equal(t, "text/plain; charset=utf-8", rw.Header().Get("Content-Type"))
+
+	req = httptest.NewRequest("GET", "https://example.com/get", nil)
+	rw = httptest.NewRecorder()
+	ctx = BasicContext(rw, req)
+
+	func() {
+		defer ctx.Perform()
+		require.ErrorIs(t, ctx.SendFile(filepath.Join(dir, "missing.txt")), os.ErrNotExist)
+	}()
+
+	require.Equal(t, http.StatusNotFound, rw.Code)
+	// os error is not exposed
+	require.Equal(t, "NOT FOUND", rw.Body.String())
+
+	req = httptest.NewRequest("GET", "https://example.com/get", nil)
+	rw = httptest.NewRecorder()
+	ctx = BasicContext(rw, req)
+
+	func() {
+		defer ctx.Perform()
+		require.Error(t, ctx.SendFile(dir))
+	}()
+
+	require.Equal(t, http.StatusNotFound, rw.Code)
+	require.Equal(t, "NOT FOUND", rw.Body.String())
+}
+
+func TestContextRawBody(t *testing.T) {
+	req := httptest.NewRequest("POST", "https://example.com/post", bytes.NewReader([]byte(`{"hello":"world"}`)))
+	req.Header.Set("Content-Type", "application/json")
+	rw := httptest.NewRecorder()
+	ctx := BasicContext(rw, req)
+
+	buf, err := ctx.RawBody()
+	require.NoError(t, err)
+	require.Equal(t, `{"hello":"world"}`, string(buf))
+
+	args := Bind[struct {
+		Hello string `json:"hello"`
+	}](ctx)
+	require.Equal(t, "world", args.Hello)
+
+	buf, err = ctx.RawBody()
+	require.NoError(t, err)
+	require.Equal(t, `{"hello":"world"}`, string(buf))
+}
+
+func TestContextBodyBytes(t *testing.T) {
+	ctx := BasicContext(httptest.NewRecorder(), httptest.NewRequest("POST", "https://example.com/post", strings.NewReader("hello")))
+
+	buf, err := ctx.BodyBytes(5)
+	require.NoError(t, err)
+	require.Equal(t, "hello", string(buf))
+
+	buf, err = ctx.BodyBytes(5)
+	require.NoError(t, err)
+	require.Equal(t, "hello", string(buf))
+
+	_, err = ctx.BodyBytes(4)
+	require.ErrorIs(t, err, ErrBodyTooLarge)
+
+	ctx = BasicContext(httptest.NewRecorder(), httptest.NewRequest("POST", "https://example.com/post", strings.NewReader("hello")))
+	_, err = ctx.BodyBytes(4)
+	require.ErrorIs(t, err, ErrBodyTooLarge)
+
+	a := Basic(WithMaxBodyBytes(4))
+	a.HandleFunc("/post", func(ctx Context) {
+		_, err := ctx.BodyBytes(0)
+		require.ErrorIs(t, err, ErrBodyTooLarge)
+		ctx.Bind(&map[string]any{})
+	})
+	res := a.TestRequest("POST", "/post", strings.NewReader("hello"))
+	require.Equal(t, http.StatusRequestEntityTooLarge, res.Code)
+}
+
+func TestContextRedirectRelative(t *testing.T) {
+	a := Basic(WithTrustedProxies([]string{"10.0.0.0/8"}))
+	a.HandleFunc("/old", func(ctx Context) {
+		ctx.RedirectRelative("//evil.com/new?a=b", http.StatusMovedPermanently)
+	})
+
+	req := httptest.NewRequest("GET", "https://example.com/old", nil)
+	req.Header.Set("X-Forwarded-Host", "evil.com")
+	rw := httptest.NewRecorder()
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusMovedPermanently, rw.Code)
+	require.Equal(t, "https://example.com/new?a=b", rw.Header().Get("Location"))
+
+	req = httptest.NewRequest("GET", "http://internal/old", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	req.Header.Set("X-Forwarded-Proto", "https")
+	req.Header.Set("X-Forwarded-Host", "example.com")
+	rw = httptest.NewRecorder()
+	a.ServeHTTP(rw, req)
+	require.Equal(t, "https://example.com/new?a=b", rw.Header().Get("Location"))
+}
+
+func TestContextPathParam(t *testing.T) {
+	a := Basic()
+	a.HandleFunc("/users/{id}/posts/{postID}", func(ctx Context) {
+		ctx.Text(ctx.PathParam("id") + "," + ctx.PathParam("postID") + "," + ctx.PathParam("none"))
+	})
+	require.Equal(t, "1,2,", a.TestRequest("GET", "/users/1/posts/2", nil).Body.String())
+	require.Equal(t, http.StatusNotFound, a.TestRequest("GET", "/users/1/posts", nil).Code)
+}
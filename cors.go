@@ -0,0 +1,153 @@
+package summer
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSConfig cross-origin resource sharing configuration, see [WithCORS]
+type CORSConfig struct {
+	// AllowOrigins allowed origins like "https://example.com", "*" allows any origin, a single "*" in host matches subdomains like "https://*.example.com"
+	AllowOrigins []string
+	// AllowOriginFunc custom check of origin, in addition to AllowOrigins
+	AllowOriginFunc func(origin string) bool
+	// AllowMethods allowed methods of preflight requests, defaults to GET, HEAD, POST, PUT, PATCH and DELETE
+	AllowMethods []string
+	// AllowHeaders allowed request headers of preflight requests, defaults to headers requested by "Access-Control-Request-Headers"
+	AllowHeaders []string
+	// ExposeHeaders response headers exposed to browsers
+	ExposeHeaders []string
+	// AllowCredentials allow cookies and authorization headers, origin is echoed instead of "*"
+	AllowCredentials bool
+	// MaxAge max age of preflight result cached by browsers, not set if <= 0
+	MaxAge time.Duration
+}
+
+// corsPolicy compiled [CORSConfig]
+type corsPolicy struct {
+	cfg CORSConfig
+
+	anyOrigin bool
+	origins   map[string]struct{}
+	wildcards [][2]string
+
+	methods       map[string]struct{}
+	allowMethods  string
+	allowHeaders  string
+	exposeHeaders string
+	maxAge        string
+}
+
+func newCORSPolicy(cfg CORSConfig) *corsPolicy {
+	p := &corsPolicy{
+		cfg:     cfg,
+		origins: map[string]struct{}{},
+		methods: map[string]struct{}{},
+	}
+	for _, o := range cfg.AllowOrigins {
+		o = strings.ToLower(strings.TrimSpace(o))
+		if o == "*" {
+			p.anyOrigin = true
+		} else if prefix, suffix, ok := strings.Cut(o, "*"); ok {
+			p.wildcards = append(p.wildcards, [2]string{prefix, suffix})
+		} else {
+			p.origins[o] = struct{}{}
+		}
+	}
+	var methods []string
+	for _, m := range cfg.AllowMethods {
+		methods = append(methods, strings.ToUpper(m))
+	}
+	if len(methods) == 0 {
+		methods = []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+	}
+	for _, m := range methods {
+		p.methods[m] = struct{}{}
+	}
+	p.allowMethods = strings.Join(methods, ", ")
+	p.allowHeaders = strings.Join(cfg.AllowHeaders, ", ")
+	p.exposeHeaders = strings.Join(cfg.ExposeHeaders, ", ")
+	if cfg.MaxAge > 0 {
+		p.maxAge = strconv.Itoa(int(cfg.MaxAge.Seconds()))
+	}
+	return p
+}
+
+// isOriginAllowed check if origin is allowed
+func (p *corsPolicy) isOriginAllowed(origin string) bool {
+	if p.anyOrigin {
+		return true
+	}
+	o := strings.ToLower(origin)
+	if _, ok := p.origins[o]; ok {
+		return true
+	}
+	for _, w := range p.wildcards {
+		if len(o) > len(w[0])+len(w[1]) && strings.HasPrefix(o, w[0]) && strings.HasSuffix(o, w[1]) {
+			return true
+		}
+	}
+	return p.cfg.AllowOriginFunc != nil && p.cfg.AllowOriginFunc(origin)
+}
+
+// serve set CORS headers of req, returns true if req is a preflight request and responded, forbid is called to respond disallowed preflight requests
+func (p *corsPolicy) serve(rw http.ResponseWriter, req *http.Request, forbid func()) bool {
+	h := rw.Header()
+	h.Add("Vary", "Origin")
+
+	origin := req.Header.Get("Origin")
+	preflight := req.Method == http.MethodOptions && req.Header.Get("Access-Control-Request-Method") != ""
+
+	if preflight {
+		h.Add("Vary", "Access-Control-Request-Method")
+		h.Add("Vary", "Access-Control-Request-Headers")
+	}
+
+	if origin == "" {
+		return false
+	}
+
+	if !p.isOriginAllowed(origin) {
+		if preflight {
+			forbid()
+		}
+		return preflight
+	}
+
+	if p.anyOrigin && !p.cfg.AllowCredentials {
+		h.Set("Access-Control-Allow-Origin", "*")
+	} else {
+		h.Set("Access-Control-Allow-Origin", origin)
+	}
+	if p.cfg.AllowCredentials {
+		h.Set("Access-Control-Allow-Credentials", "true")
+	}
+
+	if !preflight {
+		if p.exposeHeaders != "" {
+			h.Set("Access-Control-Expose-Headers", p.exposeHeaders)
+		}
+		return false
+	}
+
+	if _, ok := p.methods[strings.ToUpper(req.Header.Get("Access-Control-Request-Method"))]; !ok {
+		h.Del("Access-Control-Allow-Origin")
+		h.Del("Access-Control-Allow-Credentials")
+		forbid()
+		return true
+	}
+
+	h.Set("Access-Control-Allow-Methods", p.allowMethods)
+	if p.allowHeaders != "" {
+		h.Set("Access-Control-Allow-Headers", p.allowHeaders)
+	} else if requested := req.Header.Get("Access-Control-Request-Headers"); requested != "" {
+		h.Set("Access-Control-Allow-Headers", requested)
+	}
+	if p.maxAge != "" {
+		h.Set("Access-Control-Max-Age", p.maxAge)
+	}
+	rw.WriteHeader(http.StatusNoContent)
+	return true
+}
@@ -0,0 +1,136 @@
+package summer
+
+import (
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithCORS(t *testing.T) {
+	a := Basic(WithCORS(CORSConfig{
+		AllowOrigins:     []string{"https://example.com", "https://*.example.org"},
+		AllowMethods:     []string{"get", "post"},
+		ExposeHeaders:    []string{"X-Request-Id"},
+		AllowCredentials: true,
+		MaxAge:           time.Hour,
+	}))
+	a.POST("/test-cors", func(ctx Context) {
+		ctx.Text("OK")
+	})
+
+	request := func(method, path, origin string, fn func(req *http.Request)) *httptest.ResponseRecorder {
+		rw, req := httptest.NewRecorder(), httptest.NewRequest(method, "https://api.example.com"+path, nil)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		if fn != nil {
+			fn(req)
+		}
+		a.ServeHTTP(rw, req)
+		return rw
+	}
+
+	preflight := func(method, headers string) func(req *http.Request) {
+		return func(req *http.Request) {
+			req.Header.Set("Access-Control-Request-Method", method)
+			if headers != "" {
+				req.Header.Set("Access-Control-Request-Headers", headers)
+			}
+		}
+	}
+
+	// preflight of a method specific route
+	rw := request("OPTIONS", "/test-cors", "https://example.com", preflight("POST", "Content-Type, X-Custom"))
+	require.Equal(t, http.StatusNoContent, rw.Code)
+	require.Equal(t, "https://example.com", rw.Header().Get("Access-Control-Allow-Origin"))
+	require.Equal(t, "true", rw.Header().Get("Access-Control-Allow-Credentials"))
+	require.Equal(t, "GET, POST", rw.Header().Get("Access-Control-Allow-Methods"))
+	require.Equal(t, "Content-Type, X-Custom", rw.Header().Get("Access-Control-Allow-Headers"))
+	require.Equal(t, "3600", rw.Header().Get("Access-Control-Max-Age"))
+	require.Equal(t, "Origin, Access-Control-Request-Method, Access-Control-Request-Headers", strings.Join(rw.Header().Values("Vary"), ", "))
+
+	rw = request("OPTIONS", "/test-cors", "https://app.example.org", preflight("GET", ""))
+	require.Equal(t, http.StatusNoContent, rw.Code)
+	require.Equal(t, "https://app.example.org", rw.Header().Get("Access-Control-Allow-Origin"))
+
+	// disallowed preflight
+	rw = request("OPTIONS", "/test-cors", "https://example.net", preflight("POST", ""))
+	require.Equal(t, http.StatusForbidden, rw.Code)
+	require.Empty(t, rw.Header().Get("Access-Control-Allow-Origin"))
+
+	rw = request("OPTIONS", "/test-cors", "https://example.org", preflight("POST", ""))
+	require.Equal(t, http.StatusForbidden, rw.Code)
+
+	rw = request("OPTIONS", "/test-cors", "https://example.com", preflight("DELETE", ""))
+	require.Equal(t, http.StatusForbidden, rw.Code)
+	require.Empty(t, rw.Header().Get("Access-Control-Allow-Origin"))
+
+	// actual requests
+	rw = request("POST", "/test-cors", "https://example.com", nil)
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Equal(t, "https://example.com", rw.Header().Get("Access-Control-Allow-Origin"))
+	require.Equal(t, "X-Request-Id", rw.Header().Get("Access-Control-Expose-Headers"))
+	require.Equal(t, "Origin", rw.Header().Get("Vary"))
+
+	rw = request("POST", "/test-cors", "https://example.net", nil)
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Empty(t, rw.Header().Get("Access-Control-Allow-Origin"))
+
+	rw = request("POST", "/test-cors", "", nil)
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Empty(t, rw.Header().Get("Access-Control-Allow-Origin"))
+
+	// debug endpoints are not affected
+	rw = request("GET", "/debug/ready", "https://example.com", nil)
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Empty(t, rw.Header().Get("Access-Control-Allow-Origin"))
+	require.Empty(t, rw.Header().Get("Vary"))
+}
+
+func TestWithCORSAnyOrigin(t *testing.T) {
+	a := Basic(WithCORS(CORSConfig{
+		AllowOrigins: []string{"*"},
+		AllowHeaders: []string{"Content-Type"},
+		AllowOriginFunc: func(origin string) bool {
+			return false
+		},
+	}))
+	a.HandleFunc("/test-cors", func(ctx Context) {
+		ctx.Text("OK")
+	})
+
+	rw, req := httptest.NewRecorder(), httptest.NewRequest("OPTIONS", "https://api.example.com/test-cors", nil)
+	req.Header.Set("Origin", "https://anywhere.com")
+	req.Header.Set("Access-Control-Request-Method", "PUT")
+	req.Header.Set("Access-Control-Request-Headers", "X-Custom")
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusNoContent, rw.Code)
+	require.Equal(t, "*", rw.Header().Get("Access-Control-Allow-Origin"))
+	require.Empty(t, rw.Header().Get("Access-Control-Allow-Credentials"))
+	require.Equal(t, "Content-Type", rw.Header().Get("Access-Control-Allow-Headers"))
+	require.Empty(t, rw.Header().Get("Access-Control-Max-Age"))
+
+	// plain OPTIONS is routed
+	rw, req = httptest.NewRecorder(), httptest.NewRequest("OPTIONS", "https://api.example.com/test-cors", nil)
+	req.Header.Set("Origin", "https://anywhere.com")
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Equal(t, "OK", rw.Body.String())
+	require.Equal(t, "*", rw.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestCORSPolicyOriginFunc(t *testing.T) {
+	p := newCORSPolicy(CORSConfig{
+		AllowOrigins: []string{"HTTPS://Example.com"},
+		AllowOriginFunc: func(origin string) bool {
+			return strings.HasSuffix(origin, ".internal")
+		},
+	})
+	require.True(t, p.isOriginAllowed("https://example.com"))
+	require.True(t, p.isOriginAllowed("https://EXAMPLE.com"))
+	require.True(t, p.isOriginAllowed("http://app.internal"))
+	require.False(t, p.isOriginAllowed("https://example.com.evil"))
+}
@@ -0,0 +1,132 @@
+package summer
+
+import (
+	"context"
+	"fmt"
+	"github.com/robfig/cron/v3"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"runtime/debug"
+	"time"
+)
+
+// tracerName name of [trace.Tracer] for spans created by summer itself
+const tracerName = "github.com/guoyk93/summer"
+
+// cronJob a scheduled function registered by [App.Cron]
+type cronJob struct {
+	name     string
+	schedule cron.Schedule
+	fn       func(ctx context.Context) error
+
+	// onResult is called with result and duration of each run
+	onResult func(err error, d time.Duration)
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// call call fn once in a span, panics are recovered as errors
+func (j *cronJob) call(ctx context.Context) (err error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "cron "+j.name,
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(attribute.String("summer.cron.job", j.name)),
+	)
+	defer span.End()
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v\n%s", r, debug.Stack())
+		}
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+	}()
+	return j.fn(ctx)
+}
+
+// loop run fn at scheduled times until ctx is done
+//
+// Runs never overlap, the next time is scheduled after previous run finished, missed times are skipped.
+// The loop stops if schedule never matches again
+func (j *cronJob) loop(ctx context.Context) {
+	defer close(j.done)
+
+	for {
+		next := j.schedule.Next(time.Now())
+		// zero time, no matching time within years
+		if next.IsZero() {
+			return
+		}
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		start := time.Now()
+		err := j.call(ctx)
+		// cancelled by shutdown
+		if err != nil && ctx.Err() != nil {
+			return
+		}
+		j.onResult(err, time.Since(start))
+	}
+}
+
+func (j *cronJob) startup(ctx context.Context) error {
+	var jctx context.Context
+	jctx, j.cancel = context.WithCancel(context.Background())
+	j.done = make(chan struct{})
+	go j.loop(jctx)
+	return nil
+}
+
+// shutdown cancel the running function, and wait until it returns or ctx is done
+func (j *cronJob) shutdown(ctx context.Context) error {
+	j.cancel()
+	select {
+	case <-j.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("cron %s: %w", j.name, ctx.Err())
+	}
+}
+
+func (a *app[T]) Cron(spec string, name string, fn func(ctx context.Context) error) {
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		panic(fmt.Sprintf("summer: invalid cron spec %q of %s: %s", spec, name, err.Error()))
+	}
+	if schedule.Next(time.Now()).IsZero() {
+		panic(fmt.Sprintf("summer: cron spec %q of %s never matches", spec, name))
+	}
+	a.cron(name, schedule, fn)
+}
+
+// cron register a component named name, running fn with schedule
+func (a *app[T]) cron(name string, schedule cron.Schedule, fn func(ctx context.Context) error) {
+	j := &cronJob{
+		name:     name,
+		schedule: schedule,
+		fn:       fn,
+		onResult: func(err error, d time.Duration) {
+			a.mCronDuration.WithLabelValues(name).Observe(d.Seconds())
+			if err != nil {
+				a.mCronRuns.WithLabelValues(name, "failure").Inc()
+				a.opts.logger.Error("cron job failed", "job", name, "error", err.Error(), "duration", d.String())
+				return
+			}
+			a.mCronRuns.WithLabelValues(name, "success").Inc()
+			a.mCronLastSuccess.WithLabelValues(name).SetToCurrentTime()
+		},
+	}
+	a.Component(name).
+		Startup(j.startup).
+		Shutdown(j.shutdown)
+}
@@ -0,0 +1,115 @@
+package summer
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type testCronSchedule time.Duration
+
+func (s testCronSchedule) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(s))
+}
+
+// testCronScheduleOnce a schedule matching only once, zero time after it
+type testCronScheduleOnce time.Time
+
+func (s testCronScheduleOnce) Next(t time.Time) time.Time {
+	if t.Before(time.Time(s)) {
+		return time.Time(s)
+	}
+	return time.Time{}
+}
+
+func TestAppCron(t *testing.T) {
+	a := Basic()
+
+	var (
+		runs      int32
+		running   int32
+		overlap   atomic.Bool
+		cancelled atomic.Bool
+	)
+	a.(*app[Context]).cron("test-cron", testCronSchedule(time.Millisecond*5), func(ctx context.Context) error {
+		if atomic.AddInt32(&running, 1) > 1 {
+			overlap.Store(true)
+		}
+		defer atomic.AddInt32(&running, -1)
+
+		switch atomic.AddInt32(&runs, 1) {
+		case 1:
+			return errors.New("failed")
+		case 2:
+			panic("boom")
+		case 3:
+			// longer than interval
+			time.Sleep(time.Millisecond * 30)
+			return nil
+		}
+		<-ctx.Done()
+		cancelled.Store(true)
+		return ctx.Err()
+	})
+
+	require.NoError(t, a.Startup(context.Background()))
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt32(&runs) == 4
+	}, time.Second, time.Millisecond)
+
+	res := a.TestRequest("GET", "/debug/metrics", nil)
+	require.Contains(t, res.Body.String(), `summer_cron_runs_total{job="test-cron",result="failure"} 2`)
+	require.Contains(t, res.Body.String(), `summer_cron_runs_total{job="test-cron",result="success"} 1`)
+	require.Contains(t, res.Body.String(), `summer_cron_duration_seconds_count{job="test-cron"} 3`)
+	require.Contains(t, res.Body.String(), `summer_cron_last_success_timestamp_seconds{job="test-cron"}`)
+
+	require.NoError(t, a.Shutdown(context.Background()))
+	require.True(t, cancelled.Load())
+	require.False(t, overlap.Load())
+	require.EqualValues(t, 4, atomic.LoadInt32(&runs))
+}
+
+func TestAppCronSpec(t *testing.T) {
+	a := Basic()
+	a.Cron("*/5 * * * *", "test-cron-spec", func(ctx context.Context) error {
+		return nil
+	})
+	a.Cron("@every 1h", "test-cron-every", func(ctx context.Context) error {
+		return nil
+	})
+	require.Contains(t, a.CheckNames(), "test-cron-spec")
+	require.PanicsWithValue(t, `summer: invalid cron spec "invalid" of test-cron-invalid: expected exactly 5 fields, found 1: [invalid]`, func() {
+		a.Cron("invalid", "test-cron-invalid", func(ctx context.Context) error {
+			return nil
+		})
+	})
+	require.PanicsWithValue(t, `summer: cron spec "0 0 30 2 *" of test-cron-never never matches`, func() {
+		a.Cron("0 0 30 2 *", "test-cron-never", func(ctx context.Context) error {
+			return nil
+		})
+	})
+}
+
+func TestAppCronScheduleExhausted(t *testing.T) {
+	a := Basic()
+
+	var runs int32
+	a.(*app[Context]).cron("test-cron-exhausted", testCronScheduleOnce(time.Now().Add(time.Millisecond*5)), func(ctx context.Context) error {
+		atomic.AddInt32(&runs, 1)
+		return nil
+	})
+
+	require.NoError(t, a.Startup(context.Background()))
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt32(&runs) == 1
+	}, time.Second, time.Millisecond)
+
+	// loop stopped instead of spinning on zero time
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+	require.NoError(t, a.Shutdown(ctx))
+	require.EqualValues(t, 1, atomic.LoadInt32(&runs))
+}
@@ -0,0 +1,93 @@
+package summer
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// dedupCall an in-flight deduplicated request
+type dedupCall struct {
+	done chan struct{}
+	res  *bufferedResponse
+}
+
+// dedupGroup group of in-flight deduplicated requests by key, see [WithGetDeduplication]
+type dedupGroup struct {
+	mu    sync.Mutex
+	calls map[string]*dedupCall
+}
+
+// join returns the in-flight call of key, or creates one if leader is true
+func (g *dedupGroup) join(key string) (c *dedupCall, leader bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if c = g.calls[key]; c != nil {
+		return c, false
+	}
+	if g.calls == nil {
+		g.calls = map[string]*dedupCall{}
+	}
+	c = &dedupCall{done: make(chan struct{})}
+	g.calls[key] = c
+	return c, true
+}
+
+// finish remove the call of key, and release waiters with res
+func (g *dedupGroup) finish(key string, c *dedupCall, res *bufferedResponse) {
+	g.mu.Lock()
+	delete(g.calls, key)
+	g.mu.Unlock()
+
+	c.res = res
+	close(c.done)
+}
+
+// dedupVaryHeaders request headers commonly varying responses, included in deduplication key
+var dedupVaryHeaders = []string{"Accept", "Accept-Encoding", "Accept-Language"}
+
+// dedupPrivateHeaders request headers carrying credentials, requests with any of them are never deduplicated
+var dedupPrivateHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", DefaultAPIKeyHeader}
+
+// dedupVaries check if "Vary" header of response contains headers not included in deduplication key
+func dedupVaries(header http.Header) bool {
+	for _, v := range header.Values("Vary") {
+		for _, name := range strings.Split(v, ",") {
+			name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+			if name == "" {
+				continue
+			}
+			if name == "*" || !slices.Contains(dedupVaryHeaders, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// dedupKey returns key of deduplication, false if the request must not be deduplicated
+func dedupKey(req *http.Request) (string, bool) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return "", false
+	}
+	for _, h := range dedupPrivateHeaders {
+		if len(req.Header.Values(h)) > 0 {
+			return "", false
+		}
+	}
+	// Server-Sent Events
+	if strings.Contains(req.Header.Get("Accept"), ContentTypeTextEventStream) {
+		return "", false
+	}
+	var sb strings.Builder
+	sb.WriteString(req.Method)
+	sb.WriteString(" ")
+	sb.WriteString(req.URL.RequestURI())
+	for _, h := range dedupVaryHeaders {
+		sb.WriteString("\n")
+		sb.WriteString(strings.Join(req.Header.Values(h), ","))
+	}
+	return sb.String(), true
+}
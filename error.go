@@ -7,8 +7,12 @@ import (
 
 const (
 	HaltExtraKeyMessage = "message"
+	HaltExtraKeyDetails = "details"
 )
 
+// ErrBodyTooLarge error returned when request body exceeds the limit, results in 413 if used with [Halt] or panic
+var ErrBodyTooLarge = NewHaltError(errors.New("request body too large"), HaltWithStatusCode(http.StatusRequestEntityTooLarge))
+
 // HaltOption configuration function for [HaltError]
 type HaltOption func(h *haltError)
 
@@ -89,6 +93,71 @@ func (h *haltError) ExtractExtras(m map[string]any) {
 	}
 }
 
+// Error a structured error with status code, public message, internal cause and details
+//
+// [Context.Perform] renders it as a JSON body with public message and details, the cause is only recorded to current span
+type Error struct {
+	// Code status code of response
+	Code int
+	// Message public message, exposed to client
+	Message string
+	// Cause internal cause, not exposed to client
+	Cause error
+	// Details public key-values, exposed to client as "details"
+	Details map[string]any
+}
+
+var (
+	_ withStatusCode = &Error{}
+	_ withExtract    = &Error{}
+	_ withUnwrap     = &Error{}
+)
+
+// NewError create a new [Error] with status code and public message
+func NewError(code int, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+// WithCause set the internal cause, returns the [Error] itself
+func (e *Error) WithCause(err error) *Error {
+	e.Cause = err
+	return e
+}
+
+// WithDetail set a key-value of details, returns the [Error] itself
+func (e *Error) WithDetail(k string, v any) *Error {
+	if e.Details == nil {
+		e.Details = map[string]any{}
+	}
+	e.Details[k] = v
+	return e
+}
+
+func (e *Error) Error() string {
+	if e.Cause == nil {
+		return e.Message
+	}
+	return e.Message + ": " + e.Cause.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+func (e *Error) StatusCode() int {
+	if e.Code == 0 {
+		return http.StatusInternalServerError
+	}
+	return e.Code
+}
+
+func (e *Error) ExtractExtras(m map[string]any) {
+	m[HaltExtraKeyMessage] = e.Message
+	if len(e.Details) > 0 {
+		m[HaltExtraKeyDetails] = e.Details
+	}
+}
+
 // NewHaltError create a new [HaltError]
 func NewHaltError(err error, opts ...HaltOption) error {
 	he := &haltError{
@@ -143,6 +212,10 @@ func BodyFromError(err error) (m map[string]any) {
 		if eh, ok := err.(withExtract); ok {
 			eh.ExtractExtras(m)
 		}
+		// cause of Error is internal
+		if _, ok := err.(*Error); ok {
+			break
+		}
 		if eu, ok := err.(withUnwrap); ok {
 			err = eu.Unwrap()
 		} else {
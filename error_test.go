@@ -74,3 +74,21 @@ func TesPanicAny(t *testing.T) {
 	require.Equal(t, http.StatusInternalServerError, StatusCodeFromError(err))
 	require.Equal(t, map[string]any{"message": "panic: TEST1"}, m)
 }
+
+func TestError(t *testing.T) {
+	cause := NewHaltError(errors.New("connection refused"), HaltWithMessage("internal"))
+	err := NewError(http.StatusServiceUnavailable, "upstream unavailable").
+		WithCause(cause).
+		WithDetail("upstream", "db")
+
+	require.Equal(t, "upstream unavailable: connection refused", err.Error())
+	require.True(t, errors.Is(err, cause))
+	require.Equal(t, http.StatusServiceUnavailable, StatusCodeFromError(err))
+	require.Equal(t, map[string]any{
+		"message": "upstream unavailable",
+		"details": map[string]any{"upstream": "db"},
+	}, BodyFromError(err))
+
+	require.Equal(t, http.StatusInternalServerError, StatusCodeFromError(&Error{Message: "test"}))
+	require.Equal(t, map[string]any{"message": "test"}, BodyFromError(&Error{Message: "test"}))
+}
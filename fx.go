@@ -0,0 +1,74 @@
+package summer
+
+import (
+	"context"
+	"go.uber.org/fx"
+	"net/http"
+)
+
+type fxAppParams[T Context] struct {
+	fx.In
+
+	ContextFactory ContextFactory[T]
+	Options        []Option `optional:"true"`
+}
+
+// FxModule create a [fx.Option] providing [App] and the [http.Server] serving it on addr
+//
+// [App.Run] is started by [fx.Lifecycle] OnStart and stopped by OnStop. A [ContextFactory] must be provided, []Option is optional
+//
+// example:
+//
+//	fx.New(
+//		fx.Provide(func() summer.ContextFactory[summer.Context] { return summer.BasicContext }),
+//		fx.Supply([]summer.Option{summer.WithConcurrency(64)}),
+//		summer.FxModule[summer.Context](":8080"),
+//		fx.Invoke(func(a summer.App[summer.Context]) {
+//			a.HandleFunc("/hello", actionHello)
+//		}),
+//	).Run()
+func FxModule[T Context](addr string) fx.Option {
+	return fx.Module(
+		"summer",
+		fx.Provide(func(p fxAppParams[T]) App[T] {
+			return New(p.ContextFactory, p.Options...)
+		}),
+		fx.Provide(func(a App[T]) *http.Server {
+			return a.(*app[T]).newServer(addr)
+		}),
+		fx.Invoke(func(lc fx.Lifecycle, a App[T], s *http.Server) {
+			var (
+				cancel context.CancelFunc
+				chErr  = make(chan error, 1)
+			)
+			lc.Append(fx.Hook{
+				OnStart: func(ctx context.Context) error {
+					var rctx context.Context
+					rctx, cancel = context.WithCancel(context.Background())
+					chReady := make(chan struct{})
+					go func() {
+						chErr <- a.(*app[T]).runServer(rctx, s, func() { close(chReady) })
+					}()
+					select {
+					case <-chReady:
+						return nil
+					case err := <-chErr:
+						return err
+					case <-ctx.Done():
+						cancel()
+						return ctx.Err()
+					}
+				},
+				OnStop: func(ctx context.Context) error {
+					cancel()
+					select {
+					case err := <-chErr:
+						return err
+					case <-ctx.Done():
+						return ctx.Err()
+					}
+				},
+			})
+		}),
+	)
+}
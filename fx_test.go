@@ -0,0 +1,44 @@
+package summer
+
+import (
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestFxModule(t *testing.T) {
+	addr := freeAddr(t)
+
+	var s *http.Server
+
+	app := fxtest.New(
+		t,
+		fx.NopLogger,
+		fx.Provide(func() ContextFactory[Context] { return BasicContext }),
+		fx.Supply([]Option{WithStartupLog(false)}),
+		FxModule[Context](addr),
+		fx.Invoke(func(a App[Context]) {
+			a.HandleFunc("/test", func(ctx Context) {
+				ctx.Text("OK")
+			})
+		}),
+		fx.Populate(&s),
+	)
+	require.Equal(t, addr, s.Addr)
+
+	app.RequireStart()
+
+	res, err := http.Get("http://" + addr + "/test")
+	require.NoError(t, err)
+	buf, _ := io.ReadAll(res.Body)
+	_ = res.Body.Close()
+	require.Equal(t, "OK", string(buf))
+
+	app.RequireStop()
+
+	_, err = http.Get("http://" + addr + "/test")
+	require.Error(t, err)
+}
@@ -0,0 +1,75 @@
+package summer
+
+import "strings"
+
+// Group a group of routes sharing a path prefix and middlewares
+type Group[T Context] interface {
+	// MethodRouter inherit [MethodRouter], patterns are prefixed with group prefix
+	MethodRouter[T]
+
+	// HandleFunc register an action function with given path pattern, prefixed with group prefix
+	//
+	// Method of pattern like "GET /users" is kept in front of the prefix
+	HandleFunc(pattern string, fn HandlerFunc[T], opts ...Option)
+
+	// Use append middlewares to the group, affecting routes registered afterwards
+	Use(mws ...MiddlewareFunc[T])
+
+	// Group create a nested [Group], prefixes, middlewares and concurrency limits are composed
+	Group(prefix string, mws ...MiddlewareFunc[T]) Group[T]
+
+	// Concurrency limit concurrent requests of all routes of the group registered afterwards, shared by these routes,
+	// in addition to [WithRouteConcurrency] of each route
+	//
+	// Gauges "summer_route_in_flight" and "summer_route_queue_depth" are labeled with "group " and prefix of group
+	Concurrency(rc RouteConcurrency)
+}
+
+type group[T Context] struct {
+	app      *app[T]
+	prefix   string
+	mws      []MiddlewareFunc[T]
+	limiters []*routeLimiter
+}
+
+// joinPattern join prefix and pattern, keeping method of pattern in front
+func joinPattern(prefix, pattern string) string {
+	if method, p, ok := strings.Cut(pattern, " "); ok && method != "" && !strings.Contains(method, "/") {
+		return method + " " + prefix + strings.TrimLeft(p, " \t")
+	}
+	return prefix + pattern
+}
+
+func (g *group[T]) HandleFunc(pattern string, fn HandlerFunc[T], opts ...Option) {
+	if len(g.limiters) > 0 {
+		opts = append([]Option{withGroupLimiters(g.limiters)}, opts...)
+	}
+	if len(g.mws) > 0 {
+		opts = append(opts, withRouteMiddlewares())
+	}
+	// types of Wrap, lost after chaining middlewares
+	if op := routeOperation(fn, opts); op != nil {
+		opts = append(opts, WithOperation(*op))
+	}
+	g.app.HandleFunc(joinPattern(g.prefix, pattern), chainMiddlewares(fn, g.mws), opts...)
+}
+
+func (g *group[T]) Use(mws ...MiddlewareFunc[T]) {
+	g.mws = append(append([]MiddlewareFunc[T]{}, g.mws...), mws...)
+}
+
+func (g *group[T]) Group(prefix string, mws ...MiddlewareFunc[T]) Group[T] {
+	return &group[T]{
+		app:      g.app,
+		prefix:   g.prefix + prefix,
+		mws:      append(append([]MiddlewareFunc[T]{}, g.mws...), mws...),
+		limiters: g.limiters,
+	}
+}
+
+func (g *group[T]) Concurrency(rc RouteConcurrency) {
+	if rc.Limit <= 0 {
+		return
+	}
+	g.limiters = append(append([]*routeLimiter{}, g.limiters...), g.app.newGroupLimiter(g.prefix, rc))
+}
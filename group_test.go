@@ -0,0 +1,83 @@
+package summer
+
+import (
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGroup(t *testing.T) {
+	var calls []string
+
+	a := Basic()
+
+	g := a.Group("/api", func(ctx Context, next func()) {
+		calls = append(calls, "api")
+		next()
+	})
+	g.HandleFunc("/hello", func(ctx Context) {
+		calls = append(calls, "hello")
+		ctx.Text("hello")
+	})
+
+	v1 := g.Group("/v1", func(ctx Context, next func()) {
+		calls = append(calls, "v1")
+		if ctx.Req().Header.Get("Authorization") == "" {
+			ctx.Code(http.StatusUnauthorized)
+			ctx.Text("UNAUTHORIZED")
+			return
+		}
+		next()
+	})
+	v1.HandleFunc("/world", func(ctx Context) {
+		calls = append(calls, "world")
+		ctx.Text("world")
+	})
+
+	rw, req := httptest.NewRecorder(), httptest.NewRequest("GET", "https://example.com/api/hello", nil)
+	a.ServeHTTP(rw, req)
+	require.Equal(t, "hello", rw.Body.String())
+	require.Equal(t, []string{"api", "hello"}, calls)
+
+	calls = nil
+	rw, req = httptest.NewRecorder(), httptest.NewRequest("GET", "https://example.com/api/v1/world", nil)
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusUnauthorized, rw.Code)
+	require.Equal(t, []string{"api", "v1"}, calls)
+
+	calls = nil
+	rw, req = httptest.NewRecorder(), httptest.NewRequest("GET", "https://example.com/api/v1/world", nil)
+	req.Header.Set("Authorization", "Bearer test")
+	a.ServeHTTP(rw, req)
+	require.Equal(t, "world", rw.Body.String())
+	require.Equal(t, []string{"api", "v1", "world"}, calls)
+}
+
+func TestGroupUse(t *testing.T) {
+	var calls []string
+
+	a := Basic()
+	g := a.Group("/internal")
+	g.HandleFunc("/before", func(ctx Context) {
+		calls = append(calls, "before")
+	})
+	g.Use(func(ctx Context, next func()) {
+		calls = append(calls, "mw")
+		next()
+	})
+	g.HandleFunc("GET /after", func(ctx Context) {
+		calls = append(calls, "after")
+	})
+
+	require.Equal(t, []string{"/internal/before", "GET /internal/after"}, a.Patterns())
+
+	a.TestRequest("GET", "/internal/before", nil)
+	require.Equal(t, []string{"before"}, calls)
+
+	calls = nil
+	a.TestRequest("GET", "/internal/after", nil)
+	require.Equal(t, []string{"mw", "after"}, calls)
+
+	require.Equal(t, http.StatusMethodNotAllowed, a.TestRequest("POST", "/internal/after", nil).Code)
+}
@@ -0,0 +1,11 @@
+package summer
+
+import (
+	"net/http"
+	"strings"
+)
+
+// isGRPCRequest check if req is a gRPC request, HTTP/2 with content type "application/grpc"
+func isGRPCRequest(req *http.Request) bool {
+	return req.ProtoMajor == 2 && strings.HasPrefix(req.Header.Get("Content-Type"), ContentTypeApplicationGRPC)
+}
@@ -0,0 +1,67 @@
+package summer
+
+import (
+	"context"
+	"crypto/tls"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAppWithGRPC(t *testing.T) {
+	a := Basic(WithGRPC(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", ContentTypeApplicationGRPC)
+		_, _ = rw.Write([]byte("GRPC " + req.URL.Path))
+	})))
+	a.HandleFunc("/test", func(ctx Context) {
+		ctx.Text("HTTP " + ctx.Req().Proto)
+	})
+
+	s := httptest.NewServer(a.(*app[Context]).newServer("").Handler)
+	defer s.Close()
+
+	client := &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLSContext: func(ctx context.Context, network, addr string, cfg *tls.Config) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, addr)
+		},
+	}}
+
+	get := func(req *http.Request) string {
+		res, err := client.Do(req)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		buf, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		return string(buf)
+	}
+
+	req, _ := http.NewRequest("POST", s.URL+"/pkg.Service/Method", strings.NewReader(""))
+	req.Header.Set("Content-Type", "application/grpc+proto")
+	require.Equal(t, "GRPC /pkg.Service/Method", get(req))
+
+	req, _ = http.NewRequest("GET", s.URL+"/test", nil)
+	require.Equal(t, "HTTP HTTP/2.0", get(req))
+
+	// HTTP/1.1 still works
+	res, err := http.Get(s.URL + "/test")
+	require.NoError(t, err)
+	defer res.Body.Close()
+	buf, _ := io.ReadAll(res.Body)
+	require.Equal(t, "HTTP HTTP/1.1", string(buf))
+}
+
+func TestIsGRPCRequest(t *testing.T) {
+	req := httptest.NewRequest("POST", "/pkg.Service/Method", nil)
+	req.Header.Set("Content-Type", ContentTypeApplicationGRPC)
+	require.False(t, isGRPCRequest(req))
+	req.ProtoMajor = 2
+	require.True(t, isGRPCRequest(req))
+	req.Header.Set("Content-Type", ContentTypeApplicationJSON)
+	require.False(t, isGRPCRequest(req))
+}
@@ -0,0 +1,45 @@
+package summer
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// BuildInfo build and version information of running binary, exposed at [InfoPath]
+type BuildInfo struct {
+	GoVersion   string            `json:"go_version"`
+	Path        string            `json:"path,omitempty"`
+	Version     string            `json:"version,omitempty"`
+	VCSRevision string            `json:"vcs_revision,omitempty"`
+	VCSTime     string            `json:"vcs_time,omitempty"`
+	VCSModified bool              `json:"vcs_modified,omitempty"`
+	Info        map[string]string `json:"info,omitempty"`
+}
+
+// readBuildInfo collect [BuildInfo] from [debug.ReadBuildInfo], with app-supplied info
+func readBuildInfo(info map[string]string) BuildInfo {
+	bi := BuildInfo{GoVersion: runtime.Version(), Info: info}
+	if raw, ok := debug.ReadBuildInfo(); ok {
+		bi.Path = raw.Main.Path
+		bi.Version = raw.Main.Version
+		for _, item := range raw.Settings {
+			switch item.Key {
+			case "vcs.revision":
+				bi.VCSRevision = item.Value
+			case "vcs.time":
+				bi.VCSTime = item.Value
+			case "vcs.modified":
+				bi.VCSModified = item.Value == "true"
+			}
+		}
+	}
+	return bi
+}
+
+// VersionInfo version information exposed by [WithVersionEndpoint]
+type VersionInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"build_date"`
+	GoVersion string `json:"go_version"`
+}
@@ -0,0 +1,37 @@
+package summer
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"runtime"
+	"testing"
+)
+
+func TestAppInfo(t *testing.T) {
+	a := Basic(WithInfo(map[string]string{"env": "test"}))
+	res := a.TestRequest("GET", InfoPath, nil)
+	require.Equal(t, 200, res.Code)
+
+	var bi BuildInfo
+	require.NoError(t, json.Unmarshal(res.Body.Bytes(), &bi))
+	require.Equal(t, runtime.Version(), bi.GoVersion)
+	require.Equal(t, map[string]string{"env": "test"}, bi.Info)
+}
+
+func TestAppVersionEndpoint(t *testing.T) {
+	a := Basic()
+	require.Equal(t, 404, a.TestRequest("GET", DefaultVersionPath, nil).Code)
+
+	a = Basic(WithVersionEndpoint(VersionInfo{Version: "v1.0.0", Commit: "abc"}))
+	for _, p := range []string{DefaultVersionPath, BuildPath} {
+		res := a.TestRequest("GET", p, nil)
+		require.Equal(t, 200, res.Code)
+		var vi VersionInfo
+		require.NoError(t, json.Unmarshal(res.Body.Bytes(), &vi))
+		require.Equal(t, VersionInfo{Version: "v1.0.0", Commit: "abc", GoVersion: runtime.Version()}, vi)
+	}
+
+	a = Basic(WithVersionEndpoint(VersionInfo{Version: "v1.0.0"}), WithVersionEndpointPath("/api/version"))
+	require.Equal(t, 200, a.TestRequest("GET", "/api/version", nil).Code)
+	require.Equal(t, 404, a.TestRequest("GET", DefaultVersionPath, nil).Code)
+}
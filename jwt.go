@@ -0,0 +1,329 @@
+package summer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/sync/singleflight"
+	"io"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// JWTOptions options of [JWTAuth]
+type JWTOptions struct {
+	// Keyfunc returns key verifying a token, for example [JWKS.Keyfunc], required
+	Keyfunc jwt.Keyfunc
+	// Methods allowed signing algorithms like "RS256", defaults to any algorithm matching type of the key
+	Methods []string
+	// Issuer expected "iss" claim, not checked if empty
+	Issuer string
+	// Audience expected "aud" claim, not checked if empty
+	Audience string
+	// Leeway leeway of validating "exp", "nbf" and "iat" claims
+	Leeway time.Duration
+}
+
+type jwtAuthenticator[T Context] struct {
+	keyfunc jwt.Keyfunc
+	parser  *jwt.Parser
+}
+
+func (a *jwtAuthenticator[T]) Authenticate(ctx T) (p *Principal, err error) {
+	raw := bearerToken(ctx.Req())
+	if raw == "" {
+		return
+	}
+	claims := jwt.MapClaims{}
+	if _, err = a.parser.ParseWithClaims(raw, claims, a.keyfunc); err != nil {
+		return
+	}
+	sub, _ := claims.GetSubject()
+	p = &Principal{Subject: sub, Method: AuthMethodBearer, Claims: claims}
+	return
+}
+
+func (a *jwtAuthenticator[T]) challenge() string {
+	return "Bearer"
+}
+
+// JWTAuth create an [Authenticator] of JWT from bearer token in "Authorization" header
+//
+// Claims of token are available as [Principal.Claims], with "sub" claim as [Principal.Subject]. Missing [JWTOptions.Keyfunc] causes a panic
+func JWTAuth[T Context](o JWTOptions) Authenticator[T] {
+	if o.Keyfunc == nil {
+		panic("summer: JWTOptions.Keyfunc is required")
+	}
+	var opts []jwt.ParserOption
+	if len(o.Methods) > 0 {
+		opts = append(opts, jwt.WithValidMethods(o.Methods))
+	}
+	if o.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(o.Issuer))
+	}
+	if o.Audience != "" {
+		opts = append(opts, jwt.WithAudience(o.Audience))
+	}
+	if o.Leeway > 0 {
+		opts = append(opts, jwt.WithLeeway(o.Leeway))
+	}
+	return &jwtAuthenticator[T]{keyfunc: o.Keyfunc, parser: jwt.NewParser(opts...)}
+}
+
+const (
+	// jwksFetchTimeout timeout of fetching a JWKS
+	jwksFetchTimeout = time.Second * 10
+	// jwksMinRefreshInterval minimum interval between refreshes triggered by unknown key ids
+	jwksMinRefreshInterval = time.Minute
+	// jwksMaxBytes maximum size of a JWKS response
+	jwksMaxBytes = 1 << 20
+)
+
+// ErrJWKSKeyNotFound key id of token not found in [JWKS]
+var ErrJWKSKeyNotFound = errors.New("jwks: key not found")
+
+// JWKS a JSON Web Key Set fetched from url, usually "/.well-known/jwks.json" of an identity provider
+//
+// RSA, ECDSA (P-256, P-384 and P-521) and Ed25519 signing keys are supported, other keys are ignored
+type JWKS struct {
+	url      string
+	client   *http.Client
+	interval time.Duration
+
+	// sf concurrent refreshes share a single fetch
+	sf singleflight.Group
+
+	mu          sync.RWMutex
+	keys        map[string]any
+	fetchedAt   time.Time
+	attemptedAt time.Time
+}
+
+// NewJWKS create a [JWKS] fetching keys from url with client, refreshed every interval
+//
+// client defaults to [http.DefaultClient] if nil, interval defaults to 1 hour if <= 0
+func NewJWKS(url string, client *http.Client, interval time.Duration) *JWKS {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	return &JWKS{url: url, client: client, interval: interval}
+}
+
+// Refresh fetch keys from url, keys are replaced only if succeeded, concurrent refreshes share a single fetch
+func (j *JWKS) Refresh(ctx context.Context) (err error) {
+	_, err, _ = j.sf.Do("", func() (any, error) {
+		return nil, j.refresh(ctx)
+	})
+	return
+}
+
+// refreshWithTimeout like [JWKS.Refresh], with fetch timeout
+func (j *JWKS) refreshWithTimeout() error {
+	ctx, cancel := context.WithTimeout(context.Background(), jwksFetchTimeout)
+	defer cancel()
+	return j.Refresh(ctx)
+}
+
+// refresh fetch keys from url without holding the lock, responses larger than 1 MiB are rejected
+func (j *JWKS) refresh(ctx context.Context) (err error) {
+	attemptedAt := time.Now()
+	j.mu.Lock()
+	j.attemptedAt = attemptedAt
+	j.mu.Unlock()
+
+	var req *http.Request
+	if req, err = http.NewRequestWithContext(ctx, http.MethodGet, j.url, nil); err != nil {
+		return
+	}
+	var res *http.Response
+	if res, err = j.client.Do(req); err != nil {
+		return
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		err = fmt.Errorf("jwks: unexpected status code %d from %s", res.StatusCode, j.url)
+		return
+	}
+
+	var buf []byte
+	if buf, err = io.ReadAll(io.LimitReader(res.Body, jwksMaxBytes+1)); err != nil {
+		return
+	}
+	if len(buf) > jwksMaxBytes {
+		err = fmt.Errorf("jwks: response from %s larger than %d bytes", j.url, jwksMaxBytes)
+		return
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err = json.Unmarshal(buf, &set); err != nil {
+		return
+	}
+
+	keys := map[string]any{}
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		if key, err1 := k.publicKey(); err1 == nil {
+			keys[k.Kid] = key
+		}
+	}
+
+	j.mu.Lock()
+	j.keys = keys
+	j.fetchedAt = attemptedAt
+	j.mu.Unlock()
+	return
+}
+
+// Keyfunc implements [jwt.Keyfunc], finding key by "kid" header of token
+//
+// Keys are fetched on first use and refreshed in background after interval, unknown key ids trigger a refresh at most once per minute.
+// Stale keys are kept if refreshing failed. Only the first fetch and refreshes of unknown key ids are waited by tokens
+func (j *JWKS) Keyfunc(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	now := time.Now()
+
+	j.mu.RLock()
+	key, ok := j.lookup(kid)
+	loaded := j.keys != nil
+	stale := now.Sub(j.fetchedAt) >= j.interval
+	throttled := now.Sub(j.attemptedAt) < jwksMinRefreshInterval
+	j.mu.RUnlock()
+
+	if ok {
+		if stale && !throttled {
+			go func() {
+				_ = j.refreshWithTimeout()
+			}()
+		}
+		return key, nil
+	}
+
+	var err error
+	if !loaded || !throttled {
+		err = j.refreshWithTimeout()
+
+		j.mu.RLock()
+		key, ok = j.lookup(kid)
+		j.mu.RUnlock()
+
+		if ok {
+			return key, nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("%w: %q", ErrJWKSKeyNotFound, kid)
+}
+
+// lookup find key by kid, the only key is used if kid is empty, must be called with lock held
+func (j *JWKS) lookup(kid string) (key any, ok bool) {
+	if key, ok = j.keys[kid]; ok {
+		return
+	}
+	if kid == "" && len(j.keys) == 1 {
+		for _, key = range j.keys {
+			ok = true
+		}
+	}
+	return
+}
+
+// jwk a JSON Web Key, RFC 7517
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// publicKey decode public key of k
+func (k jwk) publicKey() (key any, err error) {
+	decode := func(s string) (*big.Int, error) {
+		buf, err := base64.RawURLEncoding.DecodeString(s)
+		if err != nil {
+			return nil, err
+		}
+		return new(big.Int).SetBytes(buf), nil
+	}
+
+	switch k.Kty {
+	case "RSA":
+		var n, e *big.Int
+		if n, err = decode(k.N); err != nil {
+			return
+		}
+		if e, err = decode(k.E); err != nil {
+			return
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			err = errors.New("jwks: invalid rsa exponent")
+			return
+		}
+		key = &rsa.PublicKey{N: n, E: int(e.Int64())}
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			err = errors.New("jwks: unsupported curve: " + k.Crv)
+			return
+		}
+		var x, y *big.Int
+		if x, err = decode(k.X); err != nil {
+			return
+		}
+		if y, err = decode(k.Y); err != nil {
+			return
+		}
+		if !curve.IsOnCurve(x, y) {
+			err = errors.New("jwks: invalid ec point")
+			return
+		}
+		key = &ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			err = errors.New("jwks: unsupported curve: " + k.Crv)
+			return
+		}
+		var buf []byte
+		if buf, err = base64.RawURLEncoding.DecodeString(k.X); err != nil {
+			return
+		}
+		if len(buf) != ed25519.PublicKeySize {
+			err = errors.New("jwks: invalid ed25519 key size")
+			return
+		}
+		key = ed25519.PublicKey(buf)
+	default:
+		err = errors.New("jwks: unsupported key type: " + k.Kty)
+	}
+	return
+}
@@ -0,0 +1,254 @@
+package summer
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testRSAJWK(kid string, key *rsa.PublicKey) map[string]any {
+	return map[string]any{
+		"kty": "RSA",
+		"kid": kid,
+		"use": "sig",
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func TestJWKPublicKey(t *testing.T) {
+	rk, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ek, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	b64 := base64.RawURLEncoding.EncodeToString
+
+	key, err := jwk{Kty: "RSA", N: b64(rk.N.Bytes()), E: b64(big.NewInt(int64(rk.E)).Bytes())}.publicKey()
+	require.NoError(t, err)
+	require.True(t, rk.PublicKey.Equal(key))
+
+	key, err = jwk{Kty: "EC", Crv: "P-256", X: b64(ek.X.Bytes()), Y: b64(ek.Y.Bytes())}.publicKey()
+	require.NoError(t, err)
+	require.True(t, ek.PublicKey.Equal(key))
+
+	_, err = jwk{Kty: "EC", Crv: "P-256", X: b64(ek.X.Bytes()), Y: b64(ek.X.Bytes())}.publicKey()
+	require.Error(t, err)
+
+	_, err = jwk{Kty: "EC", Crv: "P-192"}.publicKey()
+	require.Error(t, err)
+
+	key, err = jwk{Kty: "OKP", Crv: "Ed25519", X: b64(pub)}.publicKey()
+	require.NoError(t, err)
+	require.True(t, pub.Equal(key))
+
+	_, err = jwk{Kty: "oct"}.publicKey()
+	require.Error(t, err)
+}
+
+func TestJWTAuth(t *testing.T) {
+	key1, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	key2, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	var (
+		mu      sync.Mutex
+		keys    = []any{testRSAJWK("key-1", &key1.PublicKey), map[string]any{"kty": "oct", "kid": "secret"}}
+		fetches int32
+		failing atomic.Bool
+	)
+
+	s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&fetches, 1)
+		if failing.Load() {
+			rw.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		_ = json.NewEncoder(rw).Encode(map[string]any{"keys": keys})
+	}))
+	defer s.Close()
+
+	jwks := NewJWKS(s.URL, nil, 0)
+
+	a := Basic()
+	a.Use(Authenticate(JWTAuth[Context](JWTOptions{
+		Keyfunc:  jwks.Keyfunc,
+		Methods:  []string{"RS256"},
+		Issuer:   "https://issuer.example.com",
+		Audience: "summer",
+	})))
+	a.HandleFunc("/test-jwt", func(ctx Context) {
+		p := ctx.Principal()
+		ctx.JSON(map[string]any{"subject": p.Subject, "method": p.Method, "scope": p.Claims["scope"]})
+	})
+
+	sign := func(kid string, key *rsa.PrivateKey, claims jwt.MapClaims) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = kid
+		raw, err := token.SignedString(key)
+		require.NoError(t, err)
+		return raw
+	}
+
+	claims := func(modify func(c jwt.MapClaims)) jwt.MapClaims {
+		c := jwt.MapClaims{
+			"sub":   "user-1",
+			"iss":   "https://issuer.example.com",
+			"aud":   "summer",
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"scope": "read",
+		}
+		if modify != nil {
+			modify(c)
+		}
+		return c
+	}
+
+	request := func(authorization string) *httptest.ResponseRecorder {
+		rw, req := httptest.NewRecorder(), httptest.NewRequest("GET", "https://example.com/test-jwt", nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		a.ServeHTTP(rw, req)
+		return rw
+	}
+
+	rw := request("Bearer " + sign("key-1", key1, claims(nil)))
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.JSONEq(t, `{"subject":"user-1","method":"bearer","scope":"read"}`, rw.Body.String())
+	require.Equal(t, int32(1), atomic.LoadInt32(&fetches))
+
+	rw = request("bearer " + sign("key-1", key1, claims(nil)))
+	require.Equal(t, http.StatusOK, rw.Code)
+
+	rw = request("")
+	require.Equal(t, http.StatusUnauthorized, rw.Code)
+	require.Equal(t, "Bearer", rw.Header().Get("WWW-Authenticate"))
+
+	rw = request("Bearer invalid")
+	require.Equal(t, http.StatusUnauthorized, rw.Code)
+
+	rw = request("Bearer " + sign("key-1", key1, claims(func(c jwt.MapClaims) {
+		c["exp"] = time.Now().Add(-time.Hour).Unix()
+	})))
+	require.Equal(t, http.StatusUnauthorized, rw.Code)
+
+	rw = request("Bearer " + sign("key-1", key1, claims(func(c jwt.MapClaims) {
+		c["iss"] = "https://other.example.com"
+	})))
+	require.Equal(t, http.StatusUnauthorized, rw.Code)
+
+	rw = request("Bearer " + sign("key-1", key1, claims(func(c jwt.MapClaims) {
+		c["aud"] = "other"
+	})))
+	require.Equal(t, http.StatusUnauthorized, rw.Code)
+
+	// signed by unknown key with a known kid
+	rw = request("Bearer " + sign("key-1", key2, claims(nil)))
+	require.Equal(t, http.StatusUnauthorized, rw.Code)
+
+	// rotated, unknown kid is not refreshed within minimum interval
+	mu.Lock()
+	keys = append(keys, testRSAJWK("key-2", &key2.PublicKey))
+	mu.Unlock()
+
+	rw = request("Bearer " + sign("key-2", key2, claims(nil)))
+	require.Equal(t, http.StatusUnauthorized, rw.Code)
+	require.Equal(t, int32(1), atomic.LoadInt32(&fetches))
+
+	jwks.mu.Lock()
+	jwks.attemptedAt = time.Now().Add(-jwksMinRefreshInterval)
+	jwks.mu.Unlock()
+
+	rw = request("Bearer " + sign("key-2", key2, claims(nil)))
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Equal(t, int32(2), atomic.LoadInt32(&fetches))
+
+	// stale keys are kept if refreshing failed
+	failing.Store(true)
+	jwks.mu.Lock()
+	jwks.fetchedAt = time.Now().Add(-time.Hour)
+	jwks.attemptedAt = time.Now().Add(-time.Hour)
+	jwks.mu.Unlock()
+
+	// refreshed in background, not waited
+	rw = request("Bearer " + sign("key-1", key1, claims(nil)))
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt32(&fetches) == 3
+	}, time.Second, time.Millisecond)
+	require.Error(t, jwks.Refresh(context.Background()))
+
+	rw = request("Bearer " + sign("key-1", key1, claims(nil)))
+	require.Equal(t, http.StatusOK, rw.Code)
+}
+
+func TestJWKSRefreshLimit(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = rw.Write([]byte(`{"keys":[],"padding":"`))
+		_, _ = rw.Write(bytes.Repeat([]byte("a"), jwksMaxBytes))
+		_, _ = rw.Write([]byte(`"}`))
+	}))
+	defer s.Close()
+
+	require.ErrorContains(t, NewJWKS(s.URL, nil, 0).Refresh(context.Background()), "larger than 1048576 bytes")
+}
+
+func TestJWKSKeyfuncConcurrent(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	var fetches int32
+	release := make(chan struct{})
+	s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&fetches, 1)
+		<-release
+		_ = json.NewEncoder(rw).Encode(map[string]any{"keys": []any{testRSAJWK("key-1", &key.PublicKey)}})
+	}))
+	defer s.Close()
+
+	jwks := NewJWKS(s.URL, nil, 0)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			k, err := jwks.Keyfunc(&jwt.Token{Header: map[string]any{"kid": "key-1"}})
+			require.NoError(t, err)
+			require.Equal(t, &key.PublicKey, k)
+		}()
+	}
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt32(&fetches) == 1
+	}, time.Second, time.Millisecond)
+
+	// lock is not held while fetching
+	jwks.mu.RLock()
+	require.Nil(t, jwks.keys)
+	jwks.mu.RUnlock()
+
+	close(release)
+	wg.Wait()
+	require.Equal(t, int32(1), atomic.LoadInt32(&fetches))
+}
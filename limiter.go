@@ -0,0 +1,127 @@
+package summer
+
+import (
+	"context"
+	"github.com/prometheus/client_golang/prometheus"
+	"net/http"
+	"sync/atomic"
+)
+
+// RouteConcurrency concurrency limit of a single route, see [WithRouteConcurrency]
+type RouteConcurrency struct {
+	// Limit maximum concurrent requests of the route, a value <= 0 means unlimited
+	Limit int
+	// Queue maximum requests waiting for a slot of the route, a value <= 0 means rejecting immediately
+	Queue int
+	// RejectStatus status code of rejected requests, defaults to 503, usually 429 or 503
+	RejectStatus int
+}
+
+// routeLimiter concurrency limiter of a single route, or all routes of a [Group]
+type routeLimiter struct {
+	slots    chan struct{}
+	queued   int64
+	maxQueue int64
+	status   int
+
+	mInFlight prometheus.Gauge
+	mQueued   prometheus.Gauge
+}
+
+func newRouteLimiter(rc RouteConcurrency, mInFlight, mQueued prometheus.Gauge) *routeLimiter {
+	if rc.RejectStatus == 0 {
+		rc.RejectStatus = http.StatusServiceUnavailable
+	}
+	return &routeLimiter{
+		slots:     make(chan struct{}, rc.Limit),
+		maxQueue:  int64(rc.Queue),
+		status:    rc.RejectStatus,
+		mInFlight: mInFlight,
+		mQueued:   mQueued,
+	}
+}
+
+// acquire acquire a slot, returns false if queue is full or ctx is done while waiting
+//
+// slot of [WithConcurrency] held by the request, if not nil, is released while waiting in queue, and acquired again after
+func (l *routeLimiter) acquire(ctx context.Context, slot *concurrencySlot) bool {
+	select {
+	case l.slots <- struct{}{}:
+		l.mInFlight.Inc()
+		return true
+	default:
+	}
+
+	if atomic.AddInt64(&l.queued, 1) > l.maxQueue {
+		atomic.AddInt64(&l.queued, -1)
+		return false
+	}
+	l.mQueued.Inc()
+	dequeue := func() {
+		atomic.AddInt64(&l.queued, -1)
+		l.mQueued.Dec()
+	}
+
+	slot.release()
+
+	select {
+	case l.slots <- struct{}{}:
+		dequeue()
+	case <-ctx.Done():
+		dequeue()
+		return false
+	}
+
+	if !slot.acquire(ctx) {
+		<-l.slots
+		return false
+	}
+	l.mInFlight.Inc()
+	return true
+}
+
+// release release a slot acquired by acquire
+func (l *routeLimiter) release() {
+	<-l.slots
+	l.mInFlight.Dec()
+}
+
+type contextKeyConcurrencySlot struct{}
+
+// concurrencySlot a slot of [WithConcurrency] held by a request, released while waiting for a route slot,
+// so requests queued on a route do not starve other routes
+//
+// Only accessed by the goroutine serving the request
+type concurrencySlot struct {
+	cc   chan struct{}
+	held bool
+}
+
+// concurrencySlotFromContext returns [concurrencySlot] of request, nil if not throttled
+func concurrencySlotFromContext(ctx context.Context) *concurrencySlot {
+	slot, _ := ctx.Value(contextKeyConcurrencySlot{}).(*concurrencySlot)
+	return slot
+}
+
+// release release the slot if held, nil safe
+func (s *concurrencySlot) release() {
+	if s == nil || !s.held {
+		return
+	}
+	s.held = false
+	s.cc <- struct{}{}
+}
+
+// acquire acquire the slot again if released, returns false if ctx is done while waiting, nil safe
+func (s *concurrencySlot) acquire(ctx context.Context) bool {
+	if s == nil || s.held {
+		return true
+	}
+	select {
+	case <-s.cc:
+		s.held = true
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,141 @@
+package summer
+
+import (
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWithRouteConcurrency(t *testing.T) {
+	entered := make(chan struct{}, 2)
+	release := make(chan struct{})
+
+	a := Basic()
+	a.HandleFunc("/test-route-concurrency-reject", func(ctx Context) {
+		entered <- struct{}{}
+		<-release
+		ctx.Text("OK")
+	}, WithRouteConcurrency(RouteConcurrency{Limit: 1, RejectStatus: http.StatusTooManyRequests}))
+	a.HandleFunc("/test-route-concurrency-queue", func(ctx Context) {
+		entered <- struct{}{}
+		<-release
+		ctx.Text("OK")
+	}, WithRouteConcurrency(RouteConcurrency{Limit: 1, Queue: 1}))
+
+	var wg sync.WaitGroup
+	codes := make(chan int, 4)
+	request := func(path string) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes <- a.TestRequest("GET", path, nil).Code
+		}()
+	}
+
+	// reject immediately without queue
+	request("/test-route-concurrency-reject")
+	<-entered
+	rw := a.TestRequest("GET", "/test-route-concurrency-reject", nil)
+	require.Equal(t, http.StatusTooManyRequests, rw.Code)
+	require.Equal(t, "OVERLOADED", rw.Body.String())
+	release <- struct{}{}
+	wg.Wait()
+	require.Equal(t, http.StatusOK, <-codes)
+
+	// queue one, reject the next
+	request("/test-route-concurrency-queue")
+	<-entered
+	request("/test-route-concurrency-queue")
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(a.(*app[Context]).mRouteQueueDepth.WithLabelValues("/test-route-concurrency-queue")) == 1
+	}, time.Second, time.Millisecond*10)
+	require.Equal(t, http.StatusServiceUnavailable, a.TestRequest("GET", "/test-route-concurrency-queue", nil).Code)
+	release <- struct{}{}
+	<-entered
+	release <- struct{}{}
+	wg.Wait()
+	require.Equal(t, http.StatusOK, <-codes)
+	require.Equal(t, http.StatusOK, <-codes)
+}
+
+func TestRouteConcurrencyReleasesGlobalSlot(t *testing.T) {
+	entered := make(chan struct{}, 2)
+	release := make(chan struct{})
+
+	a := Basic(WithConcurrency(2))
+	a.HandleFunc("/test-slow", func(ctx Context) {
+		entered <- struct{}{}
+		<-release
+		ctx.Text("OK")
+	}, WithRouteConcurrency(RouteConcurrency{Limit: 1, Queue: 5}))
+	a.HandleFunc("/test-fast", func(ctx Context) {
+		ctx.Text("OK")
+	})
+
+	var wg sync.WaitGroup
+	codes := make(chan int, 3)
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes <- a.TestRequest("GET", "/test-slow", nil).Code
+		}()
+	}
+	<-entered
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(a.(*app[Context]).mRouteQueueDepth.WithLabelValues("/test-slow")) == 2
+	}, time.Second, time.Millisecond)
+
+	// queued requests do not hold global slots
+	require.Equal(t, http.StatusOK, a.TestRequest("GET", "/test-fast", nil).Code)
+
+	for i := 0; i < 3; i++ {
+		release <- struct{}{}
+		if i < 2 {
+			<-entered
+		}
+	}
+	wg.Wait()
+	for i := 0; i < 3; i++ {
+		require.Equal(t, http.StatusOK, <-codes)
+	}
+	require.Len(t, a.(*app[Context]).cc, 2)
+}
+
+func TestGroupConcurrency(t *testing.T) {
+	entered := make(chan struct{}, 2)
+	release := make(chan struct{})
+
+	a := Basic()
+	g := a.Group("/api")
+	g.Concurrency(RouteConcurrency{Limit: 1, RejectStatus: http.StatusTooManyRequests})
+	g.HandleFunc("/a", func(ctx Context) {
+		entered <- struct{}{}
+		<-release
+		ctx.Text("OK")
+	})
+	g.Group("/v1").HandleFunc("/b", func(ctx Context) {
+		ctx.Text("OK")
+	})
+	a.HandleFunc("/other", func(ctx Context) {
+		ctx.Text("OK")
+	})
+
+	done := make(chan int)
+	go func() {
+		done <- a.TestRequest("GET", "/api/a", nil).Code
+	}()
+	<-entered
+	require.Equal(t, float64(1), testutil.ToFloat64(a.(*app[Context]).mRouteInFlight.WithLabelValues("group /api")))
+
+	// shared by routes of group and nested groups
+	require.Equal(t, http.StatusTooManyRequests, a.TestRequest("GET", "/api/v1/b", nil).Code)
+	require.Equal(t, http.StatusOK, a.TestRequest("GET", "/other", nil).Code)
+
+	release <- struct{}{}
+	require.Equal(t, http.StatusOK, <-done)
+	require.Equal(t, http.StatusOK, a.TestRequest("GET", "/api/v1/b", nil).Code)
+}
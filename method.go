@@ -0,0 +1,74 @@
+package summer
+
+import (
+	"net/http"
+	"strings"
+)
+
+// MethodRouter register action functions restricted to a HTTP method
+//
+// Requests with other methods are responded with 405 and an "Allow" header, by [http.ServeMux]
+type MethodRouter[T Context] interface {
+	// GET register an action function for GET (and HEAD) requests with given path pattern
+	GET(pattern string, fn HandlerFunc[T], opts ...Option)
+
+	// POST register an action function for POST requests with given path pattern
+	POST(pattern string, fn HandlerFunc[T], opts ...Option)
+
+	// PUT register an action function for PUT requests with given path pattern
+	PUT(pattern string, fn HandlerFunc[T], opts ...Option)
+
+	// DELETE register an action function for DELETE requests with given path pattern
+	DELETE(pattern string, fn HandlerFunc[T], opts ...Option)
+
+	// PATCH register an action function for PATCH requests with given path pattern
+	PATCH(pattern string, fn HandlerFunc[T], opts ...Option)
+}
+
+// methodPattern prepend method to pattern, pattern should not contain a method
+func methodPattern(method, pattern string) string {
+	if !strings.HasPrefix(pattern, "/") {
+		panic("summer: pattern with method helper should start with '/': " + pattern)
+	}
+	return method + " " + pattern
+}
+
+func (a *app[T]) GET(pattern string, fn HandlerFunc[T], opts ...Option) {
+	a.HandleFunc(methodPattern(http.MethodGet, pattern), fn, opts...)
+}
+
+func (a *app[T]) POST(pattern string, fn HandlerFunc[T], opts ...Option) {
+	a.HandleFunc(methodPattern(http.MethodPost, pattern), fn, opts...)
+}
+
+func (a *app[T]) PUT(pattern string, fn HandlerFunc[T], opts ...Option) {
+	a.HandleFunc(methodPattern(http.MethodPut, pattern), fn, opts...)
+}
+
+func (a *app[T]) DELETE(pattern string, fn HandlerFunc[T], opts ...Option) {
+	a.HandleFunc(methodPattern(http.MethodDelete, pattern), fn, opts...)
+}
+
+func (a *app[T]) PATCH(pattern string, fn HandlerFunc[T], opts ...Option) {
+	a.HandleFunc(methodPattern(http.MethodPatch, pattern), fn, opts...)
+}
+
+func (g *group[T]) GET(pattern string, fn HandlerFunc[T], opts ...Option) {
+	g.HandleFunc(methodPattern(http.MethodGet, pattern), fn, opts...)
+}
+
+func (g *group[T]) POST(pattern string, fn HandlerFunc[T], opts ...Option) {
+	g.HandleFunc(methodPattern(http.MethodPost, pattern), fn, opts...)
+}
+
+func (g *group[T]) PUT(pattern string, fn HandlerFunc[T], opts ...Option) {
+	g.HandleFunc(methodPattern(http.MethodPut, pattern), fn, opts...)
+}
+
+func (g *group[T]) DELETE(pattern string, fn HandlerFunc[T], opts ...Option) {
+	g.HandleFunc(methodPattern(http.MethodDelete, pattern), fn, opts...)
+}
+
+func (g *group[T]) PATCH(pattern string, fn HandlerFunc[T], opts ...Option) {
+	g.HandleFunc(methodPattern(http.MethodPatch, pattern), fn, opts...)
+}
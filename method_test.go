@@ -0,0 +1,57 @@
+package summer
+
+import (
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+)
+
+func TestMethodRouter(t *testing.T) {
+	a := Basic()
+	a.GET("/users/{id}", func(ctx Context) {
+		ctx.Text("get " + ctx.PathParam("id"))
+	})
+	a.DELETE("/users/{id}", func(ctx Context) {
+		ctx.Text("delete " + ctx.PathParam("id"))
+	})
+	g := a.Group("/api")
+	g.POST("/items", func(ctx Context) {
+		ctx.Text("post")
+	})
+	g.PUT("/items", func(ctx Context) {
+		ctx.Text("put")
+	})
+	g.PATCH("/items", func(ctx Context) {
+		ctx.Text("patch")
+	})
+
+	require.Equal(t, []string{
+		"GET /users/{id}",
+		"DELETE /users/{id}",
+		"POST /api/items",
+		"PUT /api/items",
+		"PATCH /api/items",
+	}, a.Patterns())
+
+	rw := a.TestRequest(http.MethodGet, "/users/1", nil)
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Equal(t, "get 1", rw.Body.String())
+
+	rw = a.TestRequest(http.MethodDelete, "/users/2", nil)
+	require.Equal(t, "delete 2", rw.Body.String())
+
+	rw = a.TestRequest(http.MethodPost, "/users/1", nil)
+	require.Equal(t, http.StatusMethodNotAllowed, rw.Code)
+	require.Equal(t, "DELETE, GET, HEAD", rw.Header().Get("Allow"))
+
+	rw = a.TestRequest(http.MethodPatch, "/api/items", nil)
+	require.Equal(t, "patch", rw.Body.String())
+
+	rw = a.TestRequest(http.MethodGet, "/api/items", nil)
+	require.Equal(t, http.StatusMethodNotAllowed, rw.Code)
+	require.Equal(t, "PATCH, POST, PUT", rw.Header().Get("Allow"))
+
+	require.Panics(t, func() {
+		a.GET("GET /dup", func(ctx Context) {})
+	})
+}
@@ -0,0 +1,27 @@
+package summer
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"strconv"
+)
+
+// registerCollector register a collector to r, returns the existing one if already registered
+func registerCollector[C prometheus.Collector](r prometheus.Registerer, c C) C {
+	if err := r.Register(c); err != nil {
+		if are, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			if ec, ok := are.ExistingCollector.(C); ok {
+				return ec
+			}
+		}
+		panic(err)
+	}
+	return c
+}
+
+// statusClass returns class of status code like "2xx"
+func statusClass(code int) string {
+	if code < 100 || code > 599 {
+		return "unknown"
+	}
+	return strconv.Itoa(code/100) + "xx"
+}
@@ -0,0 +1,41 @@
+package summer
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestStatusClass(t *testing.T) {
+	require.Equal(t, "2xx", statusClass(http.StatusOK))
+	require.Equal(t, "4xx", statusClass(http.StatusNotFound))
+	require.Equal(t, "5xx", statusClass(http.StatusGatewayTimeout))
+	require.Equal(t, "unknown", statusClass(0))
+}
+
+func TestWithPrometheusRegistry(t *testing.T) {
+	reg := prometheus.NewRegistry()
+
+	a := Basic(WithPrometheusRegistry(reg))
+	a.HandleFunc("/users/{id}", func(ctx Context) {
+		if ctx.PathParam("id") == "0" {
+			ctx.Code(http.StatusNotFound)
+		}
+		ctx.Text("hello")
+	})
+
+	a.TestRequest("POST", "/users/1", strings.NewReader("world"))
+	a.TestRequest("GET", "/users/2", nil)
+	a.TestRequest("GET", "/users/0", nil)
+
+	body := a.TestRequest("GET", DefaultMetricsPath, nil).Body.String()
+	require.Contains(t, body, `summer_http_requests_total{route="/users/{id}",status_class="2xx"} 2`)
+	require.Contains(t, body, `summer_http_requests_total{route="/users/{id}",status_class="4xx"} 1`)
+	require.Contains(t, body, `summer_http_request_size_bytes_sum{route="/users/{id}"} 5`)
+	require.Contains(t, body, `summer_http_request_size_bytes_count{route="/users/{id}"} 3`)
+	require.Contains(t, body, `summer_http_response_size_bytes_sum{route="/users/{id}",status_class="2xx"} 10`)
+	require.Contains(t, body, `summer_http_in_flight_requests{route="/users/{id}"} 0`)
+	require.NotContains(t, body, "go_goroutines")
+}
@@ -0,0 +1,17 @@
+package summer
+
+// MiddlewareFunc middleware func with [Context] and a continuation as arguments
+//
+// Call next() to continue the chain, skip it to intercept the request
+type MiddlewareFunc[T Context] func(ctx T, next func())
+
+// chainMiddlewares wrap a [HandlerFunc] with middlewares, the first middleware is the outermost
+func chainMiddlewares[T Context](fn HandlerFunc[T], mws []MiddlewareFunc[T]) HandlerFunc[T] {
+	for i := len(mws) - 1; i >= 0; i-- {
+		mw, next := mws[i], fn
+		fn = func(ctx T) {
+			mw(ctx, func() { next(ctx) })
+		}
+	}
+	return fn
+}
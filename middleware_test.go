@@ -0,0 +1,78 @@
+package summer
+
+import (
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestAppUse(t *testing.T) {
+	var calls []string
+
+	a := Basic()
+	a.Use(func(ctx Context, next func()) {
+		calls = append(calls, "first")
+		next()
+	})
+	a.HandleFunc("/test", func(ctx Context) {
+		calls = append(calls, "test")
+		ctx.Text("OK")
+	})
+	a.Group("/api", func(ctx Context, next func()) {
+		calls = append(calls, "api")
+		next()
+	}).HandleFunc("/test", func(ctx Context) {
+		calls = append(calls, "api-test")
+	})
+	a.Use(func(ctx Context, next func()) {
+		calls = append(calls, "second")
+		if ctx.Req().URL.Query().Get("deny") != "" {
+			ctx.Text("DENIED")
+			return
+		}
+		next()
+	})
+
+	require.Equal(t, "OK", a.TestRequest("GET", "/test", nil).Body.String())
+	require.Equal(t, []string{"first", "second", "test"}, calls)
+
+	calls = nil
+	require.Equal(t, "DENIED", a.TestRequest("GET", "/test?deny=1", nil).Body.String())
+	require.Equal(t, []string{"first", "second"}, calls)
+
+	calls = nil
+	a.TestRequest("GET", "/api/test", nil)
+	require.Equal(t, []string{"first", "second", "api", "api-test"}, calls)
+}
+
+func TestAppUseConcurrent(t *testing.T) {
+	var calls int32
+
+	a := Basic()
+	a.HandleFunc("/test", func(ctx Context) {
+		ctx.Text("OK")
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			a.Use(func(ctx Context, next func()) {
+				atomic.AddInt32(&calls, 1)
+				next()
+			})
+		}()
+		go func() {
+			defer wg.Done()
+			require.Equal(t, http.StatusOK, a.TestRequest("GET", "/test", nil).Code)
+		}()
+	}
+	wg.Wait()
+
+	atomic.StoreInt32(&calls, 0)
+	require.Equal(t, http.StatusOK, a.TestRequest("GET", "/test", nil).Code)
+	require.EqualValues(t, 10, atomic.LoadInt32(&calls))
+}
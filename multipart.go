@@ -0,0 +1,39 @@
+package summer
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// isMultipartForm check if body of req is "multipart/form-data"
+func isMultipartForm(req *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	return err == nil && strings.EqualFold(mediaType, ContentTypeMultipartForm)
+}
+
+// parseMultipartForm parse "multipart/form-data" body of req into [http.Request.MultipartForm], with memory limit set by [WithMultipartMemory]
+//
+// Exceeding the limit of [WithMaxBodyBytes] results in [ErrBodyTooLarge], other errors result in 400
+func parseMultipartForm(req *http.Request) error {
+	if req.MultipartForm != nil {
+		return nil
+	}
+	err := req.ParseMultipartForm(optionsFromContext(req.Context()).multipartMemory)
+	if err == nil {
+		return nil
+	}
+	var mbe *http.MaxBytesError
+	if errors.As(err, &mbe) {
+		return ErrBodyTooLarge
+	}
+	return NewHaltError(err, HaltWithStatusCode(http.StatusBadRequest))
+}
+
+// removeMultipartForm remove temporary files of parsed "multipart/form-data" body of req
+func removeMultipartForm(req *http.Request) {
+	if req.MultipartForm != nil {
+		_ = req.MultipartForm.RemoveAll()
+	}
+}
@@ -0,0 +1,94 @@
+package summer
+
+import (
+	"bytes"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestContextFormFile(t *testing.T) {
+	var tempFile string
+
+	a := Basic(WithMaxBodyBytes(4096), WithMultipartMemory(16))
+	a.HandleFunc("/test-upload", func(ctx Context) {
+		args := Bind[struct {
+			Title string   `json:"title"`
+			Tags  []string `json:"tags"`
+			Page  int      `json:"page,string"`
+		}](ctx)
+		fh, err := ctx.FormFile("file")
+		require.NoError(t, err)
+		f, err := fh.Open()
+		require.NoError(t, err)
+		defer f.Close()
+		if osf, ok := f.(*os.File); ok {
+			tempFile = osf.Name()
+		}
+		buf, err := io.ReadAll(f)
+		require.NoError(t, err)
+
+		_, err = ctx.FormFile("missing")
+		require.True(t, errors.Is(err, http.ErrMissingFile))
+
+		ctx.JSON(map[string]any{
+			"title":    args.Title,
+			"tags":     args.Tags,
+			"page":     args.Page,
+			"filename": fh.Filename,
+			"content":  string(buf),
+		})
+	})
+	a.HandleFunc("/test-upload-only", func(ctx Context) {
+		fh, err := ctx.FormFile("file")
+		if err != nil {
+			panic(err)
+		}
+		ctx.Text(fh.Filename)
+	})
+
+	request := func(path string, content string) *httptest.ResponseRecorder {
+		body := &bytes.Buffer{}
+		w := multipart.NewWriter(body)
+		_ = w.WriteField("title", "hello")
+		_ = w.WriteField("tags", "a")
+		_ = w.WriteField("tags", "b")
+		_ = w.WriteField("page", "2")
+		fw, _ := w.CreateFormFile("file", "hello.txt")
+		_, _ = fw.Write([]byte(content))
+		_ = w.Close()
+
+		rw, req := httptest.NewRecorder(), httptest.NewRequest("POST", "https://example.com"+path+"?page=1", body)
+		req.Header.Set("Content-Type", w.FormDataContentType())
+		a.ServeHTTP(rw, req)
+		return rw
+	}
+
+	// file larger than memory limit is stored in a temporary file, removed after response
+	content := strings.Repeat("x", 100)
+	rw := request("/test-upload", content)
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.JSONEq(t, `{"title":"hello","tags":["a","b"],"page":2,"filename":"hello.txt","content":"`+content+`"}`, rw.Body.String())
+	require.NotEmpty(t, tempFile)
+	_, err := os.Stat(tempFile)
+	require.True(t, os.IsNotExist(err))
+
+	rw = request("/test-upload-only", "small")
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Equal(t, "hello.txt", rw.Body.String())
+
+	rw = request("/test-upload", strings.Repeat("x", 8192))
+	require.Equal(t, http.StatusRequestEntityTooLarge, rw.Code)
+
+	// malformed body
+	rw, req := httptest.NewRecorder(), httptest.NewRequest("POST", "https://example.com/test-upload-only", strings.NewReader("invalid"))
+	req.Header.Set("Content-Type", ContentTypeMultipartForm+"; boundary=xxx")
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusBadRequest, rw.Code)
+}
@@ -0,0 +1,407 @@
+package summer
+
+import (
+	"bytes"
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OpenAPIConfig configuration of the OpenAPI document, see [WithOpenAPI]
+type OpenAPIConfig struct {
+	// Title title of API, defaults to "summer"
+	Title string
+	// Version version of API, defaults to "0.0.0"
+	Version string
+	// Description description of API
+	Description string
+	// SwaggerUI serve Swagger UI at [SwaggerUIPath], loaded from a CDN
+	SwaggerUI bool
+	// SwaggerUIVersion exact version of "swagger-ui-dist" loaded from the CDN, defaults to [DefaultSwaggerUIVersion]
+	SwaggerUIVersion string
+	// SwaggerUIStyleIntegrity Subresource Integrity of "swagger-ui.css" of SwaggerUIVersion, like "sha384-...", checked by browsers if set
+	SwaggerUIStyleIntegrity string
+	// SwaggerUIScriptIntegrity Subresource Integrity of "swagger-ui-bundle.js" of SwaggerUIVersion, like "sha384-...", checked by browsers if set
+	SwaggerUIScriptIntegrity string
+}
+
+// DefaultSwaggerUIVersion pinned version of "swagger-ui-dist" for Swagger UI, see [OpenAPIConfig]
+const DefaultSwaggerUIVersion = "5.17.14"
+
+// Operation OpenAPI documentation of a route, see [WithOperation]
+type Operation struct {
+	// ID unique operation id
+	ID string
+	// Summary short summary
+	Summary string
+	// Description verbose description
+	Description string
+	// Tags tags for grouping
+	Tags []string
+	// Deprecated mark as deprecated
+	Deprecated bool
+	// Request a value of request type, usually a struct bound by [Context.Bind] like [Wrap], nil if none
+	//
+	// Fields with "path", "query" or "header" tags are documented as parameters, other fields as JSON body,
+	// or as query parameters for GET and DELETE routes
+	Request any
+	// Response a value of response type rendered with 200, nil if none
+	Response any
+}
+
+var (
+	openAPITimeType       = reflect.TypeOf(time.Time{})
+	openAPIDurationType   = reflect.TypeOf(time.Duration(0))
+	openAPIRawMessageType = reflect.TypeOf(json.RawMessage{})
+
+	openAPIInvalidNameChars = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+	openAPIPathParam        = regexp.MustCompile(`\{([^}]*)}`)
+)
+
+// openAPIField a documented field of struct
+type openAPIField struct {
+	name   string
+	schema map[string]any
+	// parameter name and location, for fields with "path", "query" or "header" tags
+	param string
+	in    string
+}
+
+// openAPIGenerator generate schemas of Go types, named struct types are collected as components
+type openAPIGenerator struct {
+	schemas map[string]any
+	names   map[reflect.Type]string
+}
+
+func newOpenAPIGenerator() *openAPIGenerator {
+	return &openAPIGenerator{schemas: map[string]any{}, names: map[reflect.Type]string{}}
+}
+
+// schemaOf returns schema of t, a reference for named struct types
+func (g *openAPIGenerator) schemaOf(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t {
+	case openAPITimeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case openAPIDurationType:
+		return map[string]any{"type": "integer", "format": "int64"}
+	case openAPIRawMessageType:
+		return map[string]any{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return map[string]any{"type": "integer", "format": "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer", "format": "int64"}
+	case reflect.Float32:
+		return map[string]any{"type": "number", "format": "float"}
+	case reflect.Float64:
+		return map[string]any{"type": "number", "format": "double"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "format": "byte"}
+		}
+		return map[string]any{"type": "array", "items": g.schemaOf(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": g.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t, false)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + g.register(t)}
+	}
+	return map[string]any{}
+}
+
+// register add named struct type t to components, returns name of component
+func (g *openAPIGenerator) register(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+	base := openAPIInvalidNameChars.ReplaceAllString(t.Name(), "_")
+	name := base
+	for i := 2; ; i++ {
+		if _, ok := g.schemas[name]; !ok {
+			break
+		}
+		name = base + strconv.Itoa(i)
+	}
+	g.names[t] = name
+	// placeholder for recursive types
+	g.schemas[name] = map[string]any{}
+	g.schemas[name] = g.structSchema(t, false)
+	return name
+}
+
+// structSchema returns inline schema of struct type t, fields bound by "path", "query" and "header" tags are excluded if body is true
+func (g *openAPIGenerator) structSchema(t reflect.Type, body bool) map[string]any {
+	props := map[string]any{}
+	for _, f := range g.fields(t) {
+		if f.in == "" || !body {
+			props[f.name] = f.schema
+		}
+	}
+	return map[string]any{"type": "object", "properties": props}
+}
+
+// fields returns documented fields of struct type t, embedded structs are flattened
+func (g *openAPIGenerator) fields(t reflect.Type) (fields []openAPIField) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		// promoted like encoding/json, even if embedded type is unexported
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			fields = append(fields, g.fields(field.Type)...)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		f := openAPIField{name: name, schema: g.schemaOf(field.Type)}
+		if f.name == "" {
+			f.name = field.Name
+		}
+		if strings.Contains(","+opts+",", ",string,") {
+			f.schema = map[string]any{"type": "string"}
+		}
+		for _, in := range bindTags {
+			if v, ok := field.Tag.Lookup(in); ok && v != "" && v != "-" {
+				f.param, f.in = v, in
+				break
+			}
+		}
+		fields = append(fields, f)
+	}
+	return
+}
+
+// operation returns OpenAPI operation object of a route
+func (g *openAPIGenerator) operation(method string, path string, op *Operation) map[string]any {
+	o := map[string]any{}
+	var params []any
+	seen := map[string]bool{}
+
+	addParam := func(name, in string, schema map[string]any) {
+		if seen[in+":"+name] {
+			return
+		}
+		seen[in+":"+name] = true
+		param := map[string]any{"name": name, "in": in, "schema": schema}
+		if in == "path" {
+			param["required"] = true
+		}
+		params = append(params, param)
+	}
+
+	responses := map[string]any{
+		"default": map[string]any{
+			"description": "Error",
+			"content": map[string]any{
+				ContentTypeApplicationJSON: map[string]any{"schema": map[string]any{"$ref": "#/components/schemas/Error"}},
+			},
+		},
+	}
+	ok := map[string]any{"description": http.StatusText(http.StatusOK)}
+	responses["200"] = ok
+	o["responses"] = responses
+
+	if op != nil {
+		if op.ID != "" {
+			o["operationId"] = op.ID
+		}
+		if op.Summary != "" {
+			o["summary"] = op.Summary
+		}
+		if op.Description != "" {
+			o["description"] = op.Description
+		}
+		if len(op.Tags) > 0 {
+			o["tags"] = op.Tags
+		}
+		if op.Deprecated {
+			o["deprecated"] = true
+		}
+		if op.Response != nil {
+			ok["content"] = map[string]any{
+				ContentTypeApplicationJSON: map[string]any{"schema": g.schemaOf(reflect.TypeOf(op.Response))},
+			}
+		}
+		if op.Request != nil {
+			t := reflect.TypeOf(op.Request)
+			for t.Kind() == reflect.Pointer {
+				t = t.Elem()
+			}
+			if t.Kind() == reflect.Struct {
+				hasBody := method != http.MethodGet && method != http.MethodDelete
+				for _, f := range g.fields(t) {
+					if f.in != "" {
+						addParam(f.param, f.in, f.schema)
+					} else if !hasBody {
+						addParam(f.name, "query", f.schema)
+					}
+				}
+				if hasBody {
+					o["requestBody"] = map[string]any{
+						"content": map[string]any{
+							ContentTypeApplicationJSON: map[string]any{"schema": g.structSchema(t, true)},
+						},
+					}
+				}
+			} else {
+				o["requestBody"] = map[string]any{
+					"content": map[string]any{
+						ContentTypeApplicationJSON: map[string]any{"schema": g.schemaOf(t)},
+					},
+				}
+			}
+		}
+	}
+
+	// wildcards of pattern not documented by request type
+	for _, m := range openAPIPathParam.FindAllStringSubmatch(path, -1) {
+		addParam(m[1], "path", map[string]any{"type": "string"})
+	}
+
+	if len(params) > 0 {
+		o["parameters"] = params
+	}
+	return o
+}
+
+// openAPIPath convert path of pattern to OpenAPI path, returns false if path is not documentable
+func openAPIPath(p string) (string, bool) {
+	// host
+	i := strings.Index(p, "/")
+	if i < 0 {
+		return "", false
+	}
+	p = p[i:]
+	p = strings.TrimSuffix(p, "{$}")
+	p = strings.ReplaceAll(p, "...}", "}")
+	return p, true
+}
+
+func (a *app[T]) OpenAPI() map[string]any {
+	cfg := OpenAPIConfig{}
+	if a.opts.openAPI != nil {
+		cfg = *a.opts.openAPI
+	}
+	if cfg.Title == "" {
+		cfg.Title = "summer"
+	}
+	if cfg.Version == "" {
+		cfg.Version = "0.0.0"
+	}
+
+	info := map[string]any{"title": cfg.Title, "version": cfg.Version}
+	if cfg.Description != "" {
+		info["description"] = cfg.Description
+	}
+
+	g := newOpenAPIGenerator()
+	g.schemas["Error"] = map[string]any{
+		"type":       "object",
+		"properties": map[string]any{HaltExtraKeyMessage: map[string]any{"type": "string"}},
+	}
+
+	paths := map[string]any{}
+
+	a.routesMu.RLock()
+	for i, pattern := range a.patterns {
+		r := a.routes[pattern]
+		if r.deregistered.Load() {
+			continue
+		}
+		entry := newRouteEntry(pattern, i)
+		if entry.Method == "" || entry.Method == http.MethodHead {
+			continue
+		}
+		p, ok := openAPIPath(entry.Path)
+		if !ok {
+			continue
+		}
+		item, _ := paths[p].(map[string]any)
+		if item == nil {
+			item = map[string]any{}
+			paths[p] = item
+		}
+		item[strings.ToLower(entry.Method)] = g.operation(entry.Method, p, r.operation.Load())
+	}
+	a.routesMu.RUnlock()
+
+	doc := map[string]any{
+		"openapi":    "3.1.0",
+		"info":       info,
+		"paths":      paths,
+		"components": map[string]any{"schemas": g.schemas},
+	}
+	if a.opts.pathPrefix != "" {
+		doc["servers"] = []any{map[string]any{"url": a.opts.pathPrefix}}
+	}
+	return doc
+}
+
+// serveOpenAPI serve the OpenAPI document
+func (a *app[T]) serveOpenAPI(rw http.ResponseWriter, req *http.Request) {
+	respondInternalJSON(rw, a.OpenAPI(), http.StatusOK)
+}
+
+// swaggerUITemplate page of Swagger UI, assets loaded from a CDN with pinned version and optional Subresource Integrity
+var swaggerUITemplate = template.Must(template.New("swagger").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Swagger UI</title>
+<link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@{{.Version}}/swagger-ui.css"{{with .StyleIntegrity}} integrity="{{.}}"{{end}} crossorigin="anonymous">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="https://unpkg.com/swagger-ui-dist@{{.Version}}/swagger-ui-bundle.js"{{with .ScriptIntegrity}} integrity="{{.}}"{{end}} crossorigin="anonymous"></script>
+<script>
+window.ui = SwaggerUIBundle({url: "openapi.json", dom_id: "#swagger-ui"});
+</script>
+</body>
+</html>
+`))
+
+// serveSwaggerUI serve Swagger UI of the OpenAPI document
+func (a *app[T]) serveSwaggerUI(rw http.ResponseWriter, req *http.Request) {
+	data := struct {
+		Version         string
+		StyleIntegrity  string
+		ScriptIntegrity string
+	}{
+		Version:         a.opts.openAPI.SwaggerUIVersion,
+		StyleIntegrity:  a.opts.openAPI.SwaggerUIStyleIntegrity,
+		ScriptIntegrity: a.opts.openAPI.SwaggerUIScriptIntegrity,
+	}
+	if data.Version == "" {
+		data.Version = DefaultSwaggerUIVersion
+	}
+	buf := &bytes.Buffer{}
+	if err := swaggerUITemplate.Execute(buf, data); err != nil {
+		respondInternal(rw, "INTERNAL SERVER ERROR", http.StatusInternalServerError)
+		return
+	}
+	rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+	rw.WriteHeader(http.StatusOK)
+	_, _ = rw.Write(buf.Bytes())
+}
@@ -0,0 +1,162 @@
+package summer
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+	"time"
+)
+
+type testOpenAPIAddress struct {
+	City string `json:"city"`
+}
+
+type testOpenAPIUser struct {
+	ID        string              `json:"id"`
+	Name      string              `json:"name,omitempty"`
+	Age       int                 `json:"age,string"`
+	Tags      []string            `json:"tags"`
+	Avatar    []byte              `json:"avatar"`
+	Address   *testOpenAPIAddress `json:"address"`
+	Friends   []testOpenAPIUser   `json:"friends"`
+	CreatedAt time.Time           `json:"created_at"`
+	Extra     map[string]float64  `json:"extra"`
+	Secret    string              `json:"-"`
+	internal  string
+}
+
+type testOpenAPIPage struct {
+	Offset int `json:"offset"`
+}
+
+type testOpenAPIRequest struct {
+	testOpenAPIPage
+	ID     string `path:"id"`
+	Tenant string `header:"X-Tenant"`
+	Dry    bool   `query:"dry"`
+	Name   string `json:"name"`
+}
+
+func TestAppOpenAPI(t *testing.T) {
+	a := Basic(WithOpenAPI(OpenAPIConfig{Title: "test", Version: "1.0.0", SwaggerUI: true}))
+	// types filled from Wrap
+	a.PUT("/users/{id}", Wrap(func(ctx Context, req testOpenAPIRequest) (testOpenAPIUser, error) {
+		return testOpenAPIUser{}, nil
+	}), WithOperation(Operation{
+		ID:      "updateUser",
+		Summary: "update a user",
+		Tags:    []string{"users"},
+	}))
+	a.GET("/users/{id}", func(ctx Context) {}, WithOperation(Operation{
+		Request:    &testOpenAPIRequest{},
+		Deprecated: true,
+	}))
+	a.GET("/files/{path...}", func(ctx Context) {})
+	a.GET("/{$}", func(ctx Context) {})
+	a.GET("/removed", func(ctx Context) {})
+	a.HandleFunc("/any", func(ctx Context) {})
+	require.NoError(t, a.DeregisterRoute("GET /removed"))
+
+	res := a.TestRequest("GET", OpenAPIPath, nil)
+	require.Equal(t, http.StatusOK, res.Code)
+
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(res.Body.Bytes(), &doc))
+	require.Equal(t, "3.1.0", doc["openapi"])
+	require.Equal(t, map[string]any{"title": "test", "version": "1.0.0"}, doc["info"])
+
+	paths := doc["paths"].(map[string]any)
+	require.Len(t, paths, 3)
+	require.Contains(t, paths, "/")
+	require.Equal(t, []any{
+		map[string]any{"name": "path", "in": "path", "required": true, "schema": map[string]any{"type": "string"}},
+	}, paths["/files/{path}"].(map[string]any)["get"].(map[string]any)["parameters"])
+
+	user := paths["/users/{id}"].(map[string]any)
+
+	put := user["put"].(map[string]any)
+	require.Equal(t, "updateUser", put["operationId"])
+	require.Equal(t, "update a user", put["summary"])
+	require.Equal(t, []any{"users"}, put["tags"])
+	require.Equal(t, []any{
+		map[string]any{"name": "id", "in": "path", "required": true, "schema": map[string]any{"type": "string"}},
+		map[string]any{"name": "X-Tenant", "in": "header", "schema": map[string]any{"type": "string"}},
+		map[string]any{"name": "dry", "in": "query", "schema": map[string]any{"type": "boolean"}},
+	}, put["parameters"])
+	require.Equal(t, map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"offset": map[string]any{"type": "integer", "format": "int64"},
+			"name":   map[string]any{"type": "string"},
+		},
+	}, put["requestBody"].(map[string]any)["content"].(map[string]any)[ContentTypeApplicationJSON].(map[string]any)["schema"])
+	responses := put["responses"].(map[string]any)
+	require.Equal(t, map[string]any{"$ref": "#/components/schemas/testOpenAPIUser"},
+		responses["200"].(map[string]any)["content"].(map[string]any)[ContentTypeApplicationJSON].(map[string]any)["schema"])
+	require.Contains(t, responses, "default")
+
+	get := user["get"].(map[string]any)
+	require.Equal(t, true, get["deprecated"])
+	require.NotContains(t, get, "requestBody")
+	require.Len(t, get["parameters"], 5)
+	require.Equal(t, map[string]any{"description": "OK"}, get["responses"].(map[string]any)["200"])
+
+	schemas := doc["components"].(map[string]any)["schemas"].(map[string]any)
+	require.Contains(t, schemas, "Error")
+	require.Equal(t, map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"id":         map[string]any{"type": "string"},
+			"name":       map[string]any{"type": "string"},
+			"age":        map[string]any{"type": "string"},
+			"tags":       map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+			"avatar":     map[string]any{"type": "string", "format": "byte"},
+			"address":    map[string]any{"$ref": "#/components/schemas/testOpenAPIAddress"},
+			"friends":    map[string]any{"type": "array", "items": map[string]any{"$ref": "#/components/schemas/testOpenAPIUser"}},
+			"created_at": map[string]any{"type": "string", "format": "date-time"},
+			"extra":      map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "number", "format": "double"}},
+		},
+	}, schemas["testOpenAPIUser"])
+	require.Contains(t, schemas, "testOpenAPIAddress")
+
+	res = a.TestRequest("GET", SwaggerUIPath, nil)
+	require.Equal(t, http.StatusOK, res.Code)
+	require.Contains(t, res.Body.String(), `url: "openapi.json"`)
+	require.Contains(t, res.Body.String(), `https://unpkg.com/swagger-ui-dist@`+DefaultSwaggerUIVersion+`/swagger-ui-bundle.js" crossorigin="anonymous"`)
+
+	c := Basic(WithOpenAPI(OpenAPIConfig{
+		SwaggerUI:                true,
+		SwaggerUIVersion:         "5.0.0",
+		SwaggerUIStyleIntegrity:  "sha384-style",
+		SwaggerUIScriptIntegrity: "sha384-script",
+	}))
+	res = c.TestRequest("GET", SwaggerUIPath, nil)
+	require.Contains(t, res.Body.String(), `https://unpkg.com/swagger-ui-dist@5.0.0/swagger-ui.css" integrity="sha384-style" crossorigin="anonymous"`)
+	require.Contains(t, res.Body.String(), `https://unpkg.com/swagger-ui-dist@5.0.0/swagger-ui-bundle.js" integrity="sha384-script" crossorigin="anonymous"`)
+
+	// not served unless enabled
+	b := Basic()
+	b.GET("/hello", func(ctx Context) {})
+	require.Equal(t, http.StatusNotFound, b.TestRequest("GET", OpenAPIPath, nil).Code)
+	require.Contains(t, b.OpenAPI()["paths"], "/hello")
+}
+
+func TestWrapOperation(t *testing.T) {
+	a := Basic()
+	h := Wrap(func(ctx Context, req testOpenAPIRequest) (*testOpenAPIUser, error) {
+		return nil, nil
+	})
+	a.POST("/users/{id}", h)
+	a.Group("/v2").POST("/users/{id}", h)
+	a.POST("/plain", func(ctx Context) {})
+
+	paths := a.OpenAPI()["paths"].(map[string]any)
+	for _, p := range []string{"/users/{id}", "/v2/users/{id}"} {
+		post := paths[p].(map[string]any)["post"].(map[string]any)
+		require.Contains(t, post, "requestBody", p)
+		require.Equal(t, map[string]any{"$ref": "#/components/schemas/testOpenAPIUser"},
+			post["responses"].(map[string]any)["200"].(map[string]any)["content"].(map[string]any)[ContentTypeApplicationJSON].(map[string]any)["schema"], p)
+	}
+	require.NotContains(t, paths["/plain"].(map[string]any)["post"], "requestBody")
+}
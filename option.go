@@ -1,14 +1,528 @@
 package summer
 
+import (
+	"context"
+	"github.com/gorilla/websocket"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
 type options struct {
 	concurrency      int
+	maxQueueDepth    int
 	readinessCascade int64
+	readinessWindow  time.Duration
 	readinessPath    string
 	livenessPath     string
+	startupPath      string
 	metricsPath      string
+	optionsEndpoint  bool
+	csvComma         rune
+	csvComment       rune
+	accessLogger     func(entry AccessLogEntry)
+	accessLog        bool
+	durationBuckets  []float64
+	promRegistry     *prometheus.Registry
+	maxConnections   int
+	serverTimeouts   ServerTimeouts
+	maxURLLength     int
+	getDeduplication bool
+	trustedProxies   []*net.IPNet
+	logger           *slog.Logger
+	debugAuths       []debugAuth
+	startupLog       bool
+	recoveryHandler  RecoveryHandler
+	baseContext      func(l net.Listener) context.Context
+	strictSlash      bool
+	buildInfo        map[string]string
+	trailingNewline  bool
+	maxBodyBytes     int64
+
+	requestDecompression bool
+	multipartMemory      int64
+
+	bindHeaderPrefix    string
+	bindQueryPrefix     string
+	bindCollisionPolicy CollisionPolicy
+
+	checkHTTPDialer *net.Dialer
+	checkTimeout    time.Duration
+
+	duplicateRoutePolicy DuplicateRoutePolicy
+	concurrencyWatermark float64
+
+	requiredAccept []string
+	requestTimeout time.Duration
+
+	requestTimeoutHeader string
+
+	ipAllowlists []ipAllowlist
+
+	otelSpanKind trace.SpanKind
+	otelDisabled bool
+	otelOptions  []otelhttp.Option
+
+	traceIDHeader   string
+	requestIDHeader string
+
+	pathPrefix string
+
+	versionInfo *VersionInfo
+	versionPath string
+
+	cleanIdleInterval time.Duration
+
+	heartbeatInterval time.Duration
+	heartbeatLogger   *slog.Logger
+
+	shutdownSignals []os.Signal
+	drainPeriod     time.Duration
+	shutdownTimeout time.Duration
+
+	validator Validator
+
+	webSocketUpgrader *websocket.Upgrader
+
+	sseHeartbeat time.Duration
+
+	grpcHandler http.Handler
+	h2c         bool
+
+	tlsCertFile           string
+	tlsKeyFile            string
+	tlsClientCAFile       string
+	tlsClientCertRequired bool
+
+	routeConcurrency RouteConcurrency
+	groupLimiters    []*routeLimiter
+	rateLimit        RateLimit
+	routeMiddlewares bool
+
+	cors *CORSConfig
+
+	sessions *sessionManager
+
+	configs []configEntry
+
+	workerBackoff WorkerBackoff
+
+	openAPI   *OpenAPIConfig
+	operation *Operation
+
+	compression *Compression
+
+	checkOutputFormat CheckOutputFormat
+
+	jsonSchema *jsonschema.Schema
+
+	extraDebugServers []extraDebugServer
+	debugAddr         string
+}
+
+// ServerTimeouts timeouts of [http.Server] created by [App.Run]
+type ServerTimeouts struct {
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+}
+
+// CheckOutputFormat format of readiness check output
+type CheckOutputFormat struct {
+	// LineSeparator separator between checks, defaults to "\n"
+	LineSeparator string
+	// NameSeparator separator between name and result of a check, defaults to ": "
+	NameSeparator string
+	// OKText result of a successful check, defaults to "OK"
+	OKText string
+}
+
+type ipAllowlist struct {
+	methods map[string]struct{}
+	cidrs   []*net.IPNet
+}
+
+type debugAuth struct {
+	glob string
+	fn   func(req *http.Request) bool
+}
+
+type extraDebugServer struct {
+	addr  string
+	paths []string
+}
+
+func defaultOptions() options {
+	return options{
+		concurrency:      128,
+		readinessCascade: 5,
+		readinessPath:    DefaultReadinessPath,
+		livenessPath:     DefaultLivenessPath,
+		startupPath:      DefaultStartupPath,
+		metricsPath:      DefaultMetricsPath,
+		csvComma:         ',',
+		durationBuckets:  prometheus.DefBuckets,
+		maxURLLength:     8192,
+		multipartMemory:  32 << 20,
+		workerBackoff:    WorkerBackoff{Min: time.Second, Max: time.Minute},
+		bindHeaderPrefix: "header_",
+		bindQueryPrefix:  "query_",
+		logger:           slog.Default(),
+		startupLog:       true,
+		serverTimeouts: ServerTimeouts{
+			ReadHeaderTimeout: time.Second * 10,
+			IdleTimeout:       time.Minute * 2,
+		},
+		concurrencyWatermark: 0.1,
+		otelSpanKind:         trace.SpanKindServer,
+		versionPath:          DefaultVersionPath,
+		shutdownTimeout:      time.Second * 30,
+		traceIDHeader:        "X-Trace-Id",
+		sseHeartbeat:         time.Second * 15,
+		requestIDHeader:      "X-Request-Id",
+		checkOutputFormat: CheckOutputFormat{
+			LineSeparator: "\n",
+			NameSeparator: ": ",
+			OKText:        "OK",
+		},
+	}
+}
+
+// isTrustedProxy check if request comes directly from a trusted proxy
+func (opts *options) isTrustedProxy(req *http.Request) bool {
+	ip := net.ParseIP(normalizeIP(req.RemoteAddr))
+	if ip == nil {
+		return false
+	}
+	for _, cidr := range opts.trustedProxies {
+		if cidr.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientIP returns client ip of request, forwarded headers are honored only for requests from trusted proxies
+func (opts *options) clientIP(req *http.Request) string {
+	if opts.isTrustedProxy(req) {
+		return extractClientIP(req)
+	}
+	return normalizeIP(req.RemoteAddr)
+}
+
+// isIPAllowed check if client ip of request is allowed by [WithIPAllowlist]
+func (opts *options) isIPAllowed(req *http.Request) bool {
+	var ip net.IP
+	for _, item := range opts.ipAllowlists {
+		if _, ok := item.methods[req.Method]; !ok {
+			continue
+		}
+		if ip == nil {
+			if ip = net.ParseIP(opts.clientIP(req)); ip == nil {
+				return false
+			}
+		}
+		var allowed bool
+		for _, cidr := range item.cidrs {
+			if cidr.Contains(ip) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return false
+		}
+	}
+	return true
+}
+
+type contextKeyOptions struct{}
+
+// optionsFromContext returns options of current route carried by ctx, or default options
+func optionsFromContext(ctx context.Context) *options {
+	if opts, ok := ctx.Value(contextKeyOptions{}).(*options); ok {
+		return opts
+	}
+	opts := defaultOptions()
+	return &opts
+}
+
+// OptionsInfo a snapshot of configured options of [App]
+//
+// Sensitive values, like credentials and private key paths, must be redacted.
+// Functions and handlers are reported as whether they are set, so are paths of TLS files
+type OptionsInfo struct {
+	Concurrency          int     `json:"concurrency"`
+	MaxQueueDepth        int     `json:"max_queue_depth"`
+	ConcurrencyWatermark float64 `json:"concurrency_watermark"`
+	RouteConcurrency     int     `json:"route_concurrency"`
+	RouteQueue           int     `json:"route_queue"`
+	RouteRejectStatus    int     `json:"route_reject_status"`
+	MaxConnections       int     `json:"max_connections"`
+
+	ReadinessCascade   int64         `json:"readiness_cascade"`
+	ReadinessWindow    time.Duration `json:"readiness_window"`
+	ReadinessPath      string        `json:"readiness_path"`
+	LivenessPath       string        `json:"liveness_path"`
+	StartupPath        string        `json:"startup_path"`
+	MetricsPath        string        `json:"metrics_path"`
+	VersionPath        string        `json:"version_path"`
+	OptionsEndpoint    bool          `json:"options_endpoint"`
+	CheckTimeout       time.Duration `json:"check_timeout"`
+	CheckLineSeparator string        `json:"check_line_separator"`
+	CheckNameSeparator string        `json:"check_name_separator"`
+	CheckOKText        string        `json:"check_ok_text"`
+
+	ReadTimeout       time.Duration `json:"read_timeout"`
+	ReadHeaderTimeout time.Duration `json:"read_header_timeout"`
+	WriteTimeout      time.Duration `json:"write_timeout"`
+	IdleTimeout       time.Duration `json:"idle_timeout"`
+	CleanIdleInterval time.Duration `json:"clean_idle_interval"`
+
+	RequestTimeout       time.Duration `json:"request_timeout"`
+	RequestTimeoutHeader string        `json:"request_timeout_header"`
+	MaxURLLength         int           `json:"max_url_length"`
+	MaxBodyBytes         int64         `json:"max_body_bytes"`
+	RequestDecompression bool          `json:"request_decompression"`
+	MultipartMemory      int64         `json:"multipart_memory"`
+	RequiredAccept       []string      `json:"required_accept"`
+	JSONSchema           bool          `json:"json_schema"`
+	Validator            bool          `json:"validator"`
+
+	CSVComma            string          `json:"csv_comma"`
+	CSVComment          string          `json:"csv_comment"`
+	BindHeaderPrefix    string          `json:"bind_header_prefix"`
+	BindQueryPrefix     string          `json:"bind_query_prefix"`
+	BindCollisionPolicy CollisionPolicy `json:"bind_collision_policy"`
+
+	PathPrefix           string               `json:"path_prefix"`
+	StrictSlash          bool                 `json:"strict_slash"`
+	DuplicateRoutePolicy DuplicateRoutePolicy `json:"duplicate_route_policy"`
+	TrustedProxies       []string             `json:"trusted_proxies"`
+	IPAllowlists         []IPAllowlistInfo    `json:"ip_allowlists"`
+	DebugAuths           []string             `json:"debug_auths"`
+	DebugAddr            string               `json:"debug_addr"`
+	ExtraDebugServers    []string             `json:"extra_debug_servers"`
+
+	GetDeduplication        bool          `json:"get_deduplication"`
+	RateLimit               RateLimitInfo `json:"rate_limit"`
+	CORS                    *CORSInfo     `json:"cors"`
+	Compression             bool          `json:"compression"`
+	CompressionMinSize      int           `json:"compression_min_size"`
+	CompressionContentTypes []string      `json:"compression_content_types"`
+	CompressionEncodings    []string      `json:"compression_encodings"`
+	Sessions                bool          `json:"sessions"`
+	Configs                 int           `json:"configs"`
+	OpenAPI                 bool          `json:"openapi"`
+
+	TLS                   bool          `json:"tls"`
+	TLSClientCA           bool          `json:"tls_client_ca"`
+	TLSClientCertRequired bool          `json:"tls_client_cert_required"`
+	H2C                   bool          `json:"h2c"`
+	GRPC                  bool          `json:"grpc"`
+	WebSocketUpgrader     bool          `json:"websocket_upgrader"`
+	SSEHeartbeat          time.Duration `json:"sse_heartbeat"`
+
+	AccessLog       bool              `json:"access_log"`
+	AccessLogger    bool              `json:"access_logger"`
+	StartupLog      bool              `json:"startup_log"`
+	RecoveryHandler bool              `json:"recovery_handler"`
+	TrailingNewline bool              `json:"trailing_newline"`
+	DurationBuckets []float64         `json:"duration_buckets"`
+	BuildInfo       map[string]string `json:"build_info"`
+	VersionInfo     *VersionInfo      `json:"version_info"`
+	TraceIDHeader   string            `json:"trace_id_header"`
+	RequestIDHeader string            `json:"request_id_header"`
+	OTelSpanKind    string            `json:"otel_span_kind"`
+	OTelDisabled    bool              `json:"otel_disabled"`
+
+	HeartbeatInterval time.Duration `json:"heartbeat_interval"`
+	WorkerBackoffMin  time.Duration `json:"worker_backoff_min"`
+	WorkerBackoffMax  time.Duration `json:"worker_backoff_max"`
+	ShutdownSignals   []string      `json:"shutdown_signals"`
+	DrainPeriod       time.Duration `json:"drain_period"`
+	ShutdownTimeout   time.Duration `json:"shutdown_timeout"`
+}
+
+// RateLimitInfo snapshot of [RateLimit] in [OptionsInfo]
+type RateLimitInfo struct {
+	Limit    int               `json:"limit"`
+	Period   time.Duration     `json:"period"`
+	Burst    int               `json:"burst"`
+	Strategy RateLimitStrategy `json:"strategy"`
+	Key      bool              `json:"key"`
+	Store    bool              `json:"store"`
+}
+
+// CORSInfo snapshot of [CORSConfig] in [OptionsInfo]
+type CORSInfo struct {
+	AllowOrigins     []string      `json:"allow_origins"`
+	AllowOriginFunc  bool          `json:"allow_origin_func"`
+	AllowMethods     []string      `json:"allow_methods"`
+	AllowHeaders     []string      `json:"allow_headers"`
+	ExposeHeaders    []string      `json:"expose_headers"`
+	AllowCredentials bool          `json:"allow_credentials"`
+	MaxAge           time.Duration `json:"max_age"`
+}
+
+// IPAllowlistInfo snapshot of an allowlist of [WithIPAllowlist] in [OptionsInfo]
+type IPAllowlistInfo struct {
+	Methods []string `json:"methods"`
+	CIDRs   []string `json:"cidrs"`
+}
+
+func (opts options) info() OptionsInfo {
+	info := OptionsInfo{
+		Concurrency:          opts.concurrency,
+		MaxQueueDepth:        opts.maxQueueDepth,
+		ConcurrencyWatermark: opts.concurrencyWatermark,
+		RouteConcurrency:     opts.routeConcurrency.Limit,
+		RouteQueue:           opts.routeConcurrency.Queue,
+		RouteRejectStatus:    opts.routeConcurrency.RejectStatus,
+		MaxConnections:       opts.maxConnections,
+
+		ReadinessCascade:   opts.readinessCascade,
+		ReadinessWindow:    opts.readinessWindow,
+		ReadinessPath:      opts.readinessPath,
+		LivenessPath:       opts.livenessPath,
+		StartupPath:        opts.startupPath,
+		MetricsPath:        opts.metricsPath,
+		VersionPath:        opts.versionPath,
+		OptionsEndpoint:    opts.optionsEndpoint,
+		CheckTimeout:       opts.checkTimeout,
+		CheckLineSeparator: opts.checkOutputFormat.LineSeparator,
+		CheckNameSeparator: opts.checkOutputFormat.NameSeparator,
+		CheckOKText:        opts.checkOutputFormat.OKText,
+
+		ReadTimeout:       opts.serverTimeouts.ReadTimeout,
+		ReadHeaderTimeout: opts.serverTimeouts.ReadHeaderTimeout,
+		WriteTimeout:      opts.serverTimeouts.WriteTimeout,
+		IdleTimeout:       opts.serverTimeouts.IdleTimeout,
+		CleanIdleInterval: opts.cleanIdleInterval,
+
+		RequestTimeout:       opts.requestTimeout,
+		RequestTimeoutHeader: opts.requestTimeoutHeader,
+		MaxURLLength:         opts.maxURLLength,
+		MaxBodyBytes:         opts.maxBodyBytes,
+		RequestDecompression: opts.requestDecompression,
+		MultipartMemory:      opts.multipartMemory,
+		RequiredAccept:       opts.requiredAccept,
+		JSONSchema:           opts.jsonSchema != nil,
+		Validator:            opts.validator != nil,
+
+		BindHeaderPrefix:    opts.bindHeaderPrefix,
+		BindQueryPrefix:     opts.bindQueryPrefix,
+		BindCollisionPolicy: opts.bindCollisionPolicy,
+
+		PathPrefix:           opts.pathPrefix,
+		StrictSlash:          opts.strictSlash,
+		DuplicateRoutePolicy: opts.duplicateRoutePolicy,
+		DebugAddr:            opts.debugAddr,
+
+		GetDeduplication: opts.getDeduplication,
+		RateLimit: RateLimitInfo{
+			Limit:    opts.rateLimit.Limit,
+			Period:   opts.rateLimit.Period,
+			Burst:    opts.rateLimit.Burst,
+			Strategy: opts.rateLimit.Strategy,
+			Key:      opts.rateLimit.Key != nil,
+			Store:    opts.rateLimit.Store != nil,
+		},
+		Sessions: opts.sessions != nil,
+		Configs:  len(opts.configs),
+		OpenAPI:  opts.openAPI != nil,
+
+		TLS:                   opts.tlsCertFile != "",
+		TLSClientCA:           opts.tlsClientCAFile != "",
+		TLSClientCertRequired: opts.tlsClientCertRequired,
+		H2C:                   opts.h2c,
+		GRPC:                  opts.grpcHandler != nil,
+		WebSocketUpgrader:     opts.webSocketUpgrader != nil,
+		SSEHeartbeat:          opts.sseHeartbeat,
+
+		AccessLog:       opts.accessLog,
+		AccessLogger:    opts.accessLogger != nil,
+		StartupLog:      opts.startupLog,
+		RecoveryHandler: opts.recoveryHandler != nil,
+		TrailingNewline: opts.trailingNewline,
+		DurationBuckets: opts.durationBuckets,
+		BuildInfo:       opts.buildInfo,
+		VersionInfo:     opts.versionInfo,
+		TraceIDHeader:   opts.traceIDHeader,
+		RequestIDHeader: opts.requestIDHeader,
+		OTelSpanKind:    opts.otelSpanKind.String(),
+		OTelDisabled:    opts.otelDisabled,
+
+		HeartbeatInterval: opts.heartbeatInterval,
+		WorkerBackoffMin:  opts.workerBackoff.Min,
+		WorkerBackoffMax:  opts.workerBackoff.Max,
+		DrainPeriod:       opts.drainPeriod,
+		ShutdownTimeout:   opts.shutdownTimeout,
+	}
+	if opts.csvComma != 0 {
+		info.CSVComma = string(opts.csvComma)
+	}
+	if opts.csvComment != 0 {
+		info.CSVComment = string(opts.csvComment)
+	}
+	for _, cidr := range opts.trustedProxies {
+		info.TrustedProxies = append(info.TrustedProxies, cidr.String())
+	}
+	for _, item := range opts.ipAllowlists {
+		al := IPAllowlistInfo{}
+		for method := range item.methods {
+			al.Methods = append(al.Methods, method)
+		}
+		sort.Strings(al.Methods)
+		for _, cidr := range item.cidrs {
+			al.CIDRs = append(al.CIDRs, cidr.String())
+		}
+		info.IPAllowlists = append(info.IPAllowlists, al)
+	}
+	for _, item := range opts.debugAuths {
+		info.DebugAuths = append(info.DebugAuths, item.glob)
+	}
+	for _, item := range opts.extraDebugServers {
+		info.ExtraDebugServers = append(info.ExtraDebugServers, item.addr)
+	}
+	if c := opts.cors; c != nil {
+		info.CORS = &CORSInfo{
+			AllowOrigins:     c.AllowOrigins,
+			AllowOriginFunc:  c.AllowOriginFunc != nil,
+			AllowMethods:     c.AllowMethods,
+			AllowHeaders:     c.AllowHeaders,
+			ExposeHeaders:    c.ExposeHeaders,
+			AllowCredentials: c.AllowCredentials,
+			MaxAge:           c.MaxAge,
+		}
+	}
+	if c := opts.compression; c != nil {
+		info.Compression = true
+		info.CompressionMinSize = c.MinSize
+		info.CompressionContentTypes = c.ContentTypes
+		info.CompressionEncodings = c.Encodings
+	}
+	for _, sig := range opts.shutdownSignals {
+		info.ShutdownSignals = append(info.ShutdownSignals, sig.String())
+	}
+	return info
 }
 
 // Option a function configuring [App]
+//
+// Options related to request processing, like [WithCSVComma], can also be used with [App.HandleFunc] to override per route
 type Option func(opts *options)
 
 // WithConcurrency set maximum concurrent requests of [App].
@@ -20,6 +534,24 @@ func WithConcurrency(c int) Option {
 	}
 }
 
+// WithMaxQueueDepth set maximum requests waiting for a concurrency slot, extra requests are rejected with 503 immediately.
+//
+// A value <= 0 means unlimited
+func WithMaxQueueDepth(n int) Option {
+	return func(opts *options) {
+		opts.maxQueueDepth = n
+	}
+}
+
+// WithConcurrencyWatermark set ratio of available concurrency slots, below which a warning is logged, at most once per minute, defaults to 0.1
+//
+// Available slots are also exposed as gauge "summer_concurrency_available". A value <= 0 disables the warning
+func WithConcurrencyWatermark(ratio float64) Option {
+	return func(opts *options) {
+		opts.concurrencyWatermark = ratio
+	}
+}
+
 // WithReadinessCascade set maximum continuous failed Readiness Checks after which Liveness CheckFunc start to fail.
 //
 // Failing Liveness Checks could trigger a Pod restart.
@@ -31,6 +563,15 @@ func WithReadinessCascade(rc int) Option {
 	}
 }
 
+// WithReadinessFailureWindow set duration readiness must be continuously failing before counted by [WithReadinessCascade]
+//
+// Within the window, readiness check still fails, but liveness check stays healthy
+func WithReadinessFailureWindow(d time.Duration) Option {
+	return func(opts *options) {
+		opts.readinessWindow = d
+	}
+}
+
 // WithReadinessPath set readiness check path
 func WithReadinessPath(s string) Option {
 	return func(opts *options) {
@@ -45,9 +586,698 @@ func WithLivenessPath(s string) Option {
 	}
 }
 
+// WithStartupPath set startup check path, see [App.CheckStartupFunc]
+func WithStartupPath(s string) Option {
+	return func(opts *options) {
+		opts.startupPath = s
+	}
+}
+
 // WithMetricsPath set metrics path
 func WithMetricsPath(s string) Option {
 	return func(opts *options) {
 		opts.metricsPath = s
 	}
 }
+
+// WithOptionsEndpoint expose snapshot of options as JSON at [OptionsPath]
+func WithOptionsEndpoint(enabled bool) Option {
+	return func(opts *options) {
+		opts.optionsEndpoint = enabled
+	}
+}
+
+// WithCSVComma set field delimiter for decoding "text/csv" request body, defaults to ','
+func WithCSVComma(r rune) Option {
+	return func(opts *options) {
+		opts.csvComma = r
+	}
+}
+
+// WithCSVComment set comment character for decoding "text/csv" request body, defaults to disabled
+func WithCSVComment(r rune) Option {
+	return func(opts *options) {
+		opts.csvComment = r
+	}
+}
+
+// WithPrometheusRegistry set [prometheus.Registry] registering metrics of [App] and served at metrics path, defaults to the global default registry
+func WithPrometheusRegistry(reg *prometheus.Registry) Option {
+	return func(opts *options) {
+		opts.promRegistry = reg
+	}
+}
+
+// WithAccessLogger set a function to be called with [AccessLogEntry] after each non-debug request completed
+func WithAccessLogger(fn func(entry AccessLogEntry)) Option {
+	return func(opts *options) {
+		opts.accessLogger = fn
+	}
+}
+
+// WithAccessLog enable access log written by [SlogAccessLogger] with logger set by [WithLogger], if no function set by [WithAccessLogger]
+func WithAccessLog(enabled bool) Option {
+	return func(opts *options) {
+		opts.accessLog = enabled
+	}
+}
+
+// WithDebugServer serve all debug endpoints, including probes, metrics and pprof, on a separate HTTP server listening on addr
+//
+// Debug endpoints are no longer served by [App.ServeHTTP], keeping them away from public interface, except the version endpoint set by [WithVersionEndpoint].
+// The server is started and stopped along with [App] like [WithExtraDebugServer]
+func WithDebugServer(addr string) Option {
+	return func(opts *options) {
+		opts.debugAddr = addr
+	}
+}
+
+// WithExtraDebugServer start a secondary HTTP server listening on addr, exposing only given debug paths, can be used multiple times
+//
+// Paths ending with "/" match as prefix, for example "/debug/pprof/".
+//
+// The server is started by [Registry.Startup] and stopped by [Registry.Shutdown], but not checked by readiness
+func WithExtraDebugServer(addr string, paths []string) Option {
+	return func(opts *options) {
+		opts.extraDebugServers = append(opts.extraDebugServers, extraDebugServer{addr: addr, paths: paths})
+	}
+}
+
+// WithDurationBuckets set buckets of request duration histogram "summer_request_duration_seconds"
+//
+// Defaults to [prometheus.DefBuckets]
+func WithDurationBuckets(buckets []float64) Option {
+	return func(opts *options) {
+		opts.durationBuckets = buckets
+	}
+}
+
+// WithMaxConnections set maximum open connections accepted by [App.Run], extra connections are queued by OS
+//
+// A value <= 0 means unlimited
+func WithMaxConnections(n int) Option {
+	return func(opts *options) {
+		opts.maxConnections = n
+	}
+}
+
+// WithServerTimeouts set timeouts of [http.Server] created by [App.Run]
+//
+// Defaults to 10s ReadHeaderTimeout and 2m IdleTimeout, protecting against slowloris attacks
+func WithServerTimeouts(cfg ServerTimeouts) Option {
+	return func(opts *options) {
+		opts.serverTimeouts = cfg
+	}
+}
+
+// WithMaxURLLength set maximum length of request url, longer requests are rejected with 414, defaults to 8192
+//
+// A value <= 0 means unlimited
+func WithMaxURLLength(n int) Option {
+	return func(opts *options) {
+		opts.maxURLLength = n
+	}
+}
+
+// WithGetDeduplication deduplicate identical in-flight GET and HEAD requests, keyed by method, path, query
+// and "Accept", "Accept-Encoding" and "Accept-Language" headers, can be used with [App.HandleFunc] to override per route
+//
+// Only the first request is handled, the others wait and receive the same status code, headers and body, except "Set-Cookie".
+// Requests with "Authorization", "Proxy-Authorization", "Cookie" or "X-API-Key" headers, Server-Sent Events requests and routes of
+// [App.HandleFuncUpgrade] are never deduplicated. Waiters skip middlewares and rate limit, so apps with middlewares of [App.Use],
+// routes of [Group] with middlewares and routes with [WithRateLimit] are never deduplicated either, handlers checking
+// credentials themselves should disable it. Responses are buffered, disable it for routes streaming responses with flushes
+func WithGetDeduplication(enabled bool) Option {
+	return func(opts *options) {
+		opts.getDeduplication = enabled
+	}
+}
+
+// WithTrustedProxies set CIDRs of trusted proxies, forwarded headers are honored only for requests from these proxies
+//
+// Invalid CIDR causes a panic
+func WithTrustedProxies(cidrs []string) Option {
+	return func(opts *options) {
+		opts.trustedProxies = nil
+		for _, item := range cidrs {
+			_, cidr, err := net.ParseCIDR(item)
+			if err != nil {
+				panic(err)
+			}
+			opts.trustedProxies = append(opts.trustedProxies, cidr)
+		}
+	}
+}
+
+// WithLogger set logger of [App], defaults to [slog.Default]
+func WithLogger(logger *slog.Logger) Option {
+	return func(opts *options) {
+		opts.logger = logger
+	}
+}
+
+// WithDebugAuth set an authentication function for debug endpoints matching pathGlob, can be used multiple times
+//
+// pathGlob follows [path.Match], for example "/debug/pprof/*". If multiple globs match a path, all functions must pass.
+//
+// Requests failing authentication are rejected with 401
+func WithDebugAuth(pathGlob string, fn func(req *http.Request) bool) Option {
+	return func(opts *options) {
+		opts.debugAuths = append(opts.debugAuths, debugAuth{glob: pathGlob, fn: fn})
+	}
+}
+
+// WithStartupLog log a summary of configuration after components started and before listening in [App.Run], defaults to true
+func WithStartupLog(enabled bool) Option {
+	return func(opts *options) {
+		opts.startupLog = enabled
+	}
+}
+
+// WithRecoveryHandler set a [RecoveryHandler] for panics in handlers, receiving the panic value and stack
+//
+// fn is responsible for writing the response, otherwise a 500 plain text response is used
+func WithRecoveryHandler(fn RecoveryHandler) Option {
+	return func(opts *options) {
+		opts.recoveryHandler = fn
+	}
+}
+
+// WithBaseContext set [http.Server.BaseContext] of server created by [App.Run]
+func WithBaseContext(fn func(l net.Listener) context.Context) Option {
+	return func(opts *options) {
+		opts.baseContext = fn
+	}
+}
+
+// WithBaseContextValues set [http.Server.BaseContext] of server created by [App.Run] to a context with given values
+func WithBaseContextValues(vals map[any]any) Option {
+	return WithBaseContext(func(l net.Listener) context.Context {
+		ctx := context.Background()
+		for k, v := range vals {
+			ctx = context.WithValue(ctx, k, v)
+		}
+		return ctx
+	})
+}
+
+// WithStrictSlash redirect requests with trailing slash to path without it, using 308 Permanent Redirect
+//
+// For example "/foo/" is redirected to "/foo", only if "/foo" matches a route and "/foo/" does not match a subtree pattern.
+//
+// Debug endpoints are not affected
+func WithStrictSlash(enabled bool) Option {
+	return func(opts *options) {
+		opts.strictSlash = enabled
+	}
+}
+
+// WithDuplicateRoutePolicy set [DuplicateRoutePolicy] of registering a pattern already registered, defaults to [PanicOnDuplicate]
+func WithDuplicateRoutePolicy(policy DuplicateRoutePolicy) Option {
+	return func(opts *options) {
+		opts.duplicateRoutePolicy = policy
+	}
+}
+
+// WithInfo add key/values to build information exposed at [InfoPath], can be used multiple times
+func WithInfo(info map[string]string) Option {
+	return func(opts *options) {
+		m := map[string]string{}
+		for k, v := range opts.buildInfo {
+			m[k] = v
+		}
+		for k, v := range info {
+			m[k] = v
+		}
+		opts.buildInfo = m
+	}
+}
+
+// WithTrailingNewline append a trailing newline to plain text responses of debug endpoints and internal errors, for nicer curl output
+func WithTrailingNewline(enabled bool) Option {
+	return func(opts *options) {
+		opts.trailingNewline = enabled
+	}
+}
+
+// WithMaxBodyBytes set maximum bytes of request body, can be used with [App.HandleFunc] to override per route
+//
+// Requests with a larger "Content-Length" are rejected with 413 before handler called. Reading beyond the limit from [http.Request.Body]
+// fails with [http.MaxBytesError], and results in [ErrBodyTooLarge] for [Context.RawBody], [Context.BodyBytes] and [Context.Bind].
+// Estimated size of rows decoded from "text/csv" body is limited as well. A value <= 0 means unlimited
+func WithMaxBodyBytes(n int64) Option {
+	return func(opts *options) {
+		opts.maxBodyBytes = n
+	}
+}
+
+// WithRequestDecompression transparently decompress request body with "Content-Encoding" of "gzip" or "deflate", defaults to disabled
+//
+// Other encodings are rejected with 415. The decompressed body is limited by [WithMaxBodyBytes] as well
+func WithRequestDecompression(enabled bool) Option {
+	return func(opts *options) {
+		opts.requestDecompression = enabled
+	}
+}
+
+// WithMultipartMemory set maximum bytes of "multipart/form-data" file parts kept in memory, defaults to 32MB, can be used with [App.HandleFunc] to override per route
+//
+// Larger files are stored in temporary files, which are removed after response performed. Total size of request body is still limited by [WithMaxBodyBytes]
+func WithMultipartMemory(n int64) Option {
+	return func(opts *options) {
+		opts.multipartMemory = n
+	}
+}
+
+// WithBindPrefixes set key prefixes of header and query values extracted for [Context.Bind], defaults to "header_" and "query_"
+//
+// Query values are always extracted without prefix as well
+func WithBindPrefixes(header, query string) Option {
+	return func(opts *options) {
+		opts.bindHeaderPrefix = header
+		opts.bindQueryPrefix = query
+	}
+}
+
+// WithBindCollisionPolicy set [CollisionPolicy] of keys extracted for [Context.Bind], defaults to [KeepLastOnCollision]
+func WithBindCollisionPolicy(policy CollisionPolicy) Option {
+	return func(opts *options) {
+		opts.bindCollisionPolicy = policy
+	}
+}
+
+// WithCheckHTTPDialer set [net.Dialer] of the [http.Client] shared by checks registered with [App.CheckHTTP], defaults to [http.DefaultClient]
+//
+// The dialer is wrapped in a [http.Transport] with keep-alive defaults similar to [http.DefaultTransport]
+func WithCheckHTTPDialer(dialer *net.Dialer) Option {
+	return func(opts *options) {
+		opts.checkHTTPDialer = dialer
+	}
+}
+
+// WithRequiredAccept reject requests with "Accept" header not accepting any of types, with 406 listing supported types
+//
+// Quality factors are honored, "*/*" and "type/*" match as well. Requests without "Accept" header are rejected
+func WithRequiredAccept(types []string) Option {
+	return func(opts *options) {
+		opts.requiredAccept = types
+	}
+}
+
+// WithRequestTimeout set timeout of request context for non-debug routes, defaults to disabled
+//
+// Once the deadline exceeded, if no response is sent yet, 504 is responded with an [Error] immediately, without waiting for the handler,
+// and later writes of the handler are dropped. Handlers should honor the deadline by using [Context] as [context.Context]
+//
+// It can be overridden per route with [App.HandleFunc]
+func WithRequestTimeout(d time.Duration) Option {
+	return func(opts *options) {
+		opts.requestTimeout = d
+	}
+}
+
+// WithCheckTimeout set timeout of each check of readiness, defaults to disabled
+//
+// Checks run concurrently, a timed out check is reported as [ErrCheckTimeout] without waiting it
+func WithCheckTimeout(d time.Duration) Option {
+	return func(opts *options) {
+		opts.checkTimeout = d
+	}
+}
+
+// WithCheckOutputFormat set [CheckOutputFormat] of readiness check output
+//
+// For example, single-line output with CheckOutputFormat{LineSeparator: "; ", NameSeparator: "=", OKText: "OK"}
+func WithCheckOutputFormat(format CheckOutputFormat) Option {
+	return func(opts *options) {
+		opts.checkOutputFormat = format
+	}
+}
+
+// WithJSONSchema validate request body against a JSON Schema document before the handler, usually used with [App.HandleFunc]
+//
+// Validation runs after middlewares, whether the handler binds the body or not. Body other than "application/json" is rejected
+// with 415, missing body is validated as null. Invalid JSON results in 400, and violations in 422 as [SchemaValidationError].
+//
+// Invalid schema causes a panic
+func WithJSONSchema(schema []byte) Option {
+	compiled, err := compileJSONSchema(schema)
+	if err != nil {
+		panic(err)
+	}
+	return func(opts *options) {
+		opts.jsonSchema = compiled
+	}
+}
+
+// WithRequestTimeoutHeader derive deadline of request context from a request header, like "X-Request-Timeout"
+//
+// Header value is either a duration like "1.5s", or seconds like "1.5". If [WithRequestTimeout] is also set, the shorter one wins.
+//
+// Handlers get the deadline with [Context.Deadline], same as [WithRequestTimeout]
+func WithRequestTimeoutHeader(name string) Option {
+	return func(opts *options) {
+		opts.requestTimeoutHeader = name
+	}
+}
+
+// WithIPAllowlist allow requests with given methods only from client ips within cidrs, others are rejected with 403, can be used multiple times
+//
+// For example, WithIPAllowlist([]string{"POST", "PUT", "DELETE"}, []string{"10.0.0.0/8"}) restricts write operations to internal network.
+//
+// Client ip honors forwarded headers only for requests from proxies set by [WithTrustedProxies]. Debug endpoints are not affected.
+//
+// Invalid CIDR causes a panic
+func WithIPAllowlist(methods []string, cidrs []string) Option {
+	item := ipAllowlist{methods: map[string]struct{}{}}
+	for _, method := range methods {
+		item.methods[strings.ToUpper(method)] = struct{}{}
+	}
+	for _, s := range cidrs {
+		_, cidr, err := net.ParseCIDR(s)
+		if err != nil {
+			panic(err)
+		}
+		item.cidrs = append(item.cidrs, cidr)
+	}
+	return func(opts *options) {
+		opts.ipAllowlists = append(opts.ipAllowlists, item)
+	}
+}
+
+// WithOTelSpanKind set span kind of server spans created by otelhttp, defaults to [trace.SpanKindServer]
+//
+// Supported values are [trace.SpanKindServer], [trace.SpanKindConsumer] and [trace.SpanKindInternal], others cause a panic
+func WithOTelSpanKind(kind trace.SpanKind) Option {
+	switch kind {
+	case trace.SpanKindServer, trace.SpanKindConsumer, trace.SpanKindInternal:
+	default:
+		panic("summer: unsupported span kind: " + kind.String())
+	}
+	return func(opts *options) {
+		opts.otelSpanKind = kind
+	}
+}
+
+// WithOTel enable or disable the otelhttp integration, defaults to enabled
+func WithOTel(enabled bool) Option {
+	return func(opts *options) {
+		opts.otelDisabled = !enabled
+	}
+}
+
+// withOTelOption append an [otelhttp.Option], without modifying options shared with others
+func withOTelOption(o otelhttp.Option) Option {
+	return func(opts *options) {
+		opts.otelOptions = append(opts.otelOptions[:len(opts.otelOptions):len(opts.otelOptions)], o)
+	}
+}
+
+// WithOTelTracerProvider set [trace.TracerProvider] of otelhttp, defaults to the global one
+func WithOTelTracerProvider(tp trace.TracerProvider) Option {
+	return withOTelOption(otelhttp.WithTracerProvider(tp))
+}
+
+// WithOTelMeterProvider set [metric.MeterProvider] of otelhttp, defaults to the global one
+func WithOTelMeterProvider(mp metric.MeterProvider) Option {
+	return withOTelOption(otelhttp.WithMeterProvider(mp))
+}
+
+// WithOTelPropagators set [propagation.TextMapPropagator] of otelhttp, defaults to the global one
+func WithOTelPropagators(p propagation.TextMapPropagator) Option {
+	return withOTelOption(otelhttp.WithPropagators(p))
+}
+
+// WithOTelSpanNameFormatter set function formatting names of server spans, operation is always "http"
+func WithOTelSpanNameFormatter(fn func(operation string, req *http.Request) string) Option {
+	return withOTelOption(otelhttp.WithSpanNameFormatter(fn))
+}
+
+// WithOTelFilter add a filter of otelhttp, requests are not traced if any filter returns false, can be used multiple times
+//
+// Debug endpoints are never traced
+func WithOTelFilter(fn func(req *http.Request) bool) Option {
+	return withOTelOption(otelhttp.WithFilter(fn))
+}
+
+// WithTraceIDHeader set response header carrying trace id of current span, defaults to "X-Trace-Id", empty value disables it
+func WithTraceIDHeader(name string) Option {
+	return func(opts *options) {
+		opts.traceIDHeader = name
+	}
+}
+
+// WithRequestIDHeader set request header honored as request id and echoed in response, defaults to "X-Request-Id", empty value disables it
+//
+// A random request id is generated if the inbound one is missing or invalid, see [Context.RequestID]
+func WithRequestIDHeader(name string) Option {
+	return func(opts *options) {
+		opts.requestIDHeader = name
+	}
+}
+
+// WithWebSocketUpgrader set [websocket.Upgrader] of [App.HandleWebSocket], defaults to a zero value rejecting cross-origin requests
+func WithWebSocketUpgrader(u *websocket.Upgrader) Option {
+	return func(opts *options) {
+		opts.webSocketUpgrader = u
+	}
+}
+
+// WithSSEHeartbeat set interval of heartbeat comments of [Context.SSE], defaults to 15s, a value <= 0 disables it
+func WithSSEHeartbeat(interval time.Duration) Option {
+	return func(opts *options) {
+		opts.sseHeartbeat = interval
+	}
+}
+
+// WithGRPC serve gRPC requests, HTTP/2 with content type "application/grpc", with h on the same port, usually a *grpc.Server
+//
+// gRPC requests bypass routing, debug endpoints and concurrency control of [App]. h2c is enabled, see [WithH2C]
+func WithGRPC(h http.Handler) Option {
+	return func(opts *options) {
+		opts.grpcHandler = h
+	}
+}
+
+// WithH2C enable HTTP/2 without TLS (h2c) of server created by [App.Run], defaults to disabled unless [WithGRPC] is used
+func WithH2C(enabled bool) Option {
+	return func(opts *options) {
+		opts.h2c = enabled
+	}
+}
+
+// WithTLS serve HTTPS with certificate pair loaded from certFile and keyFile in server created by [App.Run], HTTP/2 is negotiated via ALPN
+//
+// Certificate files are checked for modification at most once per second on handshakes, rotated certificates are reloaded without restarting
+func WithTLS(certFile, keyFile string) Option {
+	return func(opts *options) {
+		opts.tlsCertFile = certFile
+		opts.tlsKeyFile = keyFile
+	}
+}
+
+// WithTLSClientCA verify client certificates against CA certificates in caFile, requires [WithTLS]
+//
+// If required is false, clients without certificate are still accepted, see [Context.ClientCert] for the verified identity
+func WithTLSClientCA(caFile string, required bool) Option {
+	return func(opts *options) {
+		opts.tlsClientCAFile = caFile
+		opts.tlsClientCertRequired = required
+	}
+}
+
+// WithPathPrefix strip prefix from path of all incoming requests before routing, for example the stage name "/prod" prepended by API gateways
+//
+// Requests without the prefix are served as is, with a warning logged unless for debug endpoints. Prefix not starting with "/" causes a panic
+func WithPathPrefix(prefix string) Option {
+	if !strings.HasPrefix(prefix, "/") {
+		panic("summer: path prefix must start with \"/\": " + prefix)
+	}
+	prefix = strings.TrimRight(prefix, "/")
+	return func(opts *options) {
+		opts.pathPrefix = prefix
+	}
+}
+
+// WithVersionEndpoint expose [VersionInfo] as JSON at [DefaultVersionPath], publicly outside debug endpoints, and at [BuildPath]
+//
+// GoVersion defaults to [runtime.Version] if empty
+func WithVersionEndpoint(version VersionInfo) Option {
+	if version.GoVersion == "" {
+		version.GoVersion = runtime.Version()
+	}
+	return func(opts *options) {
+		opts.versionInfo = &version
+	}
+}
+
+// WithVersionEndpointPath set path of version endpoint enabled by [WithVersionEndpoint], defaults to [DefaultVersionPath]
+func WithVersionEndpointPath(path string) Option {
+	return func(opts *options) {
+		opts.versionPath = path
+	}
+}
+
+// WithCleanIdleConnections close idle connections of server created by [App.Run] on every interval, by toggling keep-alives
+//
+// This is a workaround for scenarios where [ServerTimeouts] IdleTimeout alone is insufficient. A value <= 0 means disabled
+func WithCleanIdleConnections(interval time.Duration) Option {
+	return func(opts *options) {
+		opts.cleanIdleInterval = interval
+	}
+}
+
+// WithHeartbeat log a heartbeat line with uptime and total requests on every interval, for monitoring tools alerting on log silence
+//
+// Heartbeat is registered as a component of [App], started by [Registry.Startup] and stopped by [Registry.Shutdown].
+// If logger is nil, logger set by [WithLogger] is used. A value <= 0 means disabled
+func WithHeartbeat(interval time.Duration, logger *slog.Logger) Option {
+	return func(opts *options) {
+		opts.heartbeatInterval = interval
+		opts.heartbeatLogger = logger
+	}
+}
+
+// WithShutdownSignals set signals triggering graceful shutdown of [App.Run], like syscall.SIGTERM and os.Interrupt
+//
+// No signals are handled by default, [App.Run] is stopped only by context. Do not use it with [FxModule], signals are handled by fx
+func WithShutdownSignals(sigs ...os.Signal) Option {
+	return func(opts *options) {
+		opts.shutdownSignals = sigs
+	}
+}
+
+// WithDrainPeriod set duration readiness check fails with 503 before shutting down server in [App.Run], defaults to 0
+//
+// Set it longer than period of readiness probes, so load balancers stop sending new requests before shutdown
+func WithDrainPeriod(d time.Duration) Option {
+	return func(opts *options) {
+		opts.drainPeriod = d
+	}
+}
+
+// WithShutdownTimeout set timeout of shutting down server and components in [App.Run], defaults to 30s
+func WithShutdownTimeout(d time.Duration) Option {
+	return func(opts *options) {
+		opts.shutdownTimeout = d
+	}
+}
+
+// WithValidator set [Validator] validating data bound by [Context.Bind] and [Context.ShouldBind]
+func WithValidator(v Validator) Option {
+	return func(opts *options) {
+		opts.validator = v
+	}
+}
+
+// WithCORS enable cross-origin resource sharing of all routes with cfg, debug endpoints are not affected
+//
+// Preflight requests are responded with 204 before routing, or 403 if origin or method is not allowed
+func WithCORS(cfg CORSConfig) Option {
+	return func(opts *options) {
+		opts.cors = &cfg
+	}
+}
+
+// WithSessions enable [Context.Session] with cfg, can be used with [App.HandleFunc] to override per route
+//
+// Session id, or session data if [SessionConfig.Store] is nil, is kept in a cookie signed, and optionally encrypted, by [SecureCookie].
+// Invalid keys cause a panic
+func WithSessions(cfg SessionConfig) Option {
+	m := newSessionManager(cfg)
+	return func(opts *options) {
+		opts.sessions = m
+	}
+}
+
+// WithConfig populate struct pointed by cfg with [LoadConfig] in [New], invalid config causes a panic with the error
+//
+// [Validator] set by [WithValidator] is used unless [ConfigWithValidator] is set.
+// The config is loaded after all options applied, it can not drive other options of the same [New] call,
+// use [LoadConfig] before [New] instead, for example to set [WithConcurrency] from config
+func WithConfig(cfg any, opts ...ConfigOption) Option {
+	return func(o *options) {
+		o.configs = append(o.configs, configEntry{cfg: cfg, opts: opts})
+	}
+}
+
+// WithWorkerBackoff set backoff of restarting failed workers of [App.Go], defaults to 1 second up to 1 minute
+func WithWorkerBackoff(b WorkerBackoff) Option {
+	return func(opts *options) {
+		if b.Min <= 0 {
+			b.Min = time.Second
+		}
+		if b.Max <= 0 {
+			b.Max = time.Minute
+		}
+		if b.Max < b.Min {
+			b.Max = b.Min
+		}
+		opts.workerBackoff = b
+	}
+}
+
+// WithOpenAPI serve the OpenAPI document generated by [App.OpenAPI] at [OpenAPIPath], and Swagger UI at [SwaggerUIPath] if enabled
+func WithOpenAPI(cfg OpenAPIConfig) Option {
+	return func(opts *options) {
+		opts.openAPI = &cfg
+	}
+}
+
+// WithOperation document the route in OpenAPI document with op, used with [App.HandleFunc], see [App.OpenAPI]
+func WithOperation(op Operation) Option {
+	return func(opts *options) {
+		opts.operation = &op
+	}
+}
+
+// operationOf returns [Operation] set by [WithOperation] in opts, nil if not set
+func operationOf(opts []Option) *Operation {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o.operation
+}
+
+// WithCompression compress responses with encoding negotiated by "Accept-Encoding", nil disables, can be used with [App.HandleFunc] to override per route
+//
+// Responses smaller than [Compression.MinSize], of other content types, or with "Content-Encoding" or "Content-Range" are sent as is
+func WithCompression(c *Compression) Option {
+	return func(opts *options) {
+		opts.compression = c
+	}
+}
+
+// WithRateLimit set [RateLimit] of a route, use it with [App.HandleFunc], rejected requests are responded with 429 and "Retry-After" header
+//
+// Used with [New], each route gets a separate limit unless [RateLimit.Store] is shared. Errors of store are logged and requests are allowed
+func WithRateLimit(rl RateLimit) Option {
+	return func(opts *options) {
+		opts.rateLimit = rl
+	}
+}
+
+// WithRouteConcurrency set [RouteConcurrency] of a route, in addition to [WithConcurrency], use it with [App.HandleFunc]
+//
+// Requests waiting in queue of route do not hold slots of [WithConcurrency]. See [Group.Concurrency] for limits shared by a group.
+// In-flight and queued requests are exposed as gauges "summer_route_in_flight" and "summer_route_queue_depth"
+func WithRouteConcurrency(rc RouteConcurrency) Option {
+	return func(opts *options) {
+		opts.routeConcurrency = rc
+	}
+}
+
+// withRouteMiddlewares mark a route wrapped with middlewares of [Group]
+func withRouteMiddlewares() Option {
+	return func(opts *options) {
+		opts.routeMiddlewares = true
+	}
+}
+
+// withGroupLimiters set limiters of [Group.Concurrency] of a route
+func withGroupLimiters(limiters []*routeLimiter) Option {
+	return func(opts *options) {
+		opts.groupLimiters = limiters
+	}
+}
@@ -1,8 +1,18 @@
 package summer
 
 import (
+	"encoding/json"
+	"github.com/gorilla/websocket"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"reflect"
 	"testing"
+	"time"
 )
 
 func TestOptions(t *testing.T) {
@@ -10,14 +20,26 @@ func TestOptions(t *testing.T) {
 	WithConcurrency(2)(&opts)
 	require.Equal(t, 2, opts.concurrency)
 
+	opts = options{}
+	WithMaxQueueDepth(2)(&opts)
+	require.Equal(t, 2, opts.maxQueueDepth)
+
 	opts = options{}
 	WithReadinessCascade(2)(&opts)
 	require.Equal(t, int64(2), opts.readinessCascade)
 
+	opts = options{}
+	WithReadinessFailureWindow(time.Minute)(&opts)
+	require.Equal(t, time.Minute, opts.readinessWindow)
+
 	opts = options{}
 	WithLivenessPath("/aaa")(&opts)
 	require.Equal(t, "/aaa", opts.livenessPath)
 
+	opts = options{}
+	WithStartupPath("/aaa")(&opts)
+	require.Equal(t, "/aaa", opts.startupPath)
+
 	opts = options{}
 	WithReadinessPath("/aaa")(&opts)
 	require.Equal(t, "/aaa", opts.readinessPath)
@@ -25,4 +47,302 @@ func TestOptions(t *testing.T) {
 	opts = options{}
 	WithMetricsPath("/aaa")(&opts)
 	require.Equal(t, "/aaa", opts.metricsPath)
+
+	opts = options{}
+	WithOptionsEndpoint(true)(&opts)
+	require.True(t, opts.optionsEndpoint)
+
+	opts = options{}
+	WithCSVComma(';')(&opts)
+	require.Equal(t, ';', opts.csvComma)
+
+	opts = options{}
+	WithCSVComment('#')(&opts)
+	require.Equal(t, '#', opts.csvComment)
+
+	opts = options{}
+	WithDurationBuckets([]float64{0.1, 1})(&opts)
+	require.Equal(t, []float64{0.1, 1}, opts.durationBuckets)
+
+	opts = options{}
+	WithMaxConnections(2)(&opts)
+	require.Equal(t, 2, opts.maxConnections)
+
+	opts = defaultOptions()
+	require.Equal(t, time.Second*10, opts.serverTimeouts.ReadHeaderTimeout)
+	WithServerTimeouts(ServerTimeouts{ReadTimeout: time.Second})(&opts)
+	require.Equal(t, ServerTimeouts{ReadTimeout: time.Second}, opts.serverTimeouts)
+
+	opts = options{}
+	WithMaxURLLength(10)(&opts)
+	require.Equal(t, 10, opts.maxURLLength)
+
+	opts = options{}
+	WithGetDeduplication(true)(&opts)
+	require.True(t, opts.getDeduplication)
+
+	opts = options{}
+	WithTrustedProxies([]string{"10.0.0.0/8"})(&opts)
+	require.Len(t, opts.trustedProxies, 1)
+	require.Equal(t, "10.0.0.0/8", opts.trustedProxies[0].String())
+	require.Panics(t, func() {
+		WithTrustedProxies([]string{"bad"})(&opts)
+	})
+
+	opts = options{}
+	WithLogger(slog.Default())(&opts)
+	require.Equal(t, slog.Default(), opts.logger)
+
+	opts = defaultOptions()
+	require.True(t, opts.startupLog)
+	WithStartupLog(false)(&opts)
+	require.False(t, opts.startupLog)
+
+	type contextKey struct{}
+	opts = options{}
+	WithBaseContextValues(map[any]any{contextKey{}: "value"})(&opts)
+	require.Equal(t, "value", opts.baseContext(nil).Value(contextKey{}))
+
+	opts = options{}
+	WithStrictSlash(true)(&opts)
+	require.True(t, opts.strictSlash)
+
+	opts = options{}
+	WithDuplicateRoutePolicy(OverwriteOnDuplicate)(&opts)
+	require.Equal(t, OverwriteOnDuplicate, opts.duplicateRoutePolicy)
+
+	opts = options{}
+	WithInfo(map[string]string{"a": "1", "b": "2"})(&opts)
+	WithInfo(map[string]string{"b": "3"})(&opts)
+	require.Equal(t, map[string]string{"a": "1", "b": "3"}, opts.buildInfo)
+
+	opts = options{}
+	WithTrailingNewline(true)(&opts)
+	require.True(t, opts.trailingNewline)
+
+	opts = options{}
+	WithMaxBodyBytes(1024)(&opts)
+	require.Equal(t, int64(1024), opts.maxBodyBytes)
+
+	opts = options{}
+	WithBindPrefixes("h_", "q_")(&opts)
+	WithBindCollisionPolicy(ErrorOnCollision)(&opts)
+	require.Equal(t, "h_", opts.bindHeaderPrefix)
+	require.Equal(t, "q_", opts.bindQueryPrefix)
+	require.Equal(t, ErrorOnCollision, opts.bindCollisionPolicy)
+
+	opts = options{}
+	dialer := &net.Dialer{Timeout: time.Second}
+	WithCheckHTTPDialer(dialer)(&opts)
+	require.Equal(t, dialer, opts.checkHTTPDialer)
+
+	opts = options{}
+	WithCheckTimeout(time.Second)(&opts)
+	require.Equal(t, time.Second, opts.checkTimeout)
+
+	opts = options{}
+	WithConcurrencyWatermark(0.2)(&opts)
+	require.Equal(t, 0.2, opts.concurrencyWatermark)
+
+	opts = options{}
+	WithRequiredAccept([]string{ContentTypeApplicationJSON})(&opts)
+	require.Equal(t, []string{ContentTypeApplicationJSON}, opts.requiredAccept)
+
+	opts = options{}
+	WithRequestTimeout(time.Second)(&opts)
+	require.Equal(t, time.Second, opts.requestTimeout)
+
+	opts = options{}
+	WithCheckOutputFormat(CheckOutputFormat{LineSeparator: "; ", NameSeparator: "=", OKText: "UP"})(&opts)
+	require.Equal(t, CheckOutputFormat{LineSeparator: "; ", NameSeparator: "=", OKText: "UP"}, opts.checkOutputFormat)
+
+	opts = options{}
+	WithRequestTimeoutHeader("X-Request-Timeout")(&opts)
+	require.Equal(t, "X-Request-Timeout", opts.requestTimeoutHeader)
+
+	opts = options{}
+	WithOTelSpanKind(trace.SpanKindConsumer)(&opts)
+	require.Equal(t, trace.SpanKindConsumer, opts.otelSpanKind)
+	require.Panics(t, func() {
+		WithOTelSpanKind(trace.SpanKindClient)
+	})
+
+	opts = options{}
+	upgrader := &websocket.Upgrader{}
+	WithWebSocketUpgrader(upgrader)(&opts)
+	require.Equal(t, upgrader, opts.webSocketUpgrader)
+
+	opts = options{}
+	WithH2C(true)(&opts)
+	require.True(t, opts.h2c)
+
+	opts = options{}
+	WithOTel(false)(&opts)
+	require.True(t, opts.otelDisabled)
+	WithOTelPropagators(propagation.TraceContext{})(&opts)
+	WithOTelFilter(func(req *http.Request) bool { return true })(&opts)
+	require.Len(t, opts.otelOptions, 2)
+
+	opts = options{}
+	WithCleanIdleConnections(time.Minute)(&opts)
+	require.Equal(t, time.Minute, opts.cleanIdleInterval)
+
+	opts = options{}
+	WithHeartbeat(time.Minute, nil)(&opts)
+	require.Equal(t, time.Minute, opts.heartbeatInterval)
+	require.Nil(t, opts.heartbeatLogger)
+
+	opts = options{}
+	WithShutdownSignals(os.Interrupt)(&opts)
+	WithDrainPeriod(time.Second)(&opts)
+	WithShutdownTimeout(time.Minute)(&opts)
+	require.Equal(t, []os.Signal{os.Interrupt}, opts.shutdownSignals)
+	require.Equal(t, time.Second, opts.drainPeriod)
+	require.Equal(t, time.Minute, opts.shutdownTimeout)
+
+	opts = options{}
+	WithValidator(ValidatorFunc(func(data any) error { return nil }))(&opts)
+	require.NotNil(t, opts.validator)
+
+	opts = options{}
+	WithExtraDebugServer(":9090", []string{"/debug/pprof/"})(&opts)
+	WithExtraDebugServer(":9091", []string{"/debug/ready"})(&opts)
+	require.Equal(t, []extraDebugServer{
+		{addr: ":9090", paths: []string{"/debug/pprof/"}},
+		{addr: ":9091", paths: []string{"/debug/ready"}},
+	}, opts.extraDebugServers)
+
+	opts = options{}
+	WithDebugServer(":9090")(&opts)
+	require.Equal(t, ":9090", opts.debugAddr)
+}
+
+func TestOptionsInfoComplete(t *testing.T) {
+	// fields of options not in snapshot
+	excluded := map[string]bool{
+		"logger":           true,
+		"heartbeatLogger":  true,
+		"promRegistry":     true,
+		"baseContext":      true,
+		"checkHTTPDialer":  true,
+		"otelOptions":      true,
+		"groupLimiters":    true,
+		"routeMiddlewares": true,
+		"operation":        true,
+	}
+	// fields of options mapped to fields of OptionsInfo
+	covered := map[string][]string{
+		"concurrency":           {"Concurrency"},
+		"maxQueueDepth":         {"MaxQueueDepth"},
+		"readinessCascade":      {"ReadinessCascade"},
+		"readinessWindow":       {"ReadinessWindow"},
+		"readinessPath":         {"ReadinessPath"},
+		"livenessPath":          {"LivenessPath"},
+		"startupPath":           {"StartupPath"},
+		"metricsPath":           {"MetricsPath"},
+		"optionsEndpoint":       {"OptionsEndpoint"},
+		"csvComma":              {"CSVComma"},
+		"csvComment":            {"CSVComment"},
+		"accessLogger":          {"AccessLogger"},
+		"accessLog":             {"AccessLog"},
+		"durationBuckets":       {"DurationBuckets"},
+		"maxConnections":        {"MaxConnections"},
+		"serverTimeouts":        {"ReadTimeout", "ReadHeaderTimeout", "WriteTimeout", "IdleTimeout"},
+		"maxURLLength":          {"MaxURLLength"},
+		"getDeduplication":      {"GetDeduplication"},
+		"trustedProxies":        {"TrustedProxies"},
+		"debugAuths":            {"DebugAuths"},
+		"startupLog":            {"StartupLog"},
+		"recoveryHandler":       {"RecoveryHandler"},
+		"strictSlash":           {"StrictSlash"},
+		"buildInfo":             {"BuildInfo"},
+		"trailingNewline":       {"TrailingNewline"},
+		"maxBodyBytes":          {"MaxBodyBytes"},
+		"requestDecompression":  {"RequestDecompression"},
+		"multipartMemory":       {"MultipartMemory"},
+		"bindHeaderPrefix":      {"BindHeaderPrefix"},
+		"bindQueryPrefix":       {"BindQueryPrefix"},
+		"bindCollisionPolicy":   {"BindCollisionPolicy"},
+		"checkTimeout":          {"CheckTimeout"},
+		"duplicateRoutePolicy":  {"DuplicateRoutePolicy"},
+		"concurrencyWatermark":  {"ConcurrencyWatermark"},
+		"requiredAccept":        {"RequiredAccept"},
+		"requestTimeout":        {"RequestTimeout"},
+		"requestTimeoutHeader":  {"RequestTimeoutHeader"},
+		"ipAllowlists":          {"IPAllowlists"},
+		"otelSpanKind":          {"OTelSpanKind"},
+		"otelDisabled":          {"OTelDisabled"},
+		"traceIDHeader":         {"TraceIDHeader"},
+		"requestIDHeader":       {"RequestIDHeader"},
+		"pathPrefix":            {"PathPrefix"},
+		"versionInfo":           {"VersionInfo"},
+		"versionPath":           {"VersionPath"},
+		"cleanIdleInterval":     {"CleanIdleInterval"},
+		"heartbeatInterval":     {"HeartbeatInterval"},
+		"shutdownSignals":       {"ShutdownSignals"},
+		"drainPeriod":           {"DrainPeriod"},
+		"shutdownTimeout":       {"ShutdownTimeout"},
+		"validator":             {"Validator"},
+		"webSocketUpgrader":     {"WebSocketUpgrader"},
+		"sseHeartbeat":          {"SSEHeartbeat"},
+		"grpcHandler":           {"GRPC"},
+		"h2c":                   {"H2C"},
+		"tlsCertFile":           {"TLS"},
+		"tlsKeyFile":            {"TLS"},
+		"tlsClientCAFile":       {"TLSClientCA"},
+		"tlsClientCertRequired": {"TLSClientCertRequired"},
+		"routeConcurrency":      {"RouteConcurrency", "RouteQueue", "RouteRejectStatus"},
+		"rateLimit":             {"RateLimit"},
+		"cors":                  {"CORS"},
+		"sessions":              {"Sessions"},
+		"configs":               {"Configs"},
+		"workerBackoff":         {"WorkerBackoffMin", "WorkerBackoffMax"},
+		"openAPI":               {"OpenAPI"},
+		"compression":           {"Compression", "CompressionMinSize", "CompressionContentTypes", "CompressionEncodings"},
+		"checkOutputFormat":     {"CheckLineSeparator", "CheckNameSeparator", "CheckOKText"},
+		"jsonSchema":            {"JSONSchema"},
+		"extraDebugServers":     {"ExtraDebugServers"},
+		"debugAddr":             {"DebugAddr"},
+	}
+
+	infoType := reflect.TypeFor[OptionsInfo]()
+	mapped := map[string]bool{}
+	for _, f := range reflect.VisibleFields(reflect.TypeFor[options]()) {
+		if excluded[f.Name] {
+			continue
+		}
+		fields, ok := covered[f.Name]
+		require.True(t, ok, "options.%s is missing from OptionsInfo", f.Name)
+		for _, name := range fields {
+			_, ok = infoType.FieldByName(name)
+			require.True(t, ok, "OptionsInfo.%s of options.%s not found", name, f.Name)
+			mapped[name] = true
+		}
+	}
+	for _, f := range reflect.VisibleFields(infoType) {
+		require.True(t, mapped[f.Name], "OptionsInfo.%s is not mapped from options", f.Name)
+	}
+
+	_, cidr, _ := net.ParseCIDR("10.0.0.0/8")
+	info := options{
+		tlsCertFile:     "/etc/tls/tls.crt",
+		tlsKeyFile:      "/etc/tls/tls.key",
+		tlsClientCAFile: "/etc/tls/ca.crt",
+		trustedProxies:  []*net.IPNet{cidr},
+		ipAllowlists:    []ipAllowlist{{methods: map[string]struct{}{"POST": {}, "DELETE": {}}, cidrs: []*net.IPNet{cidr}}},
+		rateLimit:       RateLimit{Limit: 10, Key: func(req *http.Request) string { return "" }},
+		shutdownSignals: []os.Signal{os.Interrupt},
+		csvComma:        ';',
+	}.info()
+	require.True(t, info.TLS)
+	require.True(t, info.TLSClientCA)
+	buf, err := json.Marshal(info)
+	require.NoError(t, err)
+	require.NotContains(t, string(buf), "/etc/tls")
+	require.Equal(t, []string{"10.0.0.0/8"}, info.TrustedProxies)
+	require.Equal(t, []IPAllowlistInfo{{Methods: []string{"DELETE", "POST"}, CIDRs: []string{"10.0.0.0/8"}}}, info.IPAllowlists)
+	require.Equal(t, RateLimitInfo{Limit: 10, Key: true}, info.RateLimit)
+	require.Equal(t, []string{"interrupt"}, info.ShutdownSignals)
+	require.Equal(t, ";", info.CSVComma)
+	require.Empty(t, info.CSVComment)
 }
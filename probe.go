@@ -0,0 +1,135 @@
+package summer
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	ReadinessStatusOK       = "ok"
+	ReadinessStatusFailed   = "failed"
+	ReadinessStatusDraining = "draining"
+
+	CheckStatusOK      = "ok"
+	CheckStatusFailed  = "failed"
+	CheckStatusTimeout = "timeout"
+)
+
+// ReadinessReport machine-readable readiness check output, requested with "?format=json" or "Accept: application/json"
+type ReadinessReport struct {
+	// Status one of [ReadinessStatusOK], [ReadinessStatusFailed] and [ReadinessStatusDraining]
+	Status string `json:"status"`
+	// Checks results of checks, in registration order
+	Checks []CheckResult `json:"checks"`
+}
+
+// CheckResult result of a single check in [ReadinessReport]
+type CheckResult struct {
+	// Name name of component
+	Name string `json:"name"`
+	// Status one of [CheckStatusOK], [CheckStatusFailed] and [CheckStatusTimeout]
+	Status string `json:"status"`
+	// LatencyMS duration of check in milliseconds
+	LatencyMS float64 `json:"latency_ms"`
+	// Error message of error, if failed
+	Error string `json:"error,omitempty"`
+
+	err error
+}
+
+func newCheckResult(name string, err error, d time.Duration) CheckResult {
+	r := CheckResult{
+		Name:      name,
+		Status:    CheckStatusOK,
+		LatencyMS: float64(d) / float64(time.Millisecond),
+		err:       err,
+	}
+	if err != nil {
+		r.Status = CheckStatusFailed
+		if errors.Is(err, ErrCheckTimeout) {
+			r.Status = CheckStatusTimeout
+		}
+		r.Error = err.Error()
+	}
+	return r
+}
+
+// failed returns true if any check failed
+func (r ReadinessReport) failed() bool {
+	for _, item := range r.Checks {
+		if item.err != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// probeCheck a named check of a probe
+type probeCheck struct {
+	name string
+	fn   LifecycleFunc
+}
+
+// probeChecks checks of a probe other than readiness, in registration order
+type probeChecks struct {
+	mu     sync.Mutex
+	checks []probeCheck
+}
+
+// set add a check, or replace the check with the same name
+func (p *probeChecks) set(name string, fn LifecycleFunc) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for i, item := range p.checks {
+		if item.name == name {
+			p.checks[i].fn = fn
+			return
+		}
+	}
+	p.checks = append(p.checks, probeCheck{name: name, fn: fn})
+}
+
+// len returns number of checks
+func (p *probeChecks) len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.checks)
+}
+
+// run run all checks, in the same manner of [Registry.Check]
+func (p *probeChecks) run(ctx context.Context, fn func(name string, err error)) {
+	p.mu.Lock()
+	checks := append([]probeCheck{}, p.checks...)
+	p.mu.Unlock()
+
+	for _, item := range checks {
+		fn(item.name, item.fn(ctx))
+	}
+}
+
+// formatChecks format results of checks with [CheckOutputFormat], returns output and whether any check failed
+func formatChecks(format CheckOutputFormat, run func(fn func(name string, err error))) (string, bool) {
+	sb := &strings.Builder{}
+	var failed bool
+	run(func(name string, err error) {
+		if sb.Len() > 0 {
+			sb.WriteString(format.LineSeparator)
+		}
+		sb.WriteString(name)
+		sb.WriteString(format.NameSeparator)
+		if err == nil {
+			sb.WriteString(format.OKText)
+		} else {
+			failed = true
+			sb.WriteString(err.Error())
+		}
+	})
+	if sb.Len() == 0 {
+		sb.WriteString(format.OKText)
+	}
+	return sb.String(), failed
+}
@@ -0,0 +1,129 @@
+package summer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAppCheckStartupFunc(t *testing.T) {
+	a := Basic()
+
+	res := a.TestRequest("GET", DefaultStartupPath, nil)
+	require.Equal(t, http.StatusOK, res.Code)
+
+	a = Basic()
+	var warm bool
+	a.CheckStartupFunc("cache", func(ctx context.Context) error {
+		if !warm {
+			return errors.New("warming")
+		}
+		return nil
+	})
+	a.Component("db").Check(func(ctx context.Context) error {
+		return errors.New("down")
+	})
+
+	res = a.TestRequest("GET", DefaultStartupPath, nil)
+	require.Equal(t, http.StatusServiceUnavailable, res.Code)
+	require.Equal(t, "cache: warming", res.Body.String())
+
+	warm = true
+	res = a.TestRequest("GET", DefaultStartupPath, nil)
+	require.Equal(t, http.StatusOK, res.Code)
+	require.Equal(t, "cache: OK", res.Body.String())
+
+	// latched once succeeded
+	warm = false
+	res = a.TestRequest("GET", DefaultStartupPath, nil)
+	require.Equal(t, http.StatusOK, res.Code)
+	require.Equal(t, "OK", res.Body.String())
+
+	// registry checks are only used by readiness
+	require.Equal(t, http.StatusOK, a.TestRequest("GET", DefaultLivenessPath, nil).Code)
+	require.Equal(t, http.StatusInternalServerError, a.TestRequest("GET", DefaultReadinessPath, nil).Code)
+}
+
+func TestAppCheckLiveFunc(t *testing.T) {
+	a := Basic(WithStartupPath("/debug/started"))
+	a.CheckLiveFunc("loop", func(ctx context.Context) error {
+		return nil
+	})
+	a.CheckLiveFunc("deadlock", func(ctx context.Context) error {
+		return nil
+	})
+
+	res := a.TestRequest("GET", DefaultLivenessPath, nil)
+	require.Equal(t, http.StatusOK, res.Code)
+	require.Equal(t, "loop: OK\ndeadlock: OK", res.Body.String())
+
+	a.CheckLiveFunc("deadlock", func(ctx context.Context) error {
+		return errors.New("stuck")
+	})
+	res = a.TestRequest("GET", DefaultLivenessPath, nil)
+	require.Equal(t, http.StatusInternalServerError, res.Code)
+	require.Equal(t, "loop: OK\ndeadlock: stuck", res.Body.String())
+
+	require.Equal(t, http.StatusOK, a.TestRequest("GET", "/debug/started", nil).Code)
+	require.Equal(t, http.StatusOK, a.TestRequest("GET", DefaultReadinessPath, nil).Code)
+}
+
+func TestAppReadinessJSON(t *testing.T) {
+	a := Basic(WithCheckTimeout(time.Millisecond * 50))
+	a.Component("db").Check(func(ctx context.Context) error {
+		return nil
+	})
+
+	report := func(res *httptest.ResponseRecorder) (r ReadinessReport) {
+		require.Equal(t, ContentTypeApplicationJSONUTF8, res.Header().Get("Content-Type"))
+		require.NoError(t, json.Unmarshal(res.Body.Bytes(), &r))
+		return
+	}
+
+	res := a.TestRequest("GET", DefaultReadinessPath+"?format=json", nil)
+	require.Equal(t, http.StatusOK, res.Code)
+	r := report(res)
+	require.Equal(t, ReadinessStatusOK, r.Status)
+	require.Len(t, r.Checks, 1)
+	require.Equal(t, "db", r.Checks[0].Name)
+	require.Equal(t, CheckStatusOK, r.Checks[0].Status)
+	require.Empty(t, r.Checks[0].Error)
+
+	a.Component("cache").Check(func(ctx context.Context) error {
+		return errors.New("down")
+	})
+	a.Component("queue").Check(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	req := httptest.NewRequest("GET", DefaultReadinessPath, nil)
+	req.Header.Set("Accept", "application/json")
+	res = httptest.NewRecorder()
+	a.ServeHTTP(res, req)
+	require.Equal(t, http.StatusInternalServerError, res.Code)
+	r = report(res)
+	require.Equal(t, ReadinessStatusFailed, r.Status)
+	require.Len(t, r.Checks, 3)
+	require.Equal(t, CheckStatusFailed, r.Checks[1].Status)
+	require.Equal(t, "down", r.Checks[1].Error)
+	require.Equal(t, CheckStatusTimeout, r.Checks[2].Status)
+	require.Equal(t, "check timed out after 50ms", r.Checks[2].Error)
+	require.GreaterOrEqual(t, r.Checks[2].LatencyMS, float64(50))
+
+	// plain text by default
+	res = a.TestRequest("GET", DefaultReadinessPath, nil)
+	require.Equal(t, http.StatusInternalServerError, res.Code)
+	require.Equal(t, "db: OK\ncache: down\nqueue: check timed out after 50ms", res.Body.String())
+
+	atomic.StoreInt32(&a.(*app[Context]).draining, 1)
+	res = a.TestRequest("GET", DefaultReadinessPath+"?format=json", nil)
+	require.Equal(t, http.StatusServiceUnavailable, res.Code)
+	require.Equal(t, ReadinessStatusDraining, report(res).Status)
+}
@@ -0,0 +1,201 @@
+package summer
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimitStrategy algorithm of rate limiting
+type RateLimitStrategy int
+
+const (
+	// RateLimitTokenBucket token bucket, refilled at Limit per Period, allowing bursts up to Burst
+	RateLimitTokenBucket RateLimitStrategy = iota
+	// RateLimitSlidingWindow sliding window, at most Limit requests in any Period, approximated by weighting previous window
+	RateLimitSlidingWindow
+)
+
+// RateLimitKeyFunc returns key of a request, requests with the same key share a limit
+type RateLimitKeyFunc func(req *http.Request) string
+
+// RateLimitStore storage of rate limiting states
+//
+// The built-in store keeps states in memory of current process, implement it with a shared storage like Redis for distributed rate limiting
+type RateLimitStore interface {
+	// Take consume one request of key under rl, returns false and duration to wait before retrying if rejected
+	Take(ctx context.Context, key string, rl RateLimit) (allowed bool, retryAfter time.Duration, err error)
+}
+
+// RateLimit rate limiting of a route, see [WithRateLimit]
+type RateLimit struct {
+	// Limit requests allowed per Period for each key, a value <= 0 means unlimited
+	Limit int
+	// Period period of Limit, defaults to 1 second
+	Period time.Duration
+	// Burst capacity of token bucket, defaults to Limit, ignored by sliding window
+	Burst int
+	// Strategy algorithm of rate limiting, defaults to [RateLimitTokenBucket]
+	Strategy RateLimitStrategy
+	// Key key of requests, defaults to [RateLimitByIP], keys should be derived from values the client can not forge freely
+	Key RateLimitKeyFunc
+	// Store storage of states, defaults to an in-memory store of the route, share a store among routes to share limits by key
+	Store RateLimitStore
+}
+
+// RateLimitByIP key requests by client ip, forwarded headers are honored only for trusted proxies set by [WithTrustedProxies]
+func RateLimitByIP() RateLimitKeyFunc {
+	return func(req *http.Request) string {
+		return optionsFromContext(req.Context()).clientIP(req)
+	}
+}
+
+// RateLimitByHeader key requests by value of header, like an api key, falls back to client ip if header is absent
+//
+// The header is NOT verified, rate limiting runs before middlewares like [Authenticate]. A client can send a new value
+// for each request to evade the limit, and grow states of the in-memory store without bound until swept.
+// Use it only behind a proxy or gateway verifying the header, or key on a verified identity with a custom [RateLimitKeyFunc]
+func RateLimitByHeader(name string) RateLimitKeyFunc {
+	byIP := RateLimitByIP()
+	return func(req *http.Request) string {
+		if v := req.Header.Get(name); v != "" {
+			return "header:" + v
+		}
+		return "ip:" + byIP(req)
+	}
+}
+
+// rateLimiter rate limiter of a single route
+type rateLimiter struct {
+	rl RateLimit
+}
+
+func newRateLimiter(rl RateLimit) *rateLimiter {
+	if rl.Period <= 0 {
+		rl.Period = time.Second
+	}
+	if rl.Burst <= 0 {
+		rl.Burst = rl.Limit
+	}
+	if rl.Key == nil {
+		rl.Key = RateLimitByIP()
+	}
+	if rl.Store == nil {
+		rl.Store = NewMemoryRateLimitStore()
+	}
+	return &rateLimiter{rl: rl}
+}
+
+// take consume one request of req
+func (l *rateLimiter) take(req *http.Request) (bool, time.Duration, error) {
+	return l.rl.Store.Take(req.Context(), l.rl.Key(req), l.rl)
+}
+
+// retryAfterSeconds format d as value of "Retry-After" header, rounded up to at least 1 second
+func retryAfterSeconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Max(1, math.Ceil(d.Seconds()))), 10)
+}
+
+// memoryRateLimitSweepInterval minimum interval between sweeping expired states of [NewMemoryRateLimitStore]
+const memoryRateLimitSweepInterval = time.Minute
+
+type memoryRateLimitState struct {
+	// token bucket
+	tokens float64
+	// sliding window
+	windowStart time.Time
+	prevCount   float64
+	currCount   float64
+
+	updatedAt time.Time
+	expiresAt time.Time
+}
+
+type memoryRateLimitStore struct {
+	mu      sync.Mutex
+	states  map[string]*memoryRateLimitState
+	sweptAt time.Time
+	now     func() time.Time
+}
+
+// NewMemoryRateLimitStore create a [RateLimitStore] keeping states in memory, supporting all [RateLimitStrategy]
+//
+// Expired states are swept periodically
+func NewMemoryRateLimitStore() RateLimitStore {
+	return &memoryRateLimitStore{states: map[string]*memoryRateLimitState{}, now: time.Now}
+}
+
+func (s *memoryRateLimitStore) Take(ctx context.Context, key string, rl RateLimit) (allowed bool, retryAfter time.Duration, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	st := s.states[key]
+	if st == nil {
+		st = &memoryRateLimitState{tokens: float64(rl.Burst), windowStart: now, updatedAt: now}
+		s.states[key] = st
+	}
+
+	switch rl.Strategy {
+	case RateLimitSlidingWindow:
+		allowed, retryAfter = st.takeSlidingWindow(now, rl)
+		st.expiresAt = st.windowStart.Add(rl.Period * 2)
+	default:
+		allowed, retryAfter = st.takeTokenBucket(now, rl)
+		st.expiresAt = now.Add(time.Duration(float64(rl.Period) * float64(rl.Burst) / float64(rl.Limit)))
+	}
+	return
+}
+
+// sweep remove expired states, at most once per memoryRateLimitSweepInterval
+func (s *memoryRateLimitStore) sweep(now time.Time) {
+	if now.Sub(s.sweptAt) < memoryRateLimitSweepInterval {
+		return
+	}
+	s.sweptAt = now
+	for key, st := range s.states {
+		if now.After(st.expiresAt) {
+			delete(s.states, key)
+		}
+	}
+}
+
+func (st *memoryRateLimitState) takeTokenBucket(now time.Time, rl RateLimit) (bool, time.Duration) {
+	rate := float64(rl.Limit) / float64(rl.Period)
+	st.tokens = math.Min(float64(rl.Burst), st.tokens+float64(now.Sub(st.updatedAt))*rate)
+	st.updatedAt = now
+	if st.tokens >= 1 {
+		st.tokens--
+		return true, 0
+	}
+	return false, time.Duration(math.Ceil((1 - st.tokens) / rate))
+}
+
+func (st *memoryRateLimitState) takeSlidingWindow(now time.Time, rl RateLimit) (bool, time.Duration) {
+	// roll windows
+	if elapsed := now.Sub(st.windowStart); elapsed >= rl.Period*2 {
+		st.windowStart, st.prevCount, st.currCount = now, 0, 0
+	} else if elapsed >= rl.Period {
+		st.windowStart, st.prevCount, st.currCount = st.windowStart.Add(rl.Period), st.currCount, 0
+	}
+
+	elapsed := now.Sub(st.windowStart)
+	weight := 1 - float64(elapsed)/float64(rl.Period)
+	if st.prevCount*weight+st.currCount+1 <= float64(rl.Limit) {
+		st.currCount++
+		return true, 0
+	}
+
+	// wait for previous window sliding out enough, or the next window
+	retryAfter := rl.Period - elapsed
+	if st.prevCount > 0 && st.currCount+1 <= float64(rl.Limit) {
+		excess := st.prevCount*weight + st.currCount + 1 - float64(rl.Limit)
+		retryAfter = time.Duration(math.Ceil(excess / st.prevCount * float64(rl.Period)))
+	}
+	return false, retryAfter
+}
@@ -0,0 +1,155 @@
+package summer
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWithRateLimit(t *testing.T) {
+	a := Basic()
+	a.HandleFunc("/test-rate-limit-ip", func(ctx Context) {
+		ctx.Text("OK")
+	}, WithRateLimit(RateLimit{Limit: 2, Period: time.Minute}))
+	a.HandleFunc("/test-rate-limit-header", func(ctx Context) {
+		ctx.Text("OK")
+	}, WithRateLimit(RateLimit{Limit: 1, Period: time.Minute, Strategy: RateLimitSlidingWindow, Key: RateLimitByHeader("X-API-Key")}))
+
+	request := func(path string, remoteAddr string, key string) *httptest.ResponseRecorder {
+		rw, req := httptest.NewRecorder(), httptest.NewRequest("GET", "https://example.com"+path, nil)
+		req.RemoteAddr = remoteAddr
+		if key != "" {
+			req.Header.Set("X-API-Key", key)
+		}
+		a.ServeHTTP(rw, req)
+		return rw
+	}
+
+	require.Equal(t, http.StatusOK, request("/test-rate-limit-ip", "10.0.0.1:1234", "").Code)
+	require.Equal(t, http.StatusOK, request("/test-rate-limit-ip", "10.0.0.1:1235", "").Code)
+	rw := request("/test-rate-limit-ip", "10.0.0.1:1236", "")
+	require.Equal(t, http.StatusTooManyRequests, rw.Code)
+	require.Equal(t, "TOO MANY REQUESTS", rw.Body.String())
+	require.Equal(t, "30", rw.Header().Get("Retry-After"))
+	require.Equal(t, http.StatusOK, request("/test-rate-limit-ip", "10.0.0.2:1234", "").Code)
+
+	require.Equal(t, http.StatusOK, request("/test-rate-limit-header", "10.0.0.1:1234", "key-1").Code)
+	require.Equal(t, http.StatusTooManyRequests, request("/test-rate-limit-header", "10.0.0.2:1234", "key-1").Code)
+	require.Equal(t, http.StatusOK, request("/test-rate-limit-header", "10.0.0.1:1234", "key-2").Code)
+	require.Equal(t, http.StatusOK, request("/test-rate-limit-header", "10.0.0.1:1234", "").Code)
+	require.Equal(t, http.StatusTooManyRequests, request("/test-rate-limit-header", "10.0.0.1:1234", "").Code)
+
+	res := a.TestRequest("GET", "/debug/metrics", nil)
+	require.Contains(t, res.Body.String(), `summer_rejected_requests_total{reason="rate_limited"} 3`)
+}
+
+type testRateLimitStore struct {
+	err error
+}
+
+func (s *testRateLimitStore) Take(ctx context.Context, key string, rl RateLimit) (bool, time.Duration, error) {
+	return false, time.Second, s.err
+}
+
+func TestWithRateLimitStore(t *testing.T) {
+	store := NewMemoryRateLimitStore()
+
+	a := Basic()
+	a.HandleFunc("/test-rate-limit-shared-1", func(ctx Context) {
+		ctx.Text("OK")
+	}, WithRateLimit(RateLimit{Limit: 1, Period: time.Minute, Store: store}))
+	a.HandleFunc("/test-rate-limit-shared-2", func(ctx Context) {
+		ctx.Text("OK")
+	}, WithRateLimit(RateLimit{Limit: 1, Period: time.Minute, Store: store}))
+	a.HandleFunc("/test-rate-limit-failing", func(ctx Context) {
+		ctx.Text("OK")
+	}, WithRateLimit(RateLimit{Limit: 1, Store: &testRateLimitStore{err: errors.New("store down")}}))
+	a.HandleFunc("/test-rate-limit-rejecting", func(ctx Context) {
+		ctx.Text("OK")
+	}, WithRateLimit(RateLimit{Limit: 1, Store: &testRateLimitStore{}}))
+
+	require.Equal(t, http.StatusOK, a.TestRequest("GET", "/test-rate-limit-shared-1", nil).Code)
+	require.Equal(t, http.StatusTooManyRequests, a.TestRequest("GET", "/test-rate-limit-shared-2", nil).Code)
+	require.Equal(t, http.StatusOK, a.TestRequest("GET", "/test-rate-limit-failing", nil).Code)
+
+	rw := a.TestRequest("GET", "/test-rate-limit-rejecting", nil)
+	require.Equal(t, http.StatusTooManyRequests, rw.Code)
+	require.Equal(t, "1", rw.Header().Get("Retry-After"))
+}
+
+func TestMemoryRateLimitStore(t *testing.T) {
+	now := time.Now()
+	s := NewMemoryRateLimitStore().(*memoryRateLimitStore)
+	s.now = func() time.Time { return now }
+
+	take := func(key string, rl RateLimit) (bool, time.Duration) {
+		allowed, retryAfter, err := s.Take(context.Background(), key, rl)
+		require.NoError(t, err)
+		return allowed, retryAfter
+	}
+
+	t.Run("token_bucket", func(t *testing.T) {
+		rl := RateLimit{Limit: 2, Period: time.Second, Burst: 4}
+		for i := 0; i < 4; i++ {
+			allowed, _ := take("tb", rl)
+			require.True(t, allowed)
+		}
+		allowed, retryAfter := take("tb", rl)
+		require.False(t, allowed)
+		require.Equal(t, time.Millisecond*500, retryAfter)
+
+		now = now.Add(time.Millisecond * 500)
+		allowed, _ = take("tb", rl)
+		require.True(t, allowed)
+		allowed, _ = take("tb", rl)
+		require.False(t, allowed)
+	})
+
+	t.Run("sliding_window", func(t *testing.T) {
+		rl := RateLimit{Limit: 4, Period: time.Second, Strategy: RateLimitSlidingWindow}
+		for i := 0; i < 4; i++ {
+			allowed, _ := take("sw", rl)
+			require.True(t, allowed)
+		}
+		allowed, retryAfter := take("sw", rl)
+		require.False(t, allowed)
+		require.Equal(t, time.Second, retryAfter)
+
+		// previous window weighted 3/4
+		now = now.Add(time.Millisecond * 1250)
+		allowed, _ = take("sw", rl)
+		require.True(t, allowed)
+		allowed, retryAfter = take("sw", rl)
+		require.False(t, allowed)
+		require.Equal(t, time.Millisecond*250, retryAfter)
+
+		now = now.Add(time.Millisecond * 250)
+		allowed, _ = take("sw", rl)
+		require.True(t, allowed)
+
+		// long idle resets
+		now = now.Add(time.Second * 2)
+		for i := 0; i < 4; i++ {
+			allowed, _ := take("sw", rl)
+			require.True(t, allowed)
+		}
+	})
+
+	t.Run("sweep", func(t *testing.T) {
+		require.NotEmpty(t, s.states)
+		now = now.Add(time.Hour)
+		take("new", RateLimit{Limit: 1, Period: time.Second, Burst: 1})
+		require.Len(t, s.states, 1)
+	})
+}
+
+func TestRetryAfterSeconds(t *testing.T) {
+	require.Equal(t, "1", retryAfterSeconds(0))
+	require.Equal(t, "1", retryAfterSeconds(time.Millisecond*10))
+	require.Equal(t, "2", retryAfterSeconds(time.Millisecond*1500))
+	require.Equal(t, "60", retryAfterSeconds(time.Minute))
+}
@@ -0,0 +1,35 @@
+package summer
+
+import (
+	"net/http"
+	"runtime/debug"
+)
+
+// RecoveryHandler function handling a recovered panic with stack, responsible for writing the response
+type RecoveryHandler func(ctx Context, p any, stack []byte)
+
+// responded check if a response is written or set to [Context]
+func responded(c Context) bool {
+	if w, ok := c.Res().(*responseWriter); ok && w.wroteHeader {
+		return true
+	}
+	if r, ok := any(c).(interface{ responded() bool }); ok {
+		return r.responded()
+	}
+	return false
+}
+
+// recoverWith recover a panic with [RecoveryHandler], must be called with defer
+//
+// Falls back to 500 plain text if fn does not write a response
+func recoverWith(c Context, fn RecoveryHandler) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	fn(c, r, debug.Stack())
+	if !responded(c) {
+		c.Code(http.StatusInternalServerError)
+		c.Text(http.StatusText(http.StatusInternalServerError))
+	}
+}
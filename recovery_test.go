@@ -0,0 +1,60 @@
+package summer
+
+import (
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+)
+
+func TestRecoveryHandler(t *testing.T) {
+	var (
+		recovered any
+		stack     []byte
+	)
+
+	a := Basic(WithRecoveryHandler(func(ctx Context, p any, s []byte) {
+		recovered, stack = p, s
+		if ctx.Req().URL.Query().Get("respond") != "" {
+			ctx.Code(http.StatusTeapot)
+			ctx.Text("RECOVERED")
+		}
+	}))
+	a.HandleFunc("/test", func(ctx Context) {
+		panic("WWW")
+	})
+
+	rw := a.TestRequest("GET", "/test?respond=1", nil)
+	require.Equal(t, http.StatusTeapot, rw.Code)
+	require.Equal(t, "RECOVERED", rw.Body.String())
+	require.Equal(t, "WWW", recovered)
+	require.Contains(t, string(stack), "recovery_test.go")
+
+	rw = a.TestRequest("GET", "/test", nil)
+	require.Equal(t, http.StatusInternalServerError, rw.Code)
+	require.Equal(t, "Internal Server Error", rw.Body.String())
+}
+
+func TestAppRecoverEscaped(t *testing.T) {
+	a := New(func(rw http.ResponseWriter, req *http.Request) Context {
+		if req.URL.Query().Get("panic") != "" {
+			panic("factory failed")
+		}
+		return BasicContext(rw, req)
+	})
+	a.HandleFunc("/test-recover-escaped", func(ctx Context) {
+		ctx.Text("OK")
+	})
+
+	counter := a.(*app[Context]).mPanics.WithLabelValues("/test-recover-escaped")
+	before := testutil.ToFloat64(counter)
+
+	rw := a.TestRequest("GET", "/test-recover-escaped?panic=1", nil)
+	require.Equal(t, http.StatusInternalServerError, rw.Code)
+	require.Equal(t, "INTERNAL SERVER ERROR", rw.Body.String())
+	require.Equal(t, before+1, testutil.ToFloat64(counter))
+
+	rw = a.TestRequest("GET", "/test-recover-escaped", nil)
+	require.Equal(t, "OK", rw.Body.String())
+	require.Equal(t, before+1, testutil.ToFloat64(counter))
+}
@@ -3,9 +3,15 @@ package summer
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sort"
 	"sync"
+	"time"
 )
 
+// ErrCheckTimeout error of a check function not finished in time, see [RegistryWithCheckTimeout]
+var ErrCheckTimeout = errors.New("check timed out")
+
 // InjectFunc inject function for component
 type InjectFunc func(ctx context.Context, c Context) context.Context
 
@@ -18,16 +24,38 @@ type Registry interface {
 	// In order of `startup`, `check` and `shutdown`
 	Component(name string) Registration
 
-	// Startup start all registered components
+	// OnStart register a hook called by [Registry.Startup], in registration order along with startup functions of components
+	//
+	// Hooks are not checked, a failed hook aborts startup like a component. Registering after startup causes a panic
+	OnStart(fn LifecycleFunc)
+
+	// OnStop register a hook called by [Registry.Shutdown], in reverse registration order along with shutdown functions of components
+	//
+	// Hooks are called even if registered after startup
+	OnStop(fn LifecycleFunc)
+
+	// Startup start all registered components, in registration order
+	//
+	// If a startup function fails, components already started are shutdown in reverse order
 	Startup(ctx context.Context) (err error)
 
-	// Check run all checks
+	// Check run all checks concurrently, fn is called in registration order after all checks finished
 	Check(ctx context.Context, fn func(name string, err error))
 
+	// RemoveCheck remove check of a registered component, the component is excluded from [Registry.Check]
+	//
+	// Setting a check function with [Registration.Check] includes the component again
+	RemoveCheck(name string)
+
+	// CheckNames returns sorted names of components included in [Registry.Check]
+	CheckNames() []string
+
 	// Inject execute all inject funcs with [Context]
 	Inject(c Context)
 
-	// Shutdown shutdown all registered components
+	// Shutdown shutdown all started components in reverse registration order, components depending on earlier ones are shutdown first
+	//
+	// In-flight checks are waited until finished or ctx is done, errors of all shutdown functions are joined
 	Shutdown(ctx context.Context) (err error)
 }
 
@@ -53,8 +81,11 @@ type registration struct {
 	name     string
 	startup  LifecycleFunc
 	check    LifecycleFunc
+	uncheck  bool
 	shutdown LifecycleFunc
 	inject   InjectFunc
+	// hooked registered by hook, name of which never conflicts with components
+	hooked bool
 }
 
 func (r *registration) Name() string {
@@ -68,6 +99,7 @@ func (r *registration) Startup(fn LifecycleFunc) Registration {
 
 func (r *registration) Check(fn LifecycleFunc) Registration {
 	r.check = fn
+	r.uncheck = false
 	return r
 }
 
@@ -83,8 +115,15 @@ func (r *registration) Inject(fn InjectFunc) Registration {
 
 type registry struct {
 	mu   sync.Locker
+	wg   sync.WaitGroup
 	regs []*registration
 	init []*registration
+
+	started bool
+
+	services services
+
+	checkTimeout time.Duration
 }
 
 func (a *registry) Component(name string) Registration {
@@ -92,7 +131,7 @@ func (a *registry) Component(name string) Registration {
 	defer a.mu.Unlock()
 
 	for _, item := range a.regs {
-		if item.name == name {
+		if item.name == name && !item.hooked {
 			panic("duplicated component with name: " + name)
 		}
 	}
@@ -106,16 +145,47 @@ func (a *registry) Component(name string) Registration {
 	return reg
 }
 
+// hook register a registration excluded from checks, named only for errors and logs
+func (a *registry) hook(reg *registration) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	reg.uncheck = true
+	reg.hooked = true
+	a.regs = append(a.regs, reg)
+	if reg.startup == nil && a.started {
+		a.init = append(a.init, reg)
+	}
+}
+
+func (a *registry) OnStart(fn LifecycleFunc) {
+	a.mu.Lock()
+	started := a.started
+	a.mu.Unlock()
+	// would never be called until next startup
+	if started {
+		panic("summer: OnStart registered after startup")
+	}
+	a.hook(&registration{startup: fn})
+}
+
+func (a *registry) OnStop(fn LifecycleFunc) {
+	a.hook(&registration{shutdown: fn})
+}
+
 func (a *registry) Startup(ctx context.Context) (err error) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
 	defer func() {
 		if err == nil {
+			a.started = true
 			return
 		}
-		for _, item := range a.init {
-			_ = item.shutdown(ctx)
+		for i := len(a.init) - 1; i >= 0; i-- {
+			if item := a.init[i]; item.shutdown != nil {
+				_ = item.shutdown(ctx)
+			}
 		}
 		a.init = nil
 	}()
@@ -133,22 +203,118 @@ func (a *registry) Startup(ctx context.Context) (err error) {
 }
 
 func (a *registry) Check(ctx context.Context, fn func(name string, err error)) {
+	a.checkTimed(ctx, func(name string, err error, d time.Duration) {
+		fn(name, err)
+	})
+}
+
+// checkTimed like [Registry.Check], with duration of each check
+func (a *registry) checkTimed(ctx context.Context, fn func(name string, err error, d time.Duration)) {
+	a.mu.Lock()
+	var regs []registration
+	for _, item := range a.regs {
+		if item.uncheck {
+			continue
+		}
+		regs = append(regs, *item)
+	}
+	a.wg.Add(1)
+	a.mu.Unlock()
+
+	defer a.wg.Done()
+
+	// run checks concurrently, report in registration order
+	errs := make([]error, len(regs))
+	durations := make([]time.Duration, len(regs))
+	var wg sync.WaitGroup
+	for i, item := range regs {
+		if item.check == nil {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, check LifecycleFunc) {
+			defer wg.Done()
+			start := time.Now()
+			errs[i] = a.runCheck(ctx, check)
+			durations[i] = time.Since(start)
+		}(i, item.check)
+	}
+	wg.Wait()
+
+	for i, item := range regs {
+		fn(item.name, errs[i], durations[i])
+	}
+}
+
+// runCheck run a check function, with timeout set by [RegistryWithCheckTimeout]
+//
+// A timed out check is abandoned, and waited by [Registry.Shutdown]
+func (a *registry) runCheck(ctx context.Context, check LifecycleFunc) error {
+	if a.checkTimeout <= 0 {
+		return callCheck(ctx, check)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, a.checkTimeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		done <- callCheck(ctx, check)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("%w after %s", ErrCheckTimeout, a.checkTimeout)
+		}
+		return ctx.Err()
+	}
+}
+
+// callCheck call a check function, a panic is recovered as error of the check
+func callCheck(ctx context.Context, check LifecycleFunc) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return check(ctx)
+}
+
+func (a *registry) RemoveCheck(name string) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
 	for _, item := range a.regs {
-		if item.check == nil {
-			fn(item.name, nil)
-		} else {
-			fn(item.name, item.check(ctx))
+		if item.name == name {
+			item.check = nil
+			item.uncheck = true
+		}
+	}
+}
+
+func (a *registry) CheckNames() (names []string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for _, item := range a.regs {
+		if item.uncheck {
+			continue
 		}
+		names = append(names, item.name)
 	}
 
+	sort.Strings(names)
 	return
 }
 
 func (a *registry) Inject(c Context) {
 	c.Inject(func(ctx context.Context) context.Context {
+		ctx = context.WithValue(ctx, registryContextKey{}, Registry(a))
 		for _, item := range a.regs {
 			if item.inject == nil {
 				continue
@@ -164,7 +330,13 @@ func (a *registry) Shutdown(ctx context.Context) (err error) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
-	for _, item := range a.init {
+	waitGroupWait(ctx, &a.wg)
+
+	for i := len(a.init) - 1; i >= 0; i-- {
+		item := a.init[i]
+		if item.shutdown == nil {
+			continue
+		}
 		if err1 := item.shutdown(ctx); err1 != nil {
 			if err == nil {
 				err = err1
@@ -175,10 +347,32 @@ func (a *registry) Shutdown(ctx context.Context) (err error) {
 	}
 
 	a.init = nil
+	a.started = false
 
 	return
 }
 
-func NewRegistry() Registry {
-	return &registry{mu: &sync.Mutex{}}
+// RegistryOption configuration function for [NewRegistry]
+type RegistryOption func(r *registry)
+
+// RegistryWithCheckTimeout set timeout of each check function in [Registry.Check], timed out checks result in [ErrCheckTimeout]
+//
+// A value <= 0 means no timeout
+func RegistryWithCheckTimeout(d time.Duration) RegistryOption {
+	return func(r *registry) {
+		r.checkTimeout = d
+	}
+}
+
+// NewRegistry create a new [Registry]
+func NewRegistry(opts ...RegistryOption) Registry {
+	return newRegistry(opts...)
+}
+
+func newRegistry(opts ...RegistryOption) *registry {
+	r := &registry{mu: &sync.Mutex{}}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
 }
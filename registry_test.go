@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"github.com/stretchr/testify/require"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestRegistry(t *testing.T) {
@@ -124,3 +126,171 @@ func TestRegistryFailed(t *testing.T) {
 	require.False(t, t2b)
 	require.False(t, t2c)
 }
+
+func TestRegistryShutdownWaitCheck(t *testing.T) {
+	a := NewRegistry()
+
+	started := make(chan struct{})
+	a.Component("test-1").
+		Check(func(ctx context.Context) (err error) {
+			close(started)
+			time.Sleep(time.Millisecond * 100)
+			return errors.New("slow")
+		}).
+		Shutdown(func(ctx context.Context) (err error) {
+			return
+		})
+
+	require.NoError(t, a.Startup(context.Background()))
+
+	var result atomic.Value
+	go a.Check(context.Background(), func(name string, err error) {
+		result.Store(err)
+	})
+
+	<-started
+	require.NoError(t, a.Shutdown(context.Background()))
+	require.Equal(t, "slow", result.Load().(error).Error())
+}
+
+func TestRegistryRemoveCheck(t *testing.T) {
+	a := NewRegistry()
+	a.Component("test-2").Check(func(ctx context.Context) (err error) {
+		return errors.New("bad")
+	})
+	reg := a.Component("test-1")
+
+	require.Equal(t, []string{"test-1", "test-2"}, a.CheckNames())
+
+	a.RemoveCheck("test-2")
+	require.Equal(t, []string{"test-1"}, a.CheckNames())
+
+	var names []string
+	a.Check(context.Background(), func(name string, err error) {
+		require.NoError(t, err)
+		names = append(names, name)
+	})
+	require.Equal(t, []string{"test-1"}, names)
+
+	a.RemoveCheck("test-1")
+	require.Empty(t, a.CheckNames())
+
+	reg.Check(func(ctx context.Context) (err error) {
+		return
+	})
+	require.Equal(t, []string{"test-1"}, a.CheckNames())
+}
+
+func TestRegistryCheckConcurrent(t *testing.T) {
+	a := NewRegistry(RegistryWithCheckTimeout(time.Millisecond * 200))
+
+	release := make(chan struct{})
+	var running int64
+	for _, name := range []string{"test-1", "test-2", "test-3"} {
+		a.Component(name).Check(func(ctx context.Context) error {
+			atomic.AddInt64(&running, 1)
+			<-release
+			return nil
+		})
+	}
+	a.Component("test-slow").Check(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	a.Component("test-failed").Check(func(ctx context.Context) error {
+		return errors.New("failed")
+	})
+	a.Component("test-none")
+
+	go func() {
+		for atomic.LoadInt64(&running) < 3 {
+			time.Sleep(time.Millisecond)
+		}
+		close(release)
+	}()
+
+	var names []string
+	var errs []error
+	a.Check(context.Background(), func(name string, err error) {
+		names = append(names, name)
+		errs = append(errs, err)
+	})
+
+	require.Equal(t, []string{"test-1", "test-2", "test-3", "test-slow", "test-failed", "test-none"}, names)
+	require.NoError(t, errs[0])
+	require.NoError(t, errs[1])
+	require.NoError(t, errs[2])
+	require.ErrorIs(t, errs[3], ErrCheckTimeout)
+	require.Equal(t, "check timed out after 200ms", errs[3].Error())
+	require.EqualError(t, errs[4], "failed")
+	require.NoError(t, errs[5])
+}
+
+func TestRegistryHooks(t *testing.T) {
+	a := NewRegistry()
+
+	var calls []string
+	record := func(name string, err error) LifecycleFunc {
+		return func(ctx context.Context) error {
+			calls = append(calls, name)
+			return err
+		}
+	}
+
+	a.OnStart(record("start-1", nil))
+	a.OnStop(record("stop-1", nil))
+	a.Component("test-1").
+		Startup(record("test-1-startup", nil)).
+		Check(func(ctx context.Context) error { return nil }).
+		Shutdown(record("test-1-shutdown", nil))
+	a.Component("test-2").
+		Check(func(ctx context.Context) error { return nil })
+	a.OnStart(record("start-2", nil))
+	a.OnStop(record("stop-2", nil))
+
+	require.Equal(t, []string{"test-1", "test-2"}, a.CheckNames())
+
+	require.NoError(t, a.Startup(context.Background()))
+	require.Equal(t, []string{"start-1", "test-1-startup", "start-2"}, calls)
+
+	// registered after startup
+	a.OnStop(record("stop-3", nil))
+	require.PanicsWithValue(t, "summer: OnStart registered after startup", func() {
+		a.OnStart(record("start-3", nil))
+	})
+
+	calls = nil
+	require.NoError(t, a.Shutdown(context.Background()))
+	require.Equal(t, []string{"stop-3", "stop-2", "test-1-shutdown", "stop-1"}, calls)
+
+	// failed startup
+	b := NewRegistry()
+	b.OnStop(record("stop-1", nil))
+	b.OnStart(record("start-1", nil))
+	b.OnStop(record("stop-2", nil))
+	b.OnStart(record("start-2", errors.New("failed")))
+	b.OnStop(record("stop-3", nil))
+
+	calls = nil
+	require.EqualError(t, b.Startup(context.Background()), "failed")
+	require.Equal(t, []string{"start-1", "start-2", "stop-2", "stop-1"}, calls)
+}
+
+func TestRegistryCheckPanic(t *testing.T) {
+	for _, timeout := range []time.Duration{0, time.Second} {
+		a := NewRegistry(RegistryWithCheckTimeout(timeout))
+		a.Component("test-panic").Check(func(ctx context.Context) error {
+			panic("boom")
+		})
+		a.Component("test-ok").Check(func(ctx context.Context) error {
+			return nil
+		})
+
+		errs := map[string]error{}
+		a.Check(context.Background(), func(name string, err error) {
+			errs[name] = err
+		})
+		require.EqualError(t, errs["test-panic"], "panic: boom")
+		require.NoError(t, errs["test-ok"])
+	}
+}
@@ -0,0 +1,52 @@
+package summer
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+const maxRequestIDLength = 128
+
+type contextKeyRequestID struct{}
+
+// newRequestID generate a random request id of 32 hex characters
+func newRequestID() string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+// validRequestID check if an inbound request id is safe to honor, non-empty, not too long and printable ASCII only
+func validRequestID(s string) bool {
+	if s == "" || len(s) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < 0x21 || s[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// requestIDOf returns request id already in context like a forwarded request, from header set by [WithRequestIDHeader], or a generated one, empty if disabled
+func (opts *options) requestIDOf(req *http.Request) string {
+	if opts.requestIDHeader == "" {
+		return ""
+	}
+	if id := requestIDFromContext(req.Context()); id != "" {
+		return id
+	}
+	if id := req.Header.Get(opts.requestIDHeader); validRequestID(id) {
+		return id
+	}
+	return newRequestID()
+}
+
+// requestIDFromContext returns request id stored in ctx
+func requestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKeyRequestID{}).(string)
+	return id
+}
@@ -0,0 +1,56 @@
+package summer
+
+import (
+	"github.com/stretchr/testify/require"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestValidRequestID(t *testing.T) {
+	require.True(t, validRequestID("abc-123"))
+	require.False(t, validRequestID(""))
+	require.False(t, validRequestID("abc 123"))
+	require.False(t, validRequestID("abc\n123"))
+	require.False(t, validRequestID(strings.Repeat("a", maxRequestIDLength+1)))
+	require.Len(t, newRequestID(), 32)
+}
+
+func TestAppRequestID(t *testing.T) {
+	var entries []AccessLogEntry
+
+	a := Basic(WithAccessLogger(func(entry AccessLogEntry) {
+		entries = append(entries, entry)
+	}))
+	a.HandleFunc("/test", func(ctx Context) {
+		ctx.Text(ctx.RequestID())
+	})
+	a.HandleFunc("/forward", func(ctx Context) {
+		require.NoError(t, ctx.Forward("/test"))
+	})
+
+	rw, req := httptest.NewRecorder(), httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set("X-Request-Id", "abc-123")
+	a.ServeHTTP(rw, req)
+	require.Equal(t, "abc-123", rw.Body.String())
+	require.Equal(t, "abc-123", rw.Header().Get("X-Request-Id"))
+	require.Equal(t, "abc-123", entries[0].RequestID)
+
+	rw, req = httptest.NewRecorder(), httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set("X-Request-Id", "invalid id")
+	a.ServeHTTP(rw, req)
+	require.Len(t, rw.Body.String(), 32)
+	require.Equal(t, rw.Body.String(), rw.Header().Get("X-Request-Id"))
+
+	rw = a.TestRequest("GET", "/forward", nil)
+	require.Len(t, rw.Body.String(), 32)
+	require.Equal(t, rw.Body.String(), rw.Header().Get("X-Request-Id"))
+
+	a = Basic(WithRequestIDHeader(""))
+	a.HandleFunc("/test", func(ctx Context) {
+		ctx.Text(ctx.RequestID())
+	})
+	rw = a.TestRequest("GET", "/test", nil)
+	require.Empty(t, rw.Body.String())
+	require.Empty(t, rw.Header().Get("X-Request-Id"))
+}
@@ -0,0 +1,127 @@
+package summer
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"net/http"
+)
+
+// ResponseWriter a [http.ResponseWriter] capturing status code, bytes written and whether headers were sent
+//
+// [Context.Res] implements it when served by [App]
+type ResponseWriter interface {
+	http.ResponseWriter
+
+	// Status returns status code sent, or 200 if headers not sent yet
+	Status() int
+
+	// BytesWritten returns number of body bytes written
+	BytesWritten() int64
+
+	// Written returns true if headers were sent
+	Written() bool
+}
+
+var (
+	_ ResponseWriter = &responseWriter{}
+)
+
+// responseWriter a [http.ResponseWriter] wrapper capturing status code and bytes written
+type responseWriter struct {
+	http.ResponseWriter
+
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+func newResponseWriter(rw http.ResponseWriter) *responseWriter {
+	return &responseWriter{ResponseWriter: rw, status: http.StatusOK}
+}
+
+func (w *responseWriter) Status() int {
+	return w.status
+}
+
+func (w *responseWriter) BytesWritten() int64 {
+	return w.bytes
+}
+
+func (w *responseWriter) Written() bool {
+	return w.wroteHeader
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *responseWriter) Write(buf []byte) (n int, err error) {
+	w.wroteHeader = true
+	n, err = w.ResponseWriter.Write(buf)
+	w.bytes += int64(n)
+	return
+}
+
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		w.wroteHeader = true
+		f.Flush()
+	}
+}
+
+// Hijack implements [http.Hijacker], status is recorded as 101 Switching Protocols on success
+func (w *responseWriter) Hijack() (conn net.Conn, brw *bufio.ReadWriter, err error) {
+	if conn, brw, err = http.NewResponseController(w.ResponseWriter).Hijack(); err != nil {
+		return
+	}
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.status = http.StatusSwitchingProtocols
+	}
+	return
+}
+
+// Unwrap returns the underlying [http.ResponseWriter], for [http.ResponseController]
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// bufferedResponse a [http.ResponseWriter] buffering the whole response, can be replayed multiple times
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func newBufferedResponse() *bufferedResponse {
+	return &bufferedResponse{header: http.Header{}, status: http.StatusOK}
+}
+
+func (r *bufferedResponse) Header() http.Header {
+	return r.header
+}
+
+func (r *bufferedResponse) WriteHeader(code int) {
+	r.status = code
+}
+
+func (r *bufferedResponse) Write(buf []byte) (int, error) {
+	return r.body.Write(buf)
+}
+
+// replay write the buffered response to rw, "Set-Cookie" headers are skipped if shared
+func (r *bufferedResponse) replay(rw http.ResponseWriter, shared bool) {
+	for k, vs := range r.header {
+		if shared && k == "Set-Cookie" {
+			continue
+		}
+		rw.Header()[k] = append([]string{}, vs...)
+	}
+	rw.WriteHeader(r.status)
+	_, _ = rw.Write(r.body.Bytes())
+}
@@ -0,0 +1,53 @@
+package summer
+
+import (
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResponseWriter(t *testing.T) {
+	rec := httptest.NewRecorder()
+	w := newResponseWriter(rec)
+	require.Equal(t, http.StatusOK, w.status)
+	require.False(t, w.Written())
+
+	w.WriteHeader(http.StatusTeapot)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("hello"))
+	w.Flush()
+
+	require.Equal(t, http.StatusTeapot, w.status)
+	require.Equal(t, int64(5), w.bytes)
+	require.True(t, rec.Flushed)
+	require.Equal(t, rec, w.Unwrap())
+	require.Equal(t, http.StatusTeapot, w.Status())
+	require.Equal(t, int64(5), w.BytesWritten())
+	require.True(t, w.Written())
+}
+
+func TestAppPartialWrite(t *testing.T) {
+	a := Basic()
+	a.HandleFunc("/test", func(ctx Context) {
+		w, ok := ctx.Res().(ResponseWriter)
+		require.True(t, ok)
+		require.False(t, w.Written())
+		_, _ = ctx.Res().Write([]byte("partial"))
+		require.True(t, w.Written())
+		require.Equal(t, int64(7), w.BytesWritten())
+		panic("failed after partial write")
+	})
+
+	rw := a.TestRequest("GET", "/test", nil)
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Equal(t, "partial", rw.Body.String())
+}
+
+func TestResponseWriterHijack(t *testing.T) {
+	w := newResponseWriter(httptest.NewRecorder())
+	_, _, err := w.Hijack()
+	require.ErrorIs(t, err, http.ErrNotSupported)
+	require.False(t, w.wroteHeader)
+	require.Equal(t, http.StatusOK, w.status)
+}
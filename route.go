@@ -0,0 +1,96 @@
+package summer
+
+import (
+	"fmt"
+	"net/http"
+	"runtime"
+	"strings"
+	"sync/atomic"
+)
+
+// DuplicateRoutePolicy policy of registering a pattern already registered
+type DuplicateRoutePolicy int
+
+const (
+	// PanicOnDuplicate panic with call sites of both registrations
+	PanicOnDuplicate DuplicateRoutePolicy = iota
+	// OverwriteOnDuplicate replace the existing handler
+	OverwriteOnDuplicate
+)
+
+// route a registered route, handler can be swapped and route can be deregistered at runtime
+type route struct {
+	pattern      string
+	callSite     string
+	handler      atomic.Value
+	deregistered atomic.Bool
+	operation    atomic.Pointer[Operation]
+	// unthrottled registered by [App.HandleFuncUnthrottled], bypassing concurrency control
+	unthrottled atomic.Bool
+	// upgrade registered by [App.HandleFuncUpgrade]
+	upgrade atomic.Bool
+	// dedup deduplication of GET and HEAD requests, see [WithGetDeduplication]
+	dedup atomic.Bool
+}
+
+// swap replace handler and deduplication flag, deduplication is disabled while swapping,
+// so that waiters never receive a response of the replaced handler
+func (r *route) swap(h http.Handler, dedup bool) {
+	r.dedup.Store(false)
+	r.handler.Store(h)
+	r.dedup.Store(dedup)
+}
+
+func (r *route) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if r.deregistered.Load() {
+		http.NotFound(rw, req)
+		return
+	}
+	r.handler.Load().(http.Handler).ServeHTTP(rw, req)
+}
+
+// RouteEntry information of a registered route
+type RouteEntry struct {
+	// Pattern the full pattern as registered
+	Pattern string
+	// Method method extracted from pattern prefix like "GET /path", empty if not specified
+	Method string
+	// Path path portion of pattern
+	Path string
+	// RegistrationOrder zero-based order of registration
+	RegistrationOrder int
+}
+
+// newRouteEntry create a [RouteEntry] by splitting method prefix from pattern
+func newRouteEntry(pattern string, order int) RouteEntry {
+	entry := RouteEntry{Pattern: pattern, Path: pattern, RegistrationOrder: order}
+	if method, p, ok := strings.Cut(pattern, " "); ok && method != "" && !strings.Contains(method, "/") {
+		entry.Method = strings.ToUpper(method)
+		entry.Path = strings.TrimLeft(p, " \t")
+	}
+	return entry
+}
+
+type contextKeyForwarder struct{}
+
+// forwarder dispatch a request to a registered route, see [Context.Forward]
+type forwarder interface {
+	forward(rw http.ResponseWriter, req *http.Request, pattern string) error
+}
+
+const packagePrefix = "github.com/guoyk93/summer."
+
+// callSite returns file and line of the first caller outside this package
+func callSite() string {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, packagePrefix) || strings.HasSuffix(frame.File, "_test.go") {
+			return fmt.Sprintf("%s:%d", frame.File, frame.Line)
+		}
+		if !more {
+			return "unknown"
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package summer
+
+import (
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDuplicateRoutePolicy(t *testing.T) {
+	a := Basic()
+	a.HandleFunc("/test", func(ctx Context) {
+		ctx.Text("1")
+	})
+
+	var msg string
+	func() {
+		defer func() {
+			msg, _ = recover().(string)
+		}()
+		a.HandleFunc("/test", func(ctx Context) {
+			ctx.Text("2")
+		})
+	}()
+	require.Contains(t, msg, `duplicated route "/test"`)
+	require.Equal(t, 2, strings.Count(msg, "route_test.go"))
+	require.Equal(t, "1", a.TestRequest("GET", "/test", nil).Body.String())
+
+	a = Basic(WithDuplicateRoutePolicy(OverwriteOnDuplicate))
+	a.HandleFunc("/test", func(ctx Context) {
+		ctx.Text("1")
+	})
+	a.HandleFunc("/test", func(ctx Context) {
+		ctx.Text("2")
+	})
+	require.Equal(t, "2", a.TestRequest("GET", "/test", nil).Body.String())
+}
+
+func TestAppListRoutes(t *testing.T) {
+	a := Basic()
+	a.HandleFunc("/api/users", func(ctx Context) {})
+	a.HandleFunc("GET /api/items", func(ctx Context) {})
+	a.HandleFunc("POST /api/items", func(ctx Context) {})
+	a.HandleFunc("/other", func(ctx Context) {})
+
+	require.Equal(t, []string{"/api/users", "GET /api/items", "POST /api/items", "/other"}, a.Patterns())
+
+	require.Len(t, a.ListRoutes("", ""), 4)
+	require.Equal(t, []RouteEntry{
+		{Pattern: "/api/users", Path: "/api/users", RegistrationOrder: 0},
+		{Pattern: "GET /api/items", Method: "GET", Path: "/api/items", RegistrationOrder: 1},
+	}, a.ListRoutes("get", "/api/"))
+	require.Equal(t, []RouteEntry{
+		{Pattern: "POST /api/items", Method: "POST", Path: "/api/items", RegistrationOrder: 2},
+	}, a.ListRoutes("POST", "/api/items"))
+	require.Empty(t, a.ListRoutes("", "/none"))
+}
+
+func TestAppDeregisterRoute(t *testing.T) {
+	a := Basic()
+	a.HandleFunc("/test", func(ctx Context) {
+		ctx.Text("OK")
+	})
+
+	require.Error(t, a.DeregisterRoute("/none"))
+	require.Error(t, a.RegisterRoute("/none"))
+
+	require.NoError(t, a.DeregisterRoute("/test"))
+	require.Equal(t, http.StatusNotFound, a.TestRequest("GET", "/test", nil).Code)
+
+	require.NoError(t, a.RegisterRoute("/test"))
+	require.Equal(t, "OK", a.TestRequest("GET", "/test", nil).Body.String())
+}
+
+func TestAppReplaceRoute(t *testing.T) {
+	a := Basic()
+	a.HandleFunc("/test", func(ctx Context) {
+		ctx.Text("v1")
+	})
+
+	require.Error(t, a.ReplaceRoute("/none", func(ctx Context) {}))
+
+	require.NoError(t, a.ReplaceRoute("/test", func(ctx Context) {
+		ctx.Text("v2")
+	}, WithRequiredAccept([]string{ContentTypeApplicationJSON})))
+	require.Equal(t, []string{"/test"}, a.Patterns())
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set("Accept", ContentTypeApplicationJSON)
+	rw := httptest.NewRecorder()
+	a.ServeHTTP(rw, req)
+	require.Equal(t, "v2", rw.Body.String())
+	require.Equal(t, http.StatusNotAcceptable, a.TestRequest("GET", "/test", nil).Code)
+
+	require.NoError(t, a.DeregisterRoute("/test"))
+	require.NoError(t, a.ReplaceRoute("/test", func(ctx Context) {
+		ctx.Text("v3")
+	}))
+	require.Equal(t, http.StatusNotFound, a.TestRequest("GET", "/test", nil).Code)
+	require.NoError(t, a.RegisterRoute("/test"))
+	require.Equal(t, "v3", a.TestRequest("GET", "/test", nil).Body.String())
+}
+
+func TestContextForward(t *testing.T) {
+	a := Basic()
+	a.HandleFunc("/target", func(ctx Context) {
+		args := Bind[struct {
+			Hello string `json:"hello"`
+		}](ctx)
+		ctx.Text(ctx.Req().URL.Path + " " + ctx.Req().Header.Get("X-Test") + " " + args.Hello)
+	})
+	a.HandleFunc("/source", func(ctx Context) {
+		_ = Bind[map[string]any](ctx)
+		require.Error(t, ctx.Forward("/none"))
+		require.NoError(t, ctx.Forward("/target"))
+		ctx.Text("IGNORED")
+	})
+
+	rw, req := httptest.NewRecorder(), httptest.NewRequest("POST", "/source", strings.NewReader(`{"hello":"world"}`))
+	req.Header.Set("Content-Type", ContentTypeApplicationJSON)
+	req.Header.Set("X-Test", "test")
+	a.ServeHTTP(rw, req)
+	require.Equal(t, "/target test world", rw.Body.String())
+
+	ctx, _ := NewTestContext[Context](BasicContext, "GET", "/", nil)
+	require.Error(t, ctx.Forward("/target"))
+}
@@ -0,0 +1,155 @@
+package summer
+
+import (
+	"context"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"golang.org/x/net/netutil"
+	"net"
+	"net/http"
+	"os/signal"
+	"sync/atomic"
+	"time"
+)
+
+// Run startup all components, serve http on addr, and shutdown everything after ctx is done or a shutdown signal received
+//
+// Shutdown signals are set by [WithShutdownSignals], none by default
+// Before shutting down the server, readiness check fails for drain period set by [WithDrainPeriod]
+func (a *app[T]) Run(ctx context.Context, addr string) (err error) {
+	return a.runServer(ctx, a.newServer(addr), nil)
+}
+
+// newServer create the [http.Server] serving [App] on addr
+func (a *app[T]) newServer(addr string) *http.Server {
+	var h http.Handler = a
+	if a.opts.h2c || a.opts.grpcHandler != nil {
+		h = h2c.NewHandler(a, &http2.Server{IdleTimeout: a.opts.serverTimeouts.IdleTimeout})
+	}
+	return &http.Server{
+		Addr:              addr,
+		Handler:           h,
+		ReadTimeout:       a.opts.serverTimeouts.ReadTimeout,
+		ReadHeaderTimeout: a.opts.serverTimeouts.ReadHeaderTimeout,
+		WriteTimeout:      a.opts.serverTimeouts.WriteTimeout,
+		IdleTimeout:       a.opts.serverTimeouts.IdleTimeout,
+		BaseContext:       a.opts.baseContext,
+		ConnState: func(conn net.Conn, state http.ConnState) {
+			switch state {
+			case http.StateNew:
+				a.mOpenConnections.Inc()
+			case http.StateHijacked, http.StateClosed:
+				a.mOpenConnections.Dec()
+			}
+		},
+	}
+}
+
+// runServer startup all components, serve s, and shutdown everything after ctx is done
+//
+// ready is called if not nil, after components started and listener created
+func (a *app[T]) runServer(ctx context.Context, s *http.Server, ready func()) (err error) {
+	// shutdown signals, registered before anything started, so no signal falls back to default handling
+	if len(a.opts.shutdownSignals) > 0 {
+		var stop context.CancelFunc
+		ctx, stop = signal.NotifyContext(ctx, a.opts.shutdownSignals...)
+		defer stop()
+	}
+
+	defer atomic.StoreInt32(&a.draining, 0)
+
+	if s.TLSConfig == nil {
+		if s.TLSConfig, err = a.newTLSConfig(); err != nil {
+			return
+		}
+	}
+
+	if err = a.Startup(ctx); err != nil {
+		return
+	}
+	defer func() {
+		sctx, cancel := context.WithTimeout(context.Background(), a.opts.shutdownTimeout)
+		defer cancel()
+		if err1 := a.Shutdown(sctx); err1 != nil && err == nil {
+			err = err1
+		}
+	}()
+
+	if a.opts.startupLog {
+		a.opts.logger.Info(
+			"starting server",
+			"addr", s.Addr,
+			"tls", s.TLSConfig != nil,
+			"debug_addr", a.opts.debugAddr,
+			"concurrency", a.opts.concurrency,
+			"readiness_cascade", a.opts.readinessCascade,
+			"readiness_path", a.opts.readinessPath,
+			"liveness_path", a.opts.livenessPath,
+			"metrics_path", a.opts.metricsPath,
+			"routes", len(a.Patterns()),
+			"checks", len(a.CheckNames()),
+		)
+	}
+
+	var l net.Listener
+	if l, err = net.Listen("tcp", s.Addr); err != nil {
+		return
+	}
+	if a.opts.maxConnections > 0 {
+		l = netutil.LimitListener(l, a.opts.maxConnections)
+	}
+
+	chErr := make(chan error, 1)
+	go func() {
+		if s.TLSConfig != nil {
+			chErr <- s.ServeTLS(l, "", "")
+		} else {
+			chErr <- s.Serve(l)
+		}
+	}()
+
+	if ready != nil {
+		ready()
+	}
+
+	// idle connections cleanup
+	if a.opts.cleanIdleInterval > 0 {
+		done := make(chan struct{})
+		defer close(done)
+		go cleanIdleConnections(s, a.opts.cleanIdleInterval, done)
+	}
+
+	select {
+	case err = <-chErr:
+		return
+	case <-ctx.Done():
+	}
+
+	// drain, readiness check fails while in-flight requests finishing
+	atomic.StoreInt32(&a.draining, 1)
+	if a.opts.drainPeriod > 0 {
+		a.opts.logger.Info("draining server", "drain_period", a.opts.drainPeriod.String())
+		time.Sleep(a.opts.drainPeriod)
+	}
+
+	sctx, cancel := context.WithTimeout(context.Background(), a.opts.shutdownTimeout)
+	defer cancel()
+
+	err = s.Shutdown(sctx)
+	return
+}
+
+// cleanIdleConnections close idle connections of s on every interval by toggling keep-alives, until done is closed
+func cleanIdleConnections(s *http.Server, interval time.Duration, done chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			s.SetKeepAlivesEnabled(false)
+			s.SetKeepAlivesEnabled(true)
+		}
+	}
+}
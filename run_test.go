@@ -0,0 +1,120 @@
+package summer
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/require"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+)
+
+func freeAddr(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	return l.Addr().String()
+}
+
+func TestAppRun(t *testing.T) {
+	var started, stopped bool
+
+	logs := &bytes.Buffer{}
+
+	type contextKey struct{}
+
+	a := Basic(
+		WithMaxConnections(4),
+		WithLogger(slog.New(slog.NewTextHandler(logs, nil))),
+		WithBaseContextValues(map[any]any{contextKey{}: "OK"}),
+		WithCleanIdleConnections(time.Millisecond*10),
+	)
+	a.Component("test-1").
+		Startup(func(ctx context.Context) (err error) {
+			started = true
+			return
+		}).
+		Shutdown(func(ctx context.Context) (err error) {
+			stopped = true
+			return
+		})
+	a.HandleFunc("/test", func(ctx Context) {
+		ctx.Text(ctx.Value(contextKey{}).(string))
+	})
+
+	addr := freeAddr(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	chErr := make(chan error, 1)
+	go func() {
+		chErr <- a.Run(ctx, addr)
+	}()
+
+	require.Eventually(t, func() bool {
+		res, err := http.Get("http://" + addr + "/test")
+		if err != nil {
+			return false
+		}
+		defer res.Body.Close()
+		buf, _ := io.ReadAll(res.Body)
+		return string(buf) == "OK"
+	}, time.Second*5, time.Millisecond*10)
+
+	require.True(t, started)
+	require.Contains(t, logs.String(), "msg=\"starting server\" addr="+addr)
+	require.Contains(t, logs.String(), "routes=1 checks=1")
+
+	cancel()
+	require.NoError(t, <-chErr)
+	require.True(t, stopped)
+}
+
+func TestAppRunGracefulShutdown(t *testing.T) {
+	a := Basic(
+		WithStartupLog(false),
+		WithShutdownSignals(os.Interrupt),
+		WithDrainPeriod(time.Millisecond*300),
+	)
+	a.HandleFunc("/test", func(ctx Context) {
+		ctx.Text("OK")
+	})
+
+	addr := freeAddr(t)
+
+	chErr := make(chan error, 1)
+	go func() {
+		chErr <- a.Run(context.Background(), addr)
+	}()
+
+	get := func(path string) int {
+		res, err := http.Get("http://" + addr + path)
+		if err != nil {
+			return 0
+		}
+		defer res.Body.Close()
+		return res.StatusCode
+	}
+
+	require.Eventually(t, func() bool {
+		return get(DefaultReadinessPath) == http.StatusOK
+	}, time.Second*5, time.Millisecond*10)
+
+	p, err := os.FindProcess(os.Getpid())
+	require.NoError(t, err)
+	require.NoError(t, p.Signal(os.Interrupt))
+
+	require.Eventually(t, func() bool {
+		return get(DefaultReadinessPath) == http.StatusServiceUnavailable
+	}, time.Second, time.Millisecond*10)
+	require.Equal(t, http.StatusOK, get("/test"))
+
+	require.NoError(t, <-chErr)
+	require.Equal(t, 0, get("/test"))
+
+	// ready again after run returned
+	require.Equal(t, http.StatusOK, a.TestRequest("GET", DefaultReadinessPath, nil).Code)
+}
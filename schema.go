@@ -0,0 +1,105 @@
+package summer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// SchemaValidationError error of request body failing JSON Schema set by [WithJSONSchema], results in 422
+type SchemaValidationError struct {
+	// Violations violations in format of "<instance location>: <message>"
+	Violations []string
+}
+
+func (e *SchemaValidationError) Error() string {
+	return "json schema validation failed: " + strings.Join(e.Violations, "; ")
+}
+
+func (e *SchemaValidationError) StatusCode() int {
+	return http.StatusUnprocessableEntity
+}
+
+func (e *SchemaValidationError) ExtractExtras(m map[string]any) {
+	m["violations"] = e.Violations
+}
+
+var (
+	_ withStatusCode = &SchemaValidationError{}
+	_ withExtract    = &SchemaValidationError{}
+)
+
+// compileJSONSchema compile a JSON Schema document
+func compileJSONSchema(schema []byte) (*jsonschema.Schema, error) {
+	c := jsonschema.NewCompiler()
+	if err := c.AddResource("schema.json", bytes.NewReader(schema)); err != nil {
+		return nil, err
+	}
+	return c.Compile("schema.json")
+}
+
+// collectViolations collect leaf errors of a [jsonschema.ValidationError]
+func collectViolations(ve *jsonschema.ValidationError, out []string) []string {
+	if len(ve.Causes) == 0 {
+		loc := ve.InstanceLocation
+		if loc == "" {
+			loc = "/"
+		}
+		return append(out, loc+": "+ve.Message)
+	}
+	for _, cause := range ve.Causes {
+		out = append(out, collectViolations(cause, nil)...)
+	}
+	return out
+}
+
+// validateJSONSchema validate JSON body buf against schema, empty body is validated as null
+//
+// Returns 400 for invalid JSON, or [SchemaValidationError] for violations
+func validateJSONSchema(schema *jsonschema.Schema, buf []byte) (err error) {
+	var v any
+	if len(buf) > 0 {
+		dec := json.NewDecoder(bytes.NewReader(buf))
+		dec.UseNumber()
+		if err = dec.Decode(&v); err != nil {
+			return NewHaltError(err, HaltWithBadRequest())
+		}
+	}
+
+	if err = schema.Validate(v); err != nil {
+		var ve *jsonschema.ValidationError
+		if errors.As(err, &ve) {
+			return &SchemaValidationError{Violations: collectViolations(ve, nil)}
+		}
+		return NewHaltError(err)
+	}
+	return
+}
+
+// validateRequestSchema wrap fn, validating request body against schema before fn, see [WithJSONSchema]
+//
+// Body other than "application/json" is rejected with 415
+func validateRequestSchema[T Context](fn HandlerFunc[T], schema *jsonschema.Schema) HandlerFunc[T] {
+	return func(ctx T) {
+		buf, err := ctx.RawBody()
+		if err != nil {
+			if errors.Is(err, ErrBodyTooLarge) {
+				panic(err)
+			}
+			Halt(err, HaltWithBadRequest())
+		}
+		if len(buf) > 0 {
+			if contentType, _, _ := mime.ParseMediaType(ctx.Req().Header.Get("Content-Type")); !strings.EqualFold(contentType, ContentTypeApplicationJSON) {
+				HaltString("unsupported request body type, expecting "+ContentTypeApplicationJSON, HaltWithStatusCode(http.StatusUnsupportedMediaType))
+			}
+		}
+		if err = validateJSONSchema(schema, buf); err != nil {
+			panic(err)
+		}
+		fn(ctx)
+	}
+}
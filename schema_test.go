@@ -0,0 +1,122 @@
+package summer
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAppJSONSchema(t *testing.T) {
+	require.Panics(t, func() {
+		WithJSONSchema([]byte(`{"type":`))
+	})
+
+	a := Basic()
+	a.HandleFunc("/test", func(ctx Context) {
+		args := Bind[struct {
+			Name string `json:"name"`
+		}](ctx)
+		ctx.Text(args.Name)
+	}, WithJSONSchema([]byte(`{
+		"type": "object",
+		"properties": {
+			"name": {"type": "string", "minLength": 1},
+			"age": {"type": "integer"}
+		},
+		"required": ["name"]
+	}`)))
+
+	post := func(body string) *httptest.ResponseRecorder {
+		rw, req := httptest.NewRecorder(), httptest.NewRequest("POST", "/test", strings.NewReader(body))
+		req.Header.Set("Content-Type", ContentTypeApplicationJSONUTF8)
+		a.ServeHTTP(rw, req)
+		return rw
+	}
+
+	// non-JSON body is rejected
+	for _, contentType := range []string{ContentTypeFormURLEncoded, ContentTypeTextPlain, ""} {
+		rw, req := httptest.NewRecorder(), httptest.NewRequest("POST", "/test", strings.NewReader(`name=`))
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		a.ServeHTTP(rw, req)
+		require.Equal(t, http.StatusUnsupportedMediaType, rw.Code, contentType)
+	}
+
+	// missing body is validated as null
+	res := a.TestRequest("POST", "/test", nil)
+	require.Equal(t, http.StatusUnprocessableEntity, res.Code)
+
+	rw := post(`{"name":"alice","age":18}`)
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Equal(t, "alice", rw.Body.String())
+
+	rw = post(`{"name":`)
+	require.Equal(t, http.StatusBadRequest, rw.Code)
+
+	rw = post(`{"age":1.5}`)
+	require.Equal(t, http.StatusUnprocessableEntity, rw.Code)
+	var body struct {
+		Violations []string `json:"violations"`
+	}
+	require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &body))
+	require.Len(t, body.Violations, 2)
+}
+
+func TestAppJSONSchemaAfterMiddlewares(t *testing.T) {
+	a := Basic()
+	a.Use(func(ctx Context, next func()) {
+		if ctx.Req().Header.Get("Authorization") == "" {
+			HaltString("unauthorized", HaltWithStatusCode(http.StatusUnauthorized))
+		}
+		next()
+	})
+	a.HandleFunc("/test", func(ctx Context) {
+		var args map[string]any
+		ctx.Bind(&args)
+		ctx.Text("OK")
+	}, WithJSONSchema([]byte(`{"type": "object", "required": ["name"]}`)))
+
+	post := func(authorization string) *httptest.ResponseRecorder {
+		rw, req := httptest.NewRecorder(), httptest.NewRequest("POST", "/test", strings.NewReader(`{}`))
+		req.Header.Set("Content-Type", ContentTypeApplicationJSON)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		a.ServeHTTP(rw, req)
+		return rw
+	}
+
+	// rejected by middleware first
+	require.Equal(t, http.StatusUnauthorized, post("").Code)
+
+	rw := post("Bearer x")
+	require.Equal(t, http.StatusUnprocessableEntity, rw.Code)
+	require.Contains(t, rw.Body.String(), `"violations":["/: missing properties: 'name'"]`)
+}
+
+func TestAppJSONSchemaWithoutBind(t *testing.T) {
+	a := Basic()
+	a.HandleFunc("/test", func(ctx Context) {
+		buf, err := ctx.BodyBytes(0)
+		require.NoError(t, err)
+		ctx.Text(string(buf))
+	}, WithJSONSchema([]byte(`{"type": "object", "required": ["name"]}`)))
+
+	post := func(body string) *httptest.ResponseRecorder {
+		rw, req := httptest.NewRecorder(), httptest.NewRequest("POST", "/test", strings.NewReader(body))
+		req.Header.Set("Content-Type", ContentTypeApplicationJSON)
+		a.ServeHTTP(rw, req)
+		return rw
+	}
+
+	require.Equal(t, http.StatusUnprocessableEntity, post(`{}`).Code)
+
+	// body is still readable by handler
+	rw := post(`{"name":"alice"}`)
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Equal(t, `{"name":"alice"}`, rw.Body.String())
+}
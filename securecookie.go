@@ -0,0 +1,98 @@
+package summer
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"time"
+)
+
+// ErrSecureCookieInvalid value of cookie is malformed, tampered or expired
+var ErrSecureCookieInvalid = errors.New("summer: invalid secure cookie")
+
+// SecureCookie a codec signing cookie values with HMAC-SHA256, and optionally encrypting them with AES-GCM
+//
+// Name of cookie is authenticated as well, a value can not be moved to another cookie
+type SecureCookie struct {
+	hashKey []byte
+	aead    cipher.AEAD
+	now     func() time.Time
+}
+
+// NewSecureCookie create a [SecureCookie], hashKey must be at least 32 bytes, blockKey is either empty or an AES key of 16, 24 or 32 bytes
+//
+// Invalid keys cause a panic
+func NewSecureCookie(hashKey, blockKey []byte) *SecureCookie {
+	if len(hashKey) < 32 {
+		panic("summer: hash key of secure cookie must be at least 32 bytes")
+	}
+	s := &SecureCookie{hashKey: hashKey, now: time.Now}
+	if len(blockKey) > 0 {
+		block, err := aes.NewCipher(blockKey)
+		if err != nil {
+			panic("summer: invalid block key of secure cookie: " + err.Error())
+		}
+		if s.aead, err = cipher.NewGCM(block); err != nil {
+			panic("summer: invalid block key of secure cookie: " + err.Error())
+		}
+	}
+	return s
+}
+
+func (s *SecureCookie) mac(name string, data []byte) []byte {
+	h := hmac.New(sha256.New, s.hashKey)
+	h.Write([]byte(name))
+	h.Write([]byte{0})
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// Encode encode value of cookie name with current timestamp, as an URL safe string
+func (s *SecureCookie) Encode(name string, value []byte) (string, error) {
+	data := binary.BigEndian.AppendUint64(nil, uint64(s.now().Unix()))
+	data = append(data, value...)
+	if s.aead != nil {
+		nonce := make([]byte, s.aead.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return "", err
+		}
+		data = s.aead.Seal(nonce, nonce, data, []byte(name))
+	}
+	return base64.RawURLEncoding.EncodeToString(append(data, s.mac(name, data)...)), nil
+}
+
+// Decode decode value of cookie name encoded by [SecureCookie.Encode], values older than maxAge are rejected if maxAge > 0
+//
+// [ErrSecureCookieInvalid] is returned if value is malformed, tampered or expired
+func (s *SecureCookie) Decode(name string, value string, maxAge time.Duration) ([]byte, error) {
+	buf, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(buf) < sha256.Size {
+		return nil, ErrSecureCookieInvalid
+	}
+	data, sum := buf[:len(buf)-sha256.Size], buf[len(buf)-sha256.Size:]
+	if !hmac.Equal(sum, s.mac(name, data)) {
+		return nil, ErrSecureCookieInvalid
+	}
+	if s.aead != nil {
+		if len(data) < s.aead.NonceSize() {
+			return nil, ErrSecureCookieInvalid
+		}
+		nonce := data[:s.aead.NonceSize()]
+		if data, err = s.aead.Open(nil, nonce, data[len(nonce):], []byte(name)); err != nil {
+			return nil, ErrSecureCookieInvalid
+		}
+	}
+	if len(data) < 8 {
+		return nil, ErrSecureCookieInvalid
+	}
+	ts := time.Unix(int64(binary.BigEndian.Uint64(data)), 0)
+	if maxAge > 0 && s.now().Sub(ts) > maxAge {
+		return nil, ErrSecureCookieInvalid
+	}
+	return data[8:], nil
+}
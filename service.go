@@ -0,0 +1,213 @@
+package summer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// ErrServiceNotProvided error of resolving a service type not provided, see [Resolve]
+var ErrServiceNotProvided = errors.New("service not provided")
+
+// ErrServiceShutdown error of resolving a service after [Registry.Shutdown], until next [Registry.Startup]
+var ErrServiceShutdown = errors.New("service shutdown")
+
+// Startable a service started by [Registry.Startup], see [Provide]
+type Startable interface {
+	Start(ctx context.Context) error
+}
+
+// Closable a service closed by [Registry.Shutdown], like *sql.DB, see [Provide]
+type Closable interface {
+	Close() error
+}
+
+// service a shared value of [Registry], constructed at most once
+type service struct {
+	name string
+	fn   func(ctx context.Context) (any, error)
+
+	mu     sync.Mutex
+	built  bool
+	closed bool
+	value  any
+}
+
+// get returns value of service, constructed on first call, a failed construction is retried by next call
+func (s *service) get(ctx context.Context) (any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil, fmt.Errorf("%w: %s", ErrServiceShutdown, s.name)
+	}
+	if s.built {
+		return s.value, nil
+	}
+	value, err := s.fn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("service %s: %w", s.name, err)
+	}
+	s.value, s.built = value, true
+	return value, nil
+}
+
+// startup construct service if not yet, and start it if [Startable]
+func (s *service) startup(ctx context.Context) error {
+	s.mu.Lock()
+	s.closed = false
+	s.mu.Unlock()
+
+	value, err := s.get(ctx)
+	if err != nil {
+		return err
+	}
+	if v, ok := value.(Startable); ok {
+		if err = v.Start(ctx); err != nil {
+			return fmt.Errorf("service %s: %w", s.name, err)
+		}
+	}
+	return nil
+}
+
+// shutdown close service if constructed and [Closable], get fails with [ErrServiceShutdown] until next startup
+func (s *service) shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	if !s.built {
+		return nil
+	}
+	value := s.value
+	s.value, s.built = nil, false
+	if v, ok := value.(Closable); ok {
+		if err := v.Close(); err != nil {
+			return fmt.Errorf("service %s: %w", s.name, err)
+		}
+	}
+	return nil
+}
+
+// services services of a [Registry], by type
+type services struct {
+	mu    sync.RWMutex
+	items map[reflect.Type]*service
+}
+
+func (ss *services) lookup(t reflect.Type) *service {
+	ss.mu.RLock()
+	defer ss.mu.RUnlock()
+	return ss.items[t]
+}
+
+// provide register a service of type t to registry, panics if already provided
+func (a *registry) provide(t reflect.Type, fn func(ctx context.Context) (any, error)) {
+	s := &service{name: t.String(), fn: fn}
+
+	a.services.mu.Lock()
+	if _, ok := a.services.items[t]; ok {
+		a.services.mu.Unlock()
+		panic("summer: duplicated service with type: " + s.name)
+	}
+	if a.services.items == nil {
+		a.services.items = map[reflect.Type]*service{}
+	}
+	a.services.items[t] = s
+	a.services.mu.Unlock()
+
+	a.hook(&registration{name: "service " + s.name, startup: s.startup, shutdown: s.shutdown})
+}
+
+// serviceRegistry a [Registry] holding services, implemented by registries and apps of this package
+type serviceRegistry interface {
+	serviceRegistry() *registry
+}
+
+func (a *registry) serviceRegistry() *registry {
+	return a
+}
+
+func (a *app[T]) serviceRegistry() *registry {
+	return a.registry
+}
+
+// servicesOf returns the underlying registry of r holding services, nil if r is not created by this package
+func servicesOf(r Registry) *registry {
+	if sr, ok := r.(serviceRegistry); ok {
+		return sr.serviceRegistry()
+	}
+	return nil
+}
+
+// Provide register a service of type S to r, usually an [App], constructed by fn on first [Resolve], or on [Registry.Startup]
+//
+// Services are started in registration order along with components, started by Start(ctx) if [Startable],
+// and closed in reverse order on [Registry.Shutdown] if [Closable]. fn may resolve services provided earlier.
+// Providing a type twice, or to a [Registry] not created by [NewRegistry] or [New], causes a panic
+//
+// example:
+//
+//	summer.Provide(a, func(ctx context.Context) (*sql.DB, error) {
+//		return sql.Open("mysql", dsn)
+//	})
+//
+//	a.HandleFunc("/users", func(c summer.Context) {
+//		db := summer.MustResolve[*sql.DB](summer.RegistryOf(c))
+//		// ...
+//	})
+func Provide[S any](r Registry, fn func(ctx context.Context) (S, error)) {
+	reg := servicesOf(r)
+	if reg == nil {
+		panic(fmt.Sprintf("summer: registry %T does not support services", r))
+	}
+	reg.provide(reflect.TypeFor[S](), func(ctx context.Context) (any, error) {
+		return fn(ctx)
+	})
+}
+
+// Supply register an already constructed service of type S to r, like [Provide]
+func Supply[S any](r Registry, v S) {
+	Provide(r, func(ctx context.Context) (S, error) {
+		return v, nil
+	})
+}
+
+// Resolve returns service of type S provided to r, constructed if not yet, [ErrServiceNotProvided] if not provided,
+// [ErrServiceShutdown] if r is shutdown
+func Resolve[S any](r Registry) (o S, err error) {
+	t := reflect.TypeFor[S]()
+	var s *service
+	if reg := servicesOf(r); reg != nil {
+		s = reg.services.lookup(t)
+	}
+	if s == nil {
+		err = fmt.Errorf("%w: %s", ErrServiceNotProvided, t.String())
+		return
+	}
+	var v any
+	if v, err = s.get(context.Background()); err != nil {
+		return
+	}
+	o, _ = v.(S)
+	return
+}
+
+// MustResolve like [Resolve], but panics on error
+func MustResolve[S any](r Registry) S {
+	o, err := Resolve[S](r)
+	if err != nil {
+		panic(err)
+	}
+	return o
+}
+
+type registryContextKey struct{}
+
+// RegistryOf returns [Registry] of the [App] serving the request of ctx, nil if not found
+func RegistryOf(ctx context.Context) Registry {
+	r, _ := ctx.Value(registryContextKey{}).(Registry)
+	return r
+}
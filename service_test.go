@@ -0,0 +1,155 @@
+package summer
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+)
+
+type testServiceDB struct {
+	name    string
+	started bool
+	closed  bool
+	events  *[]string
+}
+
+func (d *testServiceDB) Start(ctx context.Context) error {
+	d.started = true
+	*d.events = append(*d.events, "start "+d.name)
+	return nil
+}
+
+func (d *testServiceDB) Close() error {
+	d.closed = true
+	*d.events = append(*d.events, "close "+d.name)
+	return nil
+}
+
+type testServiceRepo struct {
+	db *testServiceDB
+}
+
+type testServiceGreeter interface {
+	Greet() string
+}
+
+type testServiceGreeterImpl struct{}
+
+func (testServiceGreeterImpl) Greet() string {
+	return "hello"
+}
+
+func TestProvideResolve(t *testing.T) {
+	var events []string
+
+	a := Basic()
+	Provide(a, func(ctx context.Context) (*testServiceDB, error) {
+		events = append(events, "build db")
+		return &testServiceDB{name: "db", events: &events}, nil
+	})
+	Provide(a, func(ctx context.Context) (*testServiceRepo, error) {
+		db, err := Resolve[*testServiceDB](a)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, "build repo")
+		return &testServiceRepo{db: db}, nil
+	})
+	Supply[testServiceGreeter](a, testServiceGreeterImpl{})
+	a.OnStop(func(ctx context.Context) error {
+		events = append(events, "stop hook")
+		return nil
+	})
+
+	require.PanicsWithValue(t, "summer: duplicated service with type: *summer.testServiceDB", func() {
+		Supply(a, &testServiceDB{})
+	})
+
+	_, err := Resolve[*testServiceRepo](NewRegistry())
+	require.ErrorIs(t, err, ErrServiceNotProvided)
+	require.EqualError(t, err, "service not provided: *summer.testServiceRepo")
+	require.NotContains(t, a.CheckNames(), "service *summer.testServiceDB")
+
+	// constructed lazily, only once
+	repo := MustResolve[*testServiceRepo](a)
+	require.Same(t, repo, MustResolve[*testServiceRepo](a))
+	require.Equal(t, []string{"build db", "build repo"}, events)
+
+	require.NoError(t, a.Startup(context.Background()))
+	require.True(t, repo.db.started)
+	require.Equal(t, []string{"build db", "build repo", "start db"}, events)
+
+	a.GET("/greet", func(c Context) {
+		r := RegistryOf(c)
+		require.Same(t, repo, MustResolve[*testServiceRepo](r))
+		c.Text(MustResolve[testServiceGreeter](r).Greet())
+	})
+	res := a.TestRequest("GET", "/greet", nil)
+	require.Equal(t, http.StatusOK, res.Code)
+	require.Equal(t, "hello", res.Body.String())
+
+	require.NoError(t, a.Shutdown(context.Background()))
+	require.True(t, repo.db.closed)
+	require.Equal(t, []string{"build db", "build repo", "start db", "stop hook", "close db"}, events)
+
+	require.Nil(t, RegistryOf(context.Background()))
+
+	// not rebuilt after shutdown
+	_, err = Resolve[*testServiceRepo](a)
+	require.ErrorIs(t, err, ErrServiceShutdown)
+	require.EqualError(t, err, "service shutdown: *summer.testServiceRepo")
+	require.Equal(t, []string{"build db", "build repo", "start db", "stop hook", "close db"}, events)
+
+	// built again by next startup
+	require.NoError(t, a.Startup(context.Background()))
+	require.NotSame(t, repo, MustResolve[*testServiceRepo](a))
+	require.NoError(t, a.Shutdown(context.Background()))
+}
+
+// testServiceRegistry a [Registry] implemented outside of this package
+type testServiceRegistry struct {
+	Registry
+}
+
+func TestProvideUnsupportedRegistry(t *testing.T) {
+	r := testServiceRegistry{Registry: NewRegistry()}
+	require.PanicsWithValue(t, "summer: registry summer.testServiceRegistry does not support services", func() {
+		Supply(r, &testServiceDB{})
+	})
+	_, err := Resolve[*testServiceDB](r)
+	require.ErrorIs(t, err, ErrServiceNotProvided)
+}
+
+func TestProvideFailure(t *testing.T) {
+	r := NewRegistry()
+
+	var calls int
+	Provide(r, func(ctx context.Context) (*testServiceRepo, error) {
+		calls++
+		if calls == 1 {
+			return nil, errors.New("failed")
+		}
+		return &testServiceRepo{}, nil
+	})
+
+	_, err := Resolve[*testServiceRepo](r)
+	require.EqualError(t, err, "service *summer.testServiceRepo: failed")
+	require.Equal(t, 1, calls)
+	// retried
+	require.NotNil(t, MustResolve[*testServiceRepo](r))
+	require.NotNil(t, MustResolve[*testServiceRepo](r))
+	require.Equal(t, 2, calls)
+
+	require.Panics(t, func() {
+		MustResolve[*testServiceDB](r)
+	})
+
+	// failed startup, not closed
+	Provide(r, func(ctx context.Context) (*testServiceDB, error) {
+		return nil, errors.New("failed")
+	})
+	require.EqualError(t, r.Startup(context.Background()), "service *summer.testServiceDB: failed")
+	require.NoError(t, r.Shutdown(context.Background()))
+}
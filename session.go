@@ -0,0 +1,347 @@
+package summer
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"github.com/redis/go-redis/v9"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultSessionCookieName default name of session cookie, see [SessionConfig]
+const DefaultSessionCookieName = "summer_session"
+
+// SessionStore storage of session data keyed by session id, see [NewMemorySessionStore] and [NewRedisSessionStore]
+type SessionStore interface {
+	// Load returns data of session id, nil data without error if not found or expired
+	Load(ctx context.Context, id string) (data []byte, err error)
+
+	// Save save data of session id, expiring after ttl
+	Save(ctx context.Context, id string, data []byte, ttl time.Duration) error
+
+	// Delete delete session id, missing session is not an error
+	Delete(ctx context.Context, id string) error
+}
+
+// SessionConfig configuration of sessions, see [WithSessions]
+type SessionConfig struct {
+	// HashKey key signing session cookie with HMAC-SHA256, at least 32 bytes, required
+	HashKey []byte
+	// BlockKey optional AES key of 16, 24 or 32 bytes, encrypting session cookie with AES-GCM
+	BlockKey []byte
+	// Store storage of session data, data is stored in the session cookie itself if nil
+	Store SessionStore
+
+	// CookieName name of session cookie, defaults to [DefaultSessionCookieName]
+	CookieName string
+	// MaxAge lifetime of session, defaults to 24 hours
+	MaxAge time.Duration
+	// Path path of session cookie, defaults to "/"
+	Path string
+	// Domain domain of session cookie
+	Domain string
+	// Secure send session cookie over HTTPS only
+	Secure bool
+	// SameSite SameSite attribute of session cookie, defaults to [http.SameSiteLaxMode]
+	SameSite http.SameSite
+}
+
+// maxSessionCookieSize maximum size of session cookie value, browsers usually reject cookies larger than 4096 bytes
+const maxSessionCookieSize = 4000
+
+// cookieSessionData session data stored in the session cookie itself
+type cookieSessionData struct {
+	ID     string            `json:"id"`
+	Values map[string]string `json:"values"`
+}
+
+// sessionManager loads and saves [Session] with [SessionConfig]
+type sessionManager struct {
+	cfg   SessionConfig
+	codec *SecureCookie
+}
+
+// newSessionManager create a sessionManager with defaults filled, invalid keys cause a panic
+func newSessionManager(cfg SessionConfig) *sessionManager {
+	if cfg.CookieName == "" {
+		cfg.CookieName = DefaultSessionCookieName
+	}
+	if cfg.MaxAge <= 0 {
+		cfg.MaxAge = time.Hour * 24
+	}
+	if cfg.Path == "" {
+		cfg.Path = "/"
+	}
+	if cfg.SameSite == 0 {
+		cfg.SameSite = http.SameSiteLaxMode
+	}
+	return &sessionManager{cfg: cfg, codec: NewSecureCookie(cfg.HashKey, cfg.BlockKey)}
+}
+
+// newSessionID returns a random session id
+func newSessionID() string {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(buf)
+}
+
+// load load session of req, a new session is created if session cookie is missing, invalid or expired
+func (m *sessionManager) load(req *http.Request) (s *Session, err error) {
+	s = &Session{manager: m, values: map[string]string{}}
+
+	var buf []byte
+	if cookie, err1 := req.Cookie(m.cfg.CookieName); err1 == nil {
+		buf, _ = m.codec.Decode(m.cfg.CookieName, cookie.Value, m.cfg.MaxAge)
+	}
+
+	if m.cfg.Store == nil {
+		var data cookieSessionData
+		if buf != nil && json.Unmarshal(buf, &data) == nil && data.ID != "" {
+			s.id = data.ID
+			if data.Values != nil {
+				s.values = data.Values
+			}
+			return
+		}
+	} else if buf != nil {
+		var data []byte
+		if data, err = m.cfg.Store.Load(req.Context(), string(buf)); err != nil {
+			return
+		}
+		if data != nil && json.Unmarshal(data, &s.values) == nil {
+			s.id = string(buf)
+			return
+		}
+	}
+
+	s.id, s.isNew = newSessionID(), true
+	return
+}
+
+// save save session s to store, and set or expire the session cookie, only if s is modified
+func (m *sessionManager) save(ctx context.Context, rw http.ResponseWriter, s *Session) (err error) {
+	cookie := &http.Cookie{
+		Name:     m.cfg.CookieName,
+		Path:     m.cfg.Path,
+		Domain:   m.cfg.Domain,
+		Secure:   m.cfg.Secure,
+		HttpOnly: true,
+		SameSite: m.cfg.SameSite,
+	}
+
+	if s.destroyed {
+		if s.isNew {
+			return
+		}
+		if m.cfg.Store != nil {
+			id := s.id
+			if s.renewedFrom != "" {
+				id = s.renewedFrom
+			}
+			if err = m.cfg.Store.Delete(ctx, id); err != nil {
+				return
+			}
+		}
+		cookie.MaxAge = -1
+		http.SetCookie(rw, cookie)
+		return
+	}
+
+	if !s.modified {
+		return
+	}
+
+	var value []byte
+	if m.cfg.Store == nil {
+		if value, err = json.Marshal(cookieSessionData{ID: s.id, Values: s.values}); err != nil {
+			return
+		}
+	} else {
+		if s.renewedFrom != "" {
+			if err = m.cfg.Store.Delete(ctx, s.renewedFrom); err != nil {
+				return
+			}
+		}
+		var data []byte
+		if data, err = json.Marshal(s.values); err != nil {
+			return
+		}
+		if err = m.cfg.Store.Save(ctx, s.id, data, m.cfg.MaxAge); err != nil {
+			return
+		}
+		value = []byte(s.id)
+	}
+
+	if cookie.Value, err = m.codec.Encode(m.cfg.CookieName, value); err != nil {
+		return
+	}
+	if len(cookie.Value) > maxSessionCookieSize {
+		return errors.New("summer: session cookie too large, use a SessionStore")
+	}
+	cookie.MaxAge = int(m.cfg.MaxAge / time.Second)
+	http.SetCookie(rw, cookie)
+	return
+}
+
+// Session a session of string values, see [Context.Session]
+//
+// Changes are saved before response sent, responses streamed directly, like [Context.SSE] and [Context.SendFile], must change session beforehand
+type Session struct {
+	manager *sessionManager
+
+	id     string
+	values map[string]string
+
+	isNew       bool
+	modified    bool
+	destroyed   bool
+	renewedFrom string
+}
+
+// ID returns id of session
+func (s *Session) ID() string {
+	return s.id
+}
+
+// IsNew returns true if session is created by current request
+func (s *Session) IsNew() bool {
+	return s.isNew
+}
+
+// Get returns value of key, empty if not set
+func (s *Session) Get(key string) string {
+	return s.values[key]
+}
+
+// Lookup returns value of key, and whether it is set
+func (s *Session) Lookup(key string) (value string, ok bool) {
+	value, ok = s.values[key]
+	return
+}
+
+// Set set value of key, a destroyed session is renewed
+func (s *Session) Set(key string, value string) {
+	if s.destroyed {
+		s.Renew()
+	}
+	s.values[key] = value
+	s.modified = true
+}
+
+// Delete delete key
+func (s *Session) Delete(key string) {
+	if _, ok := s.values[key]; ok {
+		delete(s.values, key)
+		s.modified = true
+	}
+}
+
+// Renew change id of session keeping values, usually after login to prevent session fixation
+func (s *Session) Renew() {
+	if !s.isNew && s.renewedFrom == "" {
+		s.renewedFrom = s.id
+	}
+	s.id = newSessionID()
+	s.modified, s.destroyed = true, false
+}
+
+// Destroy remove all values, delete session from store and expire the session cookie
+func (s *Session) Destroy() {
+	s.values = map[string]string{}
+	s.modified, s.destroyed = false, true
+}
+
+// memorySessionSweepInterval minimum interval between sweeping expired sessions of [NewMemorySessionStore]
+const memorySessionSweepInterval = time.Minute
+
+type memorySession struct {
+	data      []byte
+	expiresAt time.Time
+}
+
+type memorySessionStore struct {
+	mu       sync.Mutex
+	sessions map[string]memorySession
+	sweptAt  time.Time
+	now      func() time.Time
+}
+
+// NewMemorySessionStore create a [SessionStore] in memory, sessions are not shared between instances and lost on restart
+func NewMemorySessionStore() SessionStore {
+	return &memorySessionStore{sessions: map[string]memorySession{}, now: time.Now}
+}
+
+func (s *memorySessionStore) Load(ctx context.Context, id string) (data []byte, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if ms, ok := s.sessions[id]; ok && s.now().Before(ms.expiresAt) {
+		data = ms.data
+	}
+	return
+}
+
+func (s *memorySessionStore) Save(ctx context.Context, id string, data []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+	s.sessions[id] = memorySession{data: data, expiresAt: now.Add(ttl)}
+	return nil
+}
+
+func (s *memorySessionStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.sessions, id)
+	return nil
+}
+
+// sweep remove expired sessions, at most once per memorySessionSweepInterval
+func (s *memorySessionStore) sweep(now time.Time) {
+	if now.Sub(s.sweptAt) < memorySessionSweepInterval {
+		return
+	}
+	s.sweptAt = now
+	for id, ms := range s.sessions {
+		if !now.Before(ms.expiresAt) {
+			delete(s.sessions, id)
+		}
+	}
+}
+
+type redisSessionStore struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// NewRedisSessionStore create a [SessionStore] backed by Redis, keys are prefixed with prefix, defaults to "summer:session:"
+func NewRedisSessionStore(client redis.UniversalClient, prefix string) SessionStore {
+	if prefix == "" {
+		prefix = "summer:session:"
+	}
+	return &redisSessionStore{client: client, prefix: prefix}
+}
+
+func (s *redisSessionStore) Load(ctx context.Context, id string) (data []byte, err error) {
+	if data, err = s.client.Get(ctx, s.prefix+id).Bytes(); errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	return
+}
+
+func (s *redisSessionStore) Save(ctx context.Context, id string, data []byte, ttl time.Duration) error {
+	return s.client.Set(ctx, s.prefix+id, data, ttl).Err()
+}
+
+func (s *redisSessionStore) Delete(ctx context.Context, id string) error {
+	return s.client.Del(ctx, s.prefix+id).Err()
+}
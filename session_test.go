@@ -0,0 +1,228 @@
+package summer
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	testSessionHashKey  = []byte(strings.Repeat("h", 32))
+	testSessionBlockKey = []byte(strings.Repeat("b", 32))
+)
+
+func TestSecureCookie(t *testing.T) {
+	now := time.Now()
+	for _, blockKey := range [][]byte{nil, testSessionBlockKey} {
+		s := NewSecureCookie(testSessionHashKey, blockKey)
+		s.now = func() time.Time { return now }
+
+		v, err := s.Encode("a", []byte("hello"))
+		require.NoError(t, err)
+		raw, err := base64.RawURLEncoding.DecodeString(v)
+		require.NoError(t, err)
+		require.Equal(t, blockKey == nil, bytes.Contains(raw, []byte("hello")))
+
+		buf, err := s.Decode("a", v, time.Minute)
+		require.NoError(t, err)
+		require.Equal(t, "hello", string(buf))
+
+		// moved to another cookie
+		_, err = s.Decode("b", v, time.Minute)
+		require.ErrorIs(t, err, ErrSecureCookieInvalid)
+
+		// tampered
+		tampered := []byte(v)
+		tampered[len(tampered)/2] ^= 1
+		_, err = s.Decode("a", string(tampered), time.Minute)
+		require.ErrorIs(t, err, ErrSecureCookieInvalid)
+		_, err = s.Decode("a", "!", time.Minute)
+		require.ErrorIs(t, err, ErrSecureCookieInvalid)
+
+		// other keys
+		_, err = NewSecureCookie([]byte(strings.Repeat("x", 32)), blockKey).Decode("a", v, 0)
+		require.ErrorIs(t, err, ErrSecureCookieInvalid)
+
+		// expired
+		s.now = func() time.Time { return now.Add(time.Hour) }
+		_, err = s.Decode("a", v, time.Minute)
+		require.ErrorIs(t, err, ErrSecureCookieInvalid)
+		_, err = s.Decode("a", v, 0)
+		require.NoError(t, err)
+	}
+
+	require.Panics(t, func() {
+		NewSecureCookie([]byte("short"), nil)
+	})
+	require.Panics(t, func() {
+		NewSecureCookie(testSessionHashKey, []byte("invalid"))
+	})
+}
+
+func TestContextCookie(t *testing.T) {
+	a := Basic()
+	a.HandleFunc("/test-cookie", func(ctx Context) {
+		c, err := ctx.Cookie("name")
+		require.NoError(t, err)
+		_, err = ctx.Cookie("missing")
+		require.ErrorIs(t, err, http.ErrNoCookie)
+		ctx.SetCookie(&http.Cookie{Name: "greeting", Value: "hello-" + c.Value, HttpOnly: true})
+		ctx.Text("OK")
+	})
+
+	rw, req := httptest.NewRecorder(), httptest.NewRequest("GET", "https://example.com/test-cookie", nil)
+	req.AddCookie(&http.Cookie{Name: "name", Value: "summer"})
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Equal(t, "greeting=hello-summer; HttpOnly", rw.Header().Get("Set-Cookie"))
+}
+
+func testSessions(t *testing.T, store SessionStore) {
+	a := Basic(WithSessions(SessionConfig{
+		HashKey:  testSessionHashKey,
+		BlockKey: testSessionBlockKey,
+		Store:    store,
+		Secure:   true,
+	}))
+	a.HandleFunc("/login", func(ctx Context) {
+		s := ctx.Session()
+		s.Renew()
+		s.Set("user", ctx.Req().URL.Query().Get("user"))
+		ctx.Text(s.ID())
+	})
+	a.HandleFunc("/me", func(ctx Context) {
+		user, ok := ctx.Session().Lookup("user")
+		if !ok {
+			ctx.Code(http.StatusUnauthorized)
+		}
+		ctx.Text(user)
+	})
+	a.HandleFunc("/logout", func(ctx Context) {
+		ctx.Session().Destroy()
+		ctx.Text("OK")
+	})
+
+	request := func(path string, cookie *http.Cookie) (*httptest.ResponseRecorder, *http.Cookie) {
+		rw, req := httptest.NewRecorder(), httptest.NewRequest("GET", "https://example.com"+path, nil)
+		if cookie != nil {
+			req.AddCookie(cookie)
+		}
+		a.ServeHTTP(rw, req)
+		var set *http.Cookie
+		if cookies := rw.Result().Cookies(); len(cookies) > 0 {
+			set = cookies[0]
+		}
+		return rw, set
+	}
+
+	rw, cookie := request("/me", nil)
+	require.Equal(t, http.StatusUnauthorized, rw.Code)
+	require.Nil(t, cookie)
+
+	rw, cookie = request("/login?user=alice", nil)
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.NotNil(t, cookie)
+	require.Equal(t, DefaultSessionCookieName, cookie.Name)
+	require.True(t, cookie.HttpOnly)
+	require.True(t, cookie.Secure)
+	require.Equal(t, http.SameSiteLaxMode, cookie.SameSite)
+	require.Equal(t, 86400, cookie.MaxAge)
+	require.NotContains(t, cookie.Value, rw.Body.String())
+
+	rw, set := request("/me", cookie)
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Equal(t, "alice", rw.Body.String())
+	require.Nil(t, set)
+
+	// login again with a renewed session id
+	rw, renewed := request("/login?user=bob", cookie)
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.NotEqual(t, cookie.Value, renewed.Value)
+	rw, _ = request("/me", renewed)
+	require.Equal(t, "bob", rw.Body.String())
+
+	rw, _ = request("/me", &http.Cookie{Name: DefaultSessionCookieName, Value: "tampered"})
+	require.Equal(t, http.StatusUnauthorized, rw.Code)
+
+	rw, set = request("/logout", renewed)
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Equal(t, -1, set.MaxAge)
+
+	if store != nil {
+		// old and destroyed sessions are deleted from store
+		rw, _ = request("/me", cookie)
+		require.Equal(t, http.StatusUnauthorized, rw.Code)
+		rw, _ = request("/me", renewed)
+		require.Equal(t, http.StatusUnauthorized, rw.Code)
+	}
+}
+
+func TestWithSessions(t *testing.T) {
+	t.Run("cookie", func(t *testing.T) {
+		testSessions(t, nil)
+	})
+	t.Run("memory", func(t *testing.T) {
+		testSessions(t, NewMemorySessionStore())
+	})
+	t.Run("redis", func(t *testing.T) {
+		mr := miniredis.RunT(t)
+		client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+		defer client.Close()
+		store := NewRedisSessionStore(client, "")
+		testSessions(t, store)
+
+		ctx := context.Background()
+		require.NoError(t, store.Save(ctx, "a", []byte("1"), time.Minute))
+		require.True(t, mr.Exists("summer:session:a"))
+		require.Equal(t, time.Minute, mr.TTL("summer:session:a"))
+		data, err := store.Load(ctx, "a")
+		require.NoError(t, err)
+		require.Equal(t, "1", string(data))
+		require.NoError(t, store.Delete(ctx, "a"))
+		data, err = store.Load(ctx, "a")
+		require.NoError(t, err)
+		require.Nil(t, data)
+	})
+}
+
+func TestContextSessionNotEnabled(t *testing.T) {
+	a := Basic()
+	a.HandleFunc("/test-session", func(ctx Context) {
+		ctx.Session()
+	})
+	res := a.TestRequest("GET", "/test-session", nil)
+	require.Equal(t, http.StatusInternalServerError, res.Code)
+}
+
+func TestMemorySessionStore(t *testing.T) {
+	now := time.Now()
+	s := NewMemorySessionStore().(*memorySessionStore)
+	s.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	require.NoError(t, s.Save(ctx, "a", []byte("1"), time.Minute))
+	data, err := s.Load(ctx, "a")
+	require.NoError(t, err)
+	require.Equal(t, "1", string(data))
+
+	now = now.Add(time.Minute * 2)
+	data, err = s.Load(ctx, "a")
+	require.NoError(t, err)
+	require.Nil(t, data)
+
+	// swept on save
+	require.NoError(t, s.Save(ctx, "b", []byte("2"), time.Minute))
+	require.Len(t, s.sessions, 1)
+
+	require.NoError(t, s.Delete(ctx, "b"))
+	require.NoError(t, s.Delete(ctx, "missing"))
+	require.Empty(t, s.sessions)
+}
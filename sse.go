@@ -0,0 +1,143 @@
+package summer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrSSEClosed error writing to a closed [SSE]
+var ErrSSEClosed = errors.New("summer: event stream closed")
+
+// ErrSSEInvalidEvent error sending an event with name containing line breaks, which would inject fields into the stream
+var ErrSSEInvalidEvent = errors.New("summer: event name must not contain line breaks")
+
+// sseLineBreaks replace all line breaks recognized by clients with "\n"
+var sseLineBreaks = strings.NewReplacer("\r\n", "\n", "\r", "\n")
+
+// SSE a Server-Sent Events stream, created by [Context.SSE]
+//
+// Methods are safe for concurrent use, every write is flushed immediately
+type SSE struct {
+	mu  sync.Mutex
+	rw  http.ResponseWriter
+	rc  *http.ResponseController
+	ctx context.Context
+
+	stop      chan struct{}
+	closeOnce sync.Once
+}
+
+// newSSE send headers of event stream, and start heartbeat if interval > 0
+func newSSE(ctx context.Context, rw http.ResponseWriter, heartbeat time.Duration) (s *SSE, err error) {
+	s = &SSE{
+		rw:   rw,
+		rc:   http.NewResponseController(rw),
+		ctx:  ctx,
+		stop: make(chan struct{}),
+	}
+
+	h := rw.Header()
+	h.Set("Content-Type", ContentTypeTextEventStream)
+	h.Set("Cache-Control", "no-cache")
+	h.Set("X-Accel-Buffering", "no")
+	h.Del("Content-Length")
+	rw.WriteHeader(http.StatusOK)
+
+	if err = s.rc.Flush(); err != nil {
+		return
+	}
+
+	if heartbeat > 0 {
+		go s.heartbeat(heartbeat)
+	}
+	return
+}
+
+func (s *SSE) heartbeat(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			if s.Comment("heartbeat") != nil {
+				return
+			}
+		}
+	}
+}
+
+// write write and flush raw content, fails if client disconnected or stream closed
+func (s *SSE) write(content string) (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	select {
+	case <-s.stop:
+		return ErrSSEClosed
+	default:
+	}
+	if err = s.ctx.Err(); err != nil {
+		return
+	}
+	if _, err = s.rw.Write([]byte(content)); err != nil {
+		return
+	}
+	return s.rc.Flush()
+}
+
+// Send send an event, event name can be empty, multi-line data is split into multiple "data" fields
+//
+// Event name containing "\r" or "\n" is rejected with [ErrSSEInvalidEvent]
+func (s *SSE) Send(event string, data string) error {
+	if strings.ContainsAny(event, "\r\n") {
+		return ErrSSEInvalidEvent
+	}
+	sb := &strings.Builder{}
+	if event != "" {
+		sb.WriteString("event: ")
+		sb.WriteString(event)
+		sb.WriteString("\n")
+	}
+	for _, line := range strings.Split(sseLineBreaks.Replace(data), "\n") {
+		sb.WriteString("data: ")
+		sb.WriteString(line)
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n")
+	return s.write(sb.String())
+}
+
+// Comment send a comment, ignored by clients, usually for keeping connection alive, multi-line text is split into multiple comment lines
+func (s *SSE) Comment(text string) error {
+	sb := &strings.Builder{}
+	for _, line := range strings.Split(sseLineBreaks.Replace(text), "\n") {
+		sb.WriteString(": ")
+		sb.WriteString(line)
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n")
+	return s.write(sb.String())
+}
+
+// Done returns a channel closed when client disconnected
+func (s *SSE) Done() <-chan struct{} {
+	return s.ctx.Done()
+}
+
+// Close stop heartbeat, further writes fail with [ErrSSEClosed], called by [Context.Perform] when handler returns
+func (s *SSE) Close() {
+	s.closeOnce.Do(func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		close(s.stop)
+	})
+}
@@ -0,0 +1,100 @@
+package summer
+
+import (
+	"bufio"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestContextSSE(t *testing.T) {
+	done := make(chan error, 1)
+
+	a := Basic(WithSSEHeartbeat(time.Millisecond * 50))
+	a.HandleFunc("/events", func(ctx Context) {
+		s, err := ctx.SSE()
+		require.NoError(t, err)
+		defer s.Close()
+
+		require.NoError(t, s.Send("greeting", "hello\nworld"))
+		require.NoError(t, s.Send("", "plain"))
+
+		<-s.Done()
+		done <- s.Send("late", "data")
+	})
+
+	srv := httptest.NewServer(a)
+	defer srv.Close()
+
+	res, err := http.Get(srv.URL + "/events")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, ContentTypeTextEventStream, res.Header.Get("Content-Type"))
+	require.Equal(t, "no-cache", res.Header.Get("Cache-Control"))
+
+	r := bufio.NewReader(res.Body)
+	readLine := func() string {
+		line, err := r.ReadString('\n')
+		require.NoError(t, err)
+		return line
+	}
+	require.Equal(t, "event: greeting\n", readLine())
+	require.Equal(t, "data: hello\n", readLine())
+	require.Equal(t, "data: world\n", readLine())
+	require.Equal(t, "\n", readLine())
+	require.Equal(t, "data: plain\n", readLine())
+	require.Equal(t, "\n", readLine())
+	require.Equal(t, ": heartbeat\n", readLine())
+	require.Equal(t, "\n", readLine())
+
+	require.NoError(t, res.Body.Close())
+
+	select {
+	case err = <-done:
+		require.Error(t, err)
+	case <-time.After(time.Second * 5):
+		t.Fatal("client disconnection not detected")
+	}
+}
+
+func TestSSEClose(t *testing.T) {
+	rw := httptest.NewRecorder()
+	s, err := newSSE(httptest.NewRequest("GET", "/", nil).Context(), rw, 0)
+	require.NoError(t, err)
+	require.True(t, rw.Flushed)
+	require.NoError(t, s.Comment("hi"))
+	s.Close()
+	s.Close()
+	require.ErrorIs(t, s.Send("a", "b"), ErrSSEClosed)
+	require.Equal(t, ": hi\n\n", rw.Body.String())
+}
+
+func TestSSELineBreaks(t *testing.T) {
+	rw := httptest.NewRecorder()
+	s, err := newSSE(httptest.NewRequest("GET", "/", nil).Context(), rw, 0)
+	require.NoError(t, err)
+	defer s.Close()
+
+	require.ErrorIs(t, s.Send("a\ndata: injected", "b"), ErrSSEInvalidEvent)
+	require.ErrorIs(t, s.Send("a\r", "b"), ErrSSEInvalidEvent)
+	require.NoError(t, s.Send("a", "b\rc\r\nd"))
+	require.NoError(t, s.Comment("x\ny"))
+	require.Equal(t, "event: a\ndata: b\ndata: c\ndata: d\n\n: x\n: y\n\n", rw.Body.String())
+}
+
+func TestContextSSEClosedByPerform(t *testing.T) {
+	var s *SSE
+	a := Basic(WithSSEHeartbeat(time.Millisecond * 50))
+	a.HandleFunc("/events", func(ctx Context) {
+		var err error
+		s, err = ctx.SSE()
+		require.NoError(t, err)
+		require.NoError(t, s.Send("", "hello"))
+	})
+
+	res := a.TestRequest("GET", "/events", nil)
+	require.Equal(t, "data: hello\n\n", res.Body.String())
+	require.ErrorIs(t, s.Comment("late"), ErrSSEClosed)
+}
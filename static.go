@@ -0,0 +1,158 @@
+package summer
+
+import (
+	"crypto/sha256"
+	"embed"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const staticIndex = "index.html"
+
+// StaticOption configuration function for [App.Static] and [App.StaticFS]
+type StaticOption func(s *staticHandler)
+
+// StaticWithSPA a [StaticOption] serving "index.html" for unmatched paths, for single page applications
+func StaticWithSPA() StaticOption {
+	return func(s *staticHandler) {
+		s.spa = true
+	}
+}
+
+// StaticWithMaxAge a [StaticOption] setting "max-age" of "Cache-Control" for files other than "index.html", defaults to 1h
+//
+// "index.html" is always served with "Cache-Control: no-cache". A value <= 0 means "no-cache" for all files
+func StaticWithMaxAge(d time.Duration) StaticOption {
+	return func(s *staticHandler) {
+		s.maxAge = d
+	}
+}
+
+// staticHandler serve files of a [fs.FS] under a path prefix, with cache headers and ETag
+type staticHandler struct {
+	fsys   fs.FS
+	prefix string
+	spa    bool
+	maxAge time.Duration
+	// etags ETag by name of files without modification time, cached only if fsys is immutable like [embed.FS]
+	etags sync.Map
+}
+
+func newStaticHandler(prefix string, fsys fs.FS, opts ...StaticOption) *staticHandler {
+	s := &staticHandler{
+		fsys:   fsys,
+		prefix: strings.TrimSuffix(prefix, "/"),
+		maxAge: time.Hour,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// open open a regular file with name, directories are resolved to their "index.html", returns name of the file opened
+func (s *staticHandler) open(name string) (f fs.File, fi fs.FileInfo, opened string, err error) {
+	if f, err = s.fsys.Open(name); err != nil {
+		return
+	}
+	if fi, err = f.Stat(); err != nil {
+		_ = f.Close()
+		return
+	}
+	if fi.IsDir() {
+		_ = f.Close()
+		return s.open(path.Join(name, staticIndex))
+	}
+	opened = name
+	return
+}
+
+func (s *staticHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		rw.Header().Set("Allow", "GET, HEAD")
+		respondInternal(rw, "METHOD NOT ALLOWED", http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := strings.TrimPrefix(path.Clean("/"+strings.TrimPrefix(req.URL.Path, s.prefix)), "/")
+	if name == "" {
+		name = "."
+	}
+
+	f, fi, opened, err := s.open(name)
+	if err != nil && s.spa && errors.Is(err, fs.ErrNotExist) {
+		f, fi, opened, err = s.open(staticIndex)
+	}
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			http.NotFound(rw, req)
+		} else {
+			respondInternal(rw, "INTERNAL SERVER ERROR", http.StatusInternalServerError)
+		}
+		return
+	}
+	defer f.Close()
+
+	rs, ok := f.(io.ReadSeeker)
+	if !ok {
+		respondInternal(rw, "INTERNAL SERVER ERROR", http.StatusInternalServerError)
+		return
+	}
+
+	if fi.Name() == staticIndex || s.maxAge <= 0 {
+		rw.Header().Set("Cache-Control", "no-cache")
+	} else {
+		rw.Header().Set("Cache-Control", "public, max-age="+strconv.FormatInt(int64(s.maxAge/time.Second), 10))
+	}
+	etag, err := s.etag(opened, fi, rs)
+	if err != nil {
+		respondInternal(rw, "INTERNAL SERVER ERROR", http.StatusInternalServerError)
+		return
+	}
+	rw.Header().Set("ETag", etag)
+
+	http.ServeContent(rw, req, fi.Name(), fi.ModTime(), rs)
+}
+
+// etag returns ETag of file with name, from size and modification time, or hash of content if modification time is missing
+func (s *staticHandler) etag(name string, fi fs.FileInfo, rs io.ReadSeeker) (string, error) {
+	if !fi.ModTime().IsZero() {
+		return fmt.Sprintf(`W/"%x-%x"`, fi.Size(), fi.ModTime().UnixNano()), nil
+	}
+	_, immutable := s.fsys.(embed.FS)
+	if immutable {
+		if v, ok := s.etags.Load(name); ok {
+			return v.(string), nil
+		}
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, rs); err != nil {
+		return "", err
+	}
+	if _, err := rs.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	etag := fmt.Sprintf(`W/"%x"`, h.Sum(nil)[:16])
+	if immutable {
+		s.etags.Store(name, etag)
+	}
+	return etag, nil
+}
+
+func (a *app[T]) Static(prefix string, dir string, opts ...StaticOption) {
+	a.StaticFS(prefix, os.DirFS(dir), opts...)
+}
+
+func (a *app[T]) StaticFS(prefix string, fsys fs.FS, opts ...StaticOption) {
+	s := newStaticHandler(prefix, fsys, opts...)
+	a.handle(s.prefix+"/", s, a.routeDeduplication(nil))
+}
@@ -0,0 +1,105 @@
+package summer
+
+import (
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestAppStaticFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"index.html":      {Data: []byte("INDEX"), ModTime: time.Unix(1700000000, 0)},
+		"assets/app.js":   {Data: []byte("JS"), ModTime: time.Unix(1700000000, 0)},
+		"docs/index.html": {Data: []byte("DOCS"), ModTime: time.Unix(1700000000, 0)},
+	}
+
+	a := Basic()
+	a.StaticFS("/app", fsys, StaticWithSPA(), StaticWithMaxAge(time.Hour*24))
+	a.StaticFS("/plain/", fsys)
+
+	rw := a.TestRequest("GET", "/app/assets/app.js", nil)
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Equal(t, "JS", rw.Body.String())
+	require.Equal(t, "public, max-age=86400", rw.Header().Get("Cache-Control"))
+	etag := rw.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	req := httptest.NewRequest("GET", "/app/assets/app.js", nil)
+	req.Header.Set("If-None-Match", etag)
+	rw = httptest.NewRecorder()
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusNotModified, rw.Code)
+
+	rw = a.TestRequest("GET", "/app/", nil)
+	require.Equal(t, "INDEX", rw.Body.String())
+	require.Equal(t, "no-cache", rw.Header().Get("Cache-Control"))
+
+	rw = a.TestRequest("GET", "/app/users/1", nil)
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Equal(t, "INDEX", rw.Body.String())
+
+	rw = a.TestRequest("GET", "/app/docs", nil)
+	require.Equal(t, "DOCS", rw.Body.String())
+
+	rw = a.TestRequest("POST", "/app/assets/app.js", nil)
+	require.Equal(t, http.StatusMethodNotAllowed, rw.Code)
+
+	rw = a.TestRequest("GET", "/plain/users/1", nil)
+	require.Equal(t, http.StatusNotFound, rw.Code)
+
+	rw = a.TestRequest("GET", "/plain/../index.html", nil)
+	require.Equal(t, http.StatusTemporaryRedirect, rw.Code)
+
+	require.Equal(t, []string{"/app/", "/plain/"}, a.Patterns())
+}
+
+func TestAppStaticFSContentETag(t *testing.T) {
+	// no modification time, like embed.FS
+	fsys := fstest.MapFS{
+		"app.js": {Data: []byte("v1")},
+	}
+
+	a := Basic()
+	a.StaticFS("/app", fsys)
+
+	rw := a.TestRequest("GET", "/app/app.js", nil)
+	require.Equal(t, "v1", rw.Body.String())
+	etag := rw.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	req := httptest.NewRequest("GET", "/app/app.js", nil)
+	req.Header.Set("If-None-Match", etag)
+	rw = httptest.NewRecorder()
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusNotModified, rw.Code)
+
+	// content changed, size unchanged
+	fsys["app.js"] = &fstest.MapFile{Data: []byte("v2")}
+
+	req = httptest.NewRequest("GET", "/app/app.js", nil)
+	req.Header.Set("If-None-Match", etag)
+	rw = httptest.NewRecorder()
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Equal(t, "v2", rw.Body.String())
+	require.NotEqual(t, etag, rw.Header().Get("ETag"))
+}
+
+func TestAppStatic(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "hello.txt"), []byte("hello"), 0644))
+
+	a := Basic()
+	a.Static("/files", dir, StaticWithMaxAge(0))
+
+	rw := a.TestRequest("GET", "/files/hello.txt", nil)
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Equal(t, "hello", rw.Body.String())
+	require.Equal(t, "no-cache", rw.Header().Get("Cache-Control"))
+	require.Equal(t, http.StatusNotFound, a.TestRequest("GET", "/files/none.txt", nil).Code)
+}
@@ -0,0 +1,20 @@
+package summer
+
+import (
+	"io"
+	"net/http/httptest"
+)
+
+// NewTestContext create a [Context] with [ContextFactory] and a [httptest.ResponseRecorder], for unit-testing handlers without routing
+//
+// Call [Context.Perform] before inspecting the recorder
+func NewTestContext[T Context](cf ContextFactory[T], method, target string, body io.Reader) (T, *httptest.ResponseRecorder) {
+	rw := httptest.NewRecorder()
+	return cf(rw, httptest.NewRequest(method, target, body)), rw
+}
+
+func (a *app[T]) TestRequest(method, target string, body io.Reader) *httptest.ResponseRecorder {
+	rw := httptest.NewRecorder()
+	a.ServeHTTP(rw, httptest.NewRequest(method, target, body))
+	return rw
+}
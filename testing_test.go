@@ -0,0 +1,37 @@
+package summer
+
+import (
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestNewTestContext(t *testing.T) {
+	ctx, rw := NewTestContext(BasicContext, "POST", "https://example.com/test?aaa=bbb", strings.NewReader(`{"hello":"world"}`))
+	ctx.Req().Header.Set("Content-Type", "application/json")
+
+	func() {
+		defer ctx.Perform()
+		args := Bind[struct {
+			AAA   string `json:"aaa"`
+			Hello string `json:"hello"`
+		}](ctx)
+		ctx.Text(args.AAA + args.Hello)
+	}()
+
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Equal(t, "bbbworld", rw.Body.String())
+}
+
+func TestAppTestRequest(t *testing.T) {
+	a := Basic()
+	a.HandleFunc("/test", func(ctx Context) {
+		ctx.Code(http.StatusTeapot)
+		ctx.Text("OK")
+	})
+
+	rw := a.TestRequest("GET", "/test", nil)
+	require.Equal(t, http.StatusTeapot, rw.Code)
+	require.Equal(t, "OK", rw.Body.String())
+}
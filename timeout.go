@@ -0,0 +1,182 @@
+package summer
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// parseTimeoutHeader parse value of request timeout header, either a [time.Duration] like "1.5s", or seconds like "1.5"
+func parseTimeoutHeader(s string) time.Duration {
+	s = strings.TrimSpace(s)
+	if d, err := time.ParseDuration(s); err == nil {
+		return d
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return time.Duration(f * float64(time.Second))
+	}
+	return 0
+}
+
+// requestTimeoutOf returns timeout of request, the shorter of [WithRequestTimeout] and [WithRequestTimeoutHeader]
+func (opts *options) requestTimeoutOf(req *http.Request) time.Duration {
+	timeout := opts.requestTimeout
+	if opts.requestTimeoutHeader == "" {
+		return timeout
+	}
+	if d := parseTimeoutHeader(req.Header.Get(opts.requestTimeoutHeader)); d > 0 && (timeout <= 0 || d < timeout) {
+		timeout = d
+	}
+	return timeout
+}
+
+// timeoutError error responded once deadline of request set by [WithRequestTimeout] exceeded
+func timeoutError(timeout time.Duration) *Error {
+	return NewError(http.StatusGatewayTimeout, "gateway timeout").WithDetail("timeout", timeout.String())
+}
+
+// timeoutWriter a [http.ResponseWriter] guarded by deadline of request context, like [http.TimeoutHandler]
+//
+// Once deadline exceeded before headers are sent, 504 is sent immediately, and later writes of the handler are dropped
+// with [http.ErrHandlerTimeout]. Headers of the handler are kept apart, and copied when sent
+type timeoutWriter struct {
+	rw      http.ResponseWriter
+	header  http.Header
+	timeout time.Duration
+
+	mu          sync.Mutex
+	wroteHeader bool
+	timedOut    bool
+	stopped     bool
+	stopTimer   func() bool
+}
+
+// newTimeoutWriter create a [timeoutWriter] of rw, responding 504 once ctx exceeded its deadline, must be stopped after handler returns
+func newTimeoutWriter(ctx context.Context, rw http.ResponseWriter, timeout time.Duration) *timeoutWriter {
+	w := &timeoutWriter{rw: rw, header: rw.Header().Clone(), timeout: timeout}
+	w.stopTimer = context.AfterFunc(ctx, func() {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			w.respondTimeout()
+		}
+	})
+	return w
+}
+
+// respondTimeout send 504 if headers are not sent yet
+func (w *timeoutWriter) respondTimeout() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.stopped || w.wroteHeader {
+		return
+	}
+	w.timedOut = true
+	buf, _ := json.Marshal(BodyFromError(timeoutError(w.timeout)))
+	h := w.rw.Header()
+	// client receives the whole response without waiting for the handler
+	h.Set("Content-Length", strconv.Itoa(len(buf)))
+	h.Set("Content-Type", ContentTypeApplicationJSONUTF8)
+	w.rw.WriteHeader(http.StatusGatewayTimeout)
+	_, _ = w.rw.Write(buf)
+	_ = http.NewResponseController(w.rw).Flush()
+}
+
+// stop stop the timer, 504 is never sent after stop returns
+func (w *timeoutWriter) stop() {
+	w.stopTimer()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.stopped = true
+}
+
+func (w *timeoutWriter) Header() http.Header {
+	return w.header
+}
+
+// writeHeader send headers with code, must be called with lock held
+func (w *timeoutWriter) writeHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	h := w.rw.Header()
+	clear(h)
+	for k, vs := range w.header {
+		h[k] = vs
+	}
+	w.rw.WriteHeader(code)
+}
+
+func (w *timeoutWriter) WriteHeader(code int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.timedOut {
+		return
+	}
+	w.writeHeader(code)
+}
+
+func (w *timeoutWriter) Write(buf []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	w.writeHeader(http.StatusOK)
+	return w.rw.Write(buf)
+}
+
+func (w *timeoutWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.timedOut {
+		return
+	}
+	w.writeHeader(http.StatusOK)
+	_ = http.NewResponseController(w.rw).Flush()
+}
+
+func (w *timeoutWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.timedOut {
+		return nil, nil, http.ErrHandlerTimeout
+	}
+	// 504 is never sent over a hijacked connection
+	w.wroteHeader = true
+	return http.NewResponseController(w.rw).Hijack()
+}
+
+func (w *timeoutWriter) Unwrap() http.ResponseWriter {
+	return w.rw
+}
+
+// respondTimeout respond 504 with an [Error] if deadline of request context exceeded and no response is set, must be called with defer
+//
+// The 504 is usually sent by [timeoutWriter] already, and this response is dropped, but the error is still recorded.
+// Panics caused by the deadline, like [context.DeadlineExceeded] from a downstream call, are responded with 504 as well
+func respondTimeout(c Context, timeout time.Duration) {
+	if !errors.Is(c.Err(), context.DeadlineExceeded) {
+		return
+	}
+	r := recover()
+	if r == nil && responded(c) {
+		return
+	}
+	if e, ok := r.(error); r != nil && !(ok && errors.Is(e, context.DeadlineExceeded)) {
+		panic(r)
+	}
+	panic(timeoutError(timeout))
+}
@@ -0,0 +1,115 @@
+package summer
+
+import (
+	"errors"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAppRequestTimeout(t *testing.T) {
+	a := Basic(WithRequestTimeout(time.Millisecond * 10))
+	a.HandleFunc("/slow", func(ctx Context) {
+		_, ok := ctx.Deadline()
+		require.True(t, ok)
+		<-ctx.Done()
+	})
+	a.HandleFunc("/slow-panic", func(ctx Context) {
+		<-ctx.Done()
+		panic(ctx.Err())
+	})
+	a.HandleFunc("/other-panic", func(ctx Context) {
+		HaltString("bad", HaltWithBadRequest())
+	})
+	a.HandleFunc("/responded", func(ctx Context) {
+		ctx.Code(http.StatusServiceUnavailable)
+		ctx.Text("BUSY")
+	})
+	// 504 sent at deadline, while handler still running
+	var late int64
+	a.HandleFunc("/stuck", func(ctx Context) {
+		time.Sleep(time.Millisecond * 100)
+		_, err := ctx.Res().Write([]byte("LATE"))
+		if errors.Is(err, http.ErrHandlerTimeout) {
+			atomic.AddInt64(&late, 1)
+		}
+		ctx.Code(http.StatusServiceUnavailable)
+		ctx.Text("LATE")
+	})
+	a.HandleFunc("/fast", func(ctx Context) {
+		ctx.Text("OK")
+	})
+	a.HandleFunc("/unlimited", func(ctx Context) {
+		_, ok := ctx.Deadline()
+		require.False(t, ok)
+	}, WithRequestTimeout(0))
+
+	res := a.TestRequest("GET", "/slow", nil)
+	require.Equal(t, http.StatusGatewayTimeout, res.Code)
+	require.Equal(t, `{"details":{"timeout":"10ms"},"message":"gateway timeout"}`, res.Body.String())
+
+	res = a.TestRequest("GET", "/slow-panic", nil)
+	require.Equal(t, http.StatusGatewayTimeout, res.Code)
+	require.Equal(t, `{"details":{"timeout":"10ms"},"message":"gateway timeout"}`, res.Body.String())
+
+	res = a.TestRequest("GET", "/other-panic", nil)
+	require.Equal(t, http.StatusBadRequest, res.Code)
+	require.Equal(t, `{"message":"bad"}`, res.Body.String())
+
+	res = a.TestRequest("GET", "/responded", nil)
+	require.Equal(t, http.StatusServiceUnavailable, res.Code)
+	require.Equal(t, "BUSY", res.Body.String())
+
+	start := time.Now()
+	s := httptest.NewServer(a)
+	defer s.Close()
+	resp, err := http.Get(s.URL + "/stuck")
+	require.NoError(t, err)
+	buf, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	require.Less(t, time.Since(start), time.Millisecond*100)
+	require.Equal(t, http.StatusGatewayTimeout, resp.StatusCode)
+	require.Equal(t, "application/json; charset=utf-8", resp.Header.Get("Content-Type"))
+	require.Equal(t, `{"details":{"timeout":"10ms"},"message":"gateway timeout"}`, string(buf))
+
+	// later writes of handler dropped
+	s.Close()
+	require.Equal(t, int64(1), atomic.LoadInt64(&late))
+
+	require.Equal(t, "OK", a.TestRequest("GET", "/fast", nil).Body.String())
+	require.Equal(t, http.StatusOK, a.TestRequest("GET", "/unlimited", nil).Code)
+}
+
+func TestParseTimeoutHeader(t *testing.T) {
+	require.Equal(t, time.Millisecond*1500, parseTimeoutHeader("1.5s"))
+	require.Equal(t, time.Millisecond*1500, parseTimeoutHeader(" 1.5 "))
+	require.Equal(t, time.Duration(0), parseTimeoutHeader("invalid"))
+	require.Equal(t, time.Duration(0), parseTimeoutHeader(""))
+}
+
+func TestAppRequestTimeoutHeader(t *testing.T) {
+	a := Basic(WithRequestTimeoutHeader("X-Request-Timeout"), WithRequestTimeout(time.Minute))
+	a.HandleFunc("/test", func(ctx Context) {
+		deadline, ok := ctx.Deadline()
+		require.True(t, ok)
+		ctx.Text(strconv.FormatBool(time.Until(deadline) <= time.Second))
+	})
+
+	rw, req := httptest.NewRecorder(), httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set("X-Request-Timeout", "1s")
+	a.ServeHTTP(rw, req)
+	require.Equal(t, "true", rw.Body.String())
+
+	rw, req = httptest.NewRecorder(), httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set("X-Request-Timeout", "1h")
+	a.ServeHTTP(rw, req)
+	require.Equal(t, "false", rw.Body.String())
+
+	require.Equal(t, "false", a.TestRequest("GET", "/test", nil).Body.String())
+}
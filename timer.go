@@ -0,0 +1,57 @@
+package summer
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Timer collect durations of named phases, exposed as "Server-Timing" response header
+//
+// Timer is safe for concurrent use
+type Timer struct {
+	mu      sync.Mutex
+	entries []timerEntry
+}
+
+type timerEntry struct {
+	name string
+	dur  time.Duration
+}
+
+// Start start a named phase, returns a function stopping it, only stopped phases are collected
+//
+// example:
+//
+//	stop := ctx.Timer().Start("db")
+//	defer stop()
+func (t *Timer) Start(name string) func() {
+	start := time.Now()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			dur := time.Since(start)
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.entries = append(t.entries, timerEntry{name: name, dur: dur})
+		})
+	}
+}
+
+// String returns value of "Server-Timing" header, like "db;dur=5.1, cache;dur=1"
+func (t *Timer) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	sb := &strings.Builder{}
+	for _, e := range t.entries {
+		if sb.Len() > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(e.name)
+		sb.WriteString(";dur=")
+		sb.WriteString(strconv.FormatFloat(float64(e.dur)/float64(time.Millisecond), 'f', -1, 64))
+	}
+	return sb.String()
+}
@@ -0,0 +1,28 @@
+package summer
+
+import (
+	"github.com/stretchr/testify/require"
+	"regexp"
+	"testing"
+)
+
+func TestTimer(t *testing.T) {
+	a := Basic()
+	a.HandleFunc("/test", func(ctx Context) {
+		stop := ctx.Timer().Start("db")
+		stop()
+		stop()
+		ctx.Timer().Start("never")
+		func() {
+			defer ctx.Timer().Start("cache")()
+		}()
+		ctx.Text("OK")
+	})
+	res := a.TestRequest("GET", "/test", nil)
+	require.Equal(t, "OK", res.Body.String())
+	require.Regexp(t, regexp.MustCompile(`^db;dur=[0-9.]+, cache;dur=[0-9.]+$`), res.Header().Get("Server-Timing"))
+
+	a.HandleFunc("/none", func(ctx Context) {})
+	res = a.TestRequest("GET", "/none", nil)
+	require.Empty(t, res.Header().Values("Server-Timing"))
+}
@@ -0,0 +1,127 @@
+package summer
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// certReloadInterval minimum interval between checking modification of certificate files
+const certReloadInterval = time.Second
+
+// certReloader serve a certificate pair loaded from files, reloading it after files are modified
+type certReloader struct {
+	certFile string
+	keyFile  string
+	interval time.Duration
+	logger   *slog.Logger
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	modTime time.Time
+	checked time.Time
+}
+
+// newCertReloader create a [certReloader] with certificate pair loaded from certFile and keyFile
+func newCertReloader(certFile, keyFile string, logger *slog.Logger) (r *certReloader, err error) {
+	r = &certReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		interval: certReloadInterval,
+		logger:   logger,
+	}
+	var modTime time.Time
+	if modTime, err = r.stat(); err != nil {
+		return
+	}
+	err = r.load(modTime)
+	return
+}
+
+// stat returns the latest modification time of certificate files
+func (r *certReloader) stat() (modTime time.Time, err error) {
+	for _, file := range []string{r.certFile, r.keyFile} {
+		var info os.FileInfo
+		if info, err = os.Stat(file); err != nil {
+			return
+		}
+		if info.ModTime().After(modTime) {
+			modTime = info.ModTime()
+		}
+	}
+	return
+}
+
+// load load certificate pair, modTime is recorded on success
+func (r *certReloader) load(modTime time.Time) (err error) {
+	var cert tls.Certificate
+	if cert, err = tls.LoadX509KeyPair(r.certFile, r.keyFile); err != nil {
+		return
+	}
+	r.cert = &cert
+	r.modTime = modTime
+	return
+}
+
+// GetCertificate implements [tls.Config.GetCertificate], the previous certificate is kept if reloading failed
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if now := time.Now(); now.Sub(r.checked) >= r.interval {
+		r.checked = now
+
+		if modTime, err := r.stat(); err != nil {
+			r.logger.Warn("failed to check tls certificate", "cert_file", r.certFile, "key_file", r.keyFile, "error", err.Error())
+		} else if !modTime.Equal(r.modTime) {
+			if err = r.load(modTime); err != nil {
+				r.logger.Warn("failed to reload tls certificate", "cert_file", r.certFile, "key_file", r.keyFile, "error", err.Error())
+			} else {
+				r.logger.Info("tls certificate reloaded", "cert_file", r.certFile, "key_file", r.keyFile)
+			}
+		}
+	}
+
+	return r.cert, nil
+}
+
+// newTLSConfig create the [tls.Config] of server created by [App.Run], nil if [WithTLS] is not used
+func (a *app[T]) newTLSConfig() (cfg *tls.Config, err error) {
+	if a.opts.tlsCertFile == "" {
+		return
+	}
+
+	var r *certReloader
+	if r, err = newCertReloader(a.opts.tlsCertFile, a.opts.tlsKeyFile, a.opts.logger); err != nil {
+		return
+	}
+
+	cfg = &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: r.GetCertificate,
+	}
+
+	if a.opts.tlsClientCAFile == "" {
+		return
+	}
+
+	var buf []byte
+	if buf, err = os.ReadFile(a.opts.tlsClientCAFile); err != nil {
+		return
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(buf) {
+		err = errors.New("no certificate found in client ca file: " + a.opts.tlsClientCAFile)
+		return
+	}
+	cfg.ClientCAs = pool
+	cfg.ClientAuth = tls.VerifyClientCertIfGiven
+	if a.opts.tlsClientCertRequired {
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return
+}
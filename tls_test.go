@@ -0,0 +1,253 @@
+package summer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"github.com/stretchr/testify/require"
+	"io"
+	"log/slog"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testCert struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+func newTestCert(t *testing.T, cn string, serial int64, parent *testCert) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	signer, signerKey := tpl, key
+	if parent == nil {
+		tpl.IsCA = true
+		tpl.BasicConstraintsValid = true
+		tpl.KeyUsage |= x509.KeyUsageCertSign
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tpl, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return &testCert{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+func (tc *testCert) write(t *testing.T, dir string, name string) (certFile, keyFile string) {
+	certFile = filepath.Join(dir, name+".crt")
+	keyFile = filepath.Join(dir, name+".key")
+	require.NoError(t, os.WriteFile(certFile, tc.certPEM, 0600))
+	require.NoError(t, os.WriteFile(keyFile, tc.keyPEM, 0600))
+	return
+}
+
+func (tc *testCert) tlsCertificate(t *testing.T) tls.Certificate {
+	cert, err := tls.X509KeyPair(tc.certPEM, tc.keyPEM)
+	require.NoError(t, err)
+	return cert
+}
+
+func TestCertReloader(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, "ca", 1, nil)
+
+	certFile, keyFile := newTestCert(t, "server", 2, ca).write(t, dir, "server")
+
+	_, err := newCertReloader(filepath.Join(dir, "missing.crt"), keyFile, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	require.Error(t, err)
+
+	r, err := newCertReloader(certFile, keyFile, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	require.NoError(t, err)
+	r.interval = 0
+
+	cert, err := r.GetCertificate(nil)
+	require.NoError(t, err)
+	require.Equal(t, "server", cert.Leaf.Subject.CommonName)
+
+	// rotated
+	newTestCert(t, "server-rotated", 3, ca).write(t, dir, "server")
+	future := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(certFile, future, future))
+	require.NoError(t, os.Chtimes(keyFile, future, future))
+
+	cert, err = r.GetCertificate(nil)
+	require.NoError(t, err)
+	require.Equal(t, "server-rotated", cert.Leaf.Subject.CommonName)
+
+	// broken files keep the previous certificate
+	require.NoError(t, os.WriteFile(certFile, []byte("broken"), 0600))
+	future = future.Add(time.Minute)
+	require.NoError(t, os.Chtimes(certFile, future, future))
+
+	cert, err = r.GetCertificate(nil)
+	require.NoError(t, err)
+	require.Equal(t, "server-rotated", cert.Leaf.Subject.CommonName)
+
+	// not checked within interval
+	r.interval = time.Hour
+	newTestCert(t, "server-ignored", 4, ca).write(t, dir, "server")
+	future = future.Add(time.Minute)
+	require.NoError(t, os.Chtimes(certFile, future, future))
+	require.NoError(t, os.Chtimes(keyFile, future, future))
+
+	cert, err = r.GetCertificate(nil)
+	require.NoError(t, err)
+	require.Equal(t, "server-rotated", cert.Leaf.Subject.CommonName)
+}
+
+func TestAppRunTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, "ca", 1, nil)
+	caFile, _ := ca.write(t, dir, "ca")
+	certFile, keyFile := newTestCert(t, "server", 2, ca).write(t, dir, "server")
+	client := newTestCert(t, "client-1", 3, ca)
+	stranger := newTestCert(t, "client-2", 4, newTestCert(t, "other-ca", 5, nil))
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+
+	run := func(t *testing.T, required bool) string {
+		a := Basic(
+			WithStartupLog(false),
+			WithShutdownSignals(),
+			WithTLS(certFile, keyFile),
+			WithTLSClientCA(caFile, required),
+		)
+		a.HandleFunc("/whoami", func(ctx Context) {
+			if cert := ctx.ClientCert(); cert != nil {
+				ctx.Text(cert.Subject.CommonName)
+			} else {
+				ctx.Text("anonymous")
+			}
+		})
+
+		addr := freeAddr(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		chErr := make(chan error, 1)
+		go func() {
+			chErr <- a.Run(ctx, addr)
+		}()
+		t.Cleanup(func() {
+			cancel()
+			require.NoError(t, <-chErr)
+		})
+
+		require.Eventually(t, func() bool {
+			conn, err := net.Dial("tcp", addr)
+			if err != nil {
+				return false
+			}
+			_ = conn.Close()
+			return true
+		}, time.Second*5, time.Millisecond*10)
+
+		return "https://" + addr + "/whoami"
+	}
+
+	get := func(url string, certs ...tls.Certificate) (string, error) {
+		cfg := &tls.Config{RootCAs: pool}
+		if len(certs) > 0 {
+			// always present the certificate, even if not issued by acceptable CAs
+			cfg.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+				return &certs[0], nil
+			}
+		}
+		c := &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig:   cfg,
+				ForceAttemptHTTP2: true,
+			},
+		}
+		defer c.CloseIdleConnections()
+		res, err := c.Get(url)
+		if err != nil {
+			return "", err
+		}
+		defer res.Body.Close()
+		require.Equal(t, "HTTP/2.0", res.Proto)
+		buf, err := io.ReadAll(res.Body)
+		return string(buf), err
+	}
+
+	t.Run("optional", func(t *testing.T) {
+		url := run(t, false)
+
+		body, err := get(url, client.tlsCertificate(t))
+		require.NoError(t, err)
+		require.Equal(t, "client-1", body)
+
+		body, err = get(url)
+		require.NoError(t, err)
+		require.Equal(t, "anonymous", body)
+
+		_, err = get(url, stranger.tlsCertificate(t))
+		require.Error(t, err)
+	})
+
+	t.Run("required", func(t *testing.T) {
+		url := run(t, true)
+
+		body, err := get(url, client.tlsCertificate(t))
+		require.NoError(t, err)
+		require.Equal(t, "client-1", body)
+
+		_, err = get(url)
+		require.Error(t, err)
+
+		_, err = get(url, stranger.tlsCertificate(t))
+		require.Error(t, err)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		a := Basic(WithStartupLog(false), WithTLS(filepath.Join(dir, "missing.crt"), keyFile))
+		require.Error(t, a.Run(context.Background(), freeAddr(t)))
+
+		a = Basic(WithStartupLog(false), WithTLS(certFile, keyFile), WithTLSClientCA(keyFile, true))
+		require.Error(t, a.Run(context.Background(), freeAddr(t)))
+	})
+}
+
+func TestContextClientCertPlain(t *testing.T) {
+	a := Basic()
+	a.HandleFunc("/whoami", func(ctx Context) {
+		require.Nil(t, ctx.ClientCert())
+		ctx.Text("OK")
+	})
+	rw := httptest.NewRecorder()
+	a.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/whoami", nil))
+	require.Equal(t, "OK", rw.Body.String())
+}
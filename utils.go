@@ -1,14 +1,22 @@
 package summer
 
 import (
+	"bytes"
+	"context"
+	"encoding/csv"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"github.com/vmihailenco/msgpack/v5"
 	"io"
 	"mime"
+	"net"
 	"net/http"
 	"net/url"
+	"reflect"
 	"strconv"
 	"strings"
+	"sync"
 )
 
 func respondInternal(rw http.ResponseWriter, s string, code int) {
@@ -21,6 +29,75 @@ func respondInternal(rw http.ResponseWriter, s string, code int) {
 	_, _ = rw.Write(buf)
 }
 
+func respondInternalJSON(rw http.ResponseWriter, data any, code int) {
+	buf, err := json.Marshal(data)
+	if err != nil {
+		respondInternal(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	rw.Header().Set("Content-Type", ContentTypeApplicationJSONUTF8)
+	rw.Header().Set("Content-Length", strconv.Itoa(len(buf)))
+	rw.Header().Set("X-Content-Type-Options", "nosniff")
+	rw.Header().Set("Cache-Control", "no-store, no-cache, must-revalidate")
+	rw.WriteHeader(code)
+	_, _ = rw.Write(buf)
+}
+
+// normalizeIP returns bare ip address from s, stripping port and brackets, returns empty string if invalid
+func normalizeIP(s string) string {
+	s = strings.TrimSpace(s)
+	if host, _, err := net.SplitHostPort(s); err == nil {
+		s = host
+	}
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "["), "]")
+	if ip := net.ParseIP(s); ip != nil {
+		return ip.String()
+	}
+	return ""
+}
+
+// extractClientIP extract client ip from "X-Forwarded-For", "X-Real-IP" or remote address
+//
+// Ports and brackets are stripped, invalid entries are skipped, always returns a bare ip address or empty string
+func extractClientIP(req *http.Request) string {
+	if xff := req.Header.Get("X-Forwarded-For"); xff != "" {
+		for _, item := range strings.Split(xff, ",") {
+			if ip := normalizeIP(item); ip != "" {
+				return ip
+			}
+		}
+	}
+	if ip := normalizeIP(req.Header.Get("X-Real-IP")); ip != "" {
+		return ip
+	}
+	return normalizeIP(req.RemoteAddr)
+}
+
+// isNil check if v is nil or a typed nil
+func isNil(v any) bool {
+	if v == nil {
+		return true
+	}
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+		return rv.IsNil()
+	}
+	return false
+}
+
+// waitGroupWait wait for a [sync.WaitGroup], returns early if ctx is done
+func waitGroupWait(ctx context.Context, wg *sync.WaitGroup) {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+}
+
 func flattenSingleSlice[T any](s []T) any {
 	if len(s) == 1 {
 		return s[0]
@@ -28,18 +105,173 @@ func flattenSingleSlice[T any](s []T) any {
 	return s
 }
 
+// BodyDecoder decode a request body and merge the result into m
+//
+// ctx is the request context, carrying route options
+type BodyDecoder func(ctx context.Context, m map[string]any, buf []byte) (err error)
+
+var (
+	bodyDecodersLock = &sync.RWMutex{}
+	bodyDecoders     = map[string]BodyDecoder{
+		ContentTypeTextPlain:       decodeTextPlain,
+		ContentTypeApplicationJSON: decodeApplicationJSON,
+		ContentTypeFormURLEncoded:  decodeFormURLEncoded,
+		ContentTypeMsgpack:         decodeMsgpack,
+		ContentTypeXMsgpack:        decodeMsgpack,
+		ContentTypeTextCSV:         decodeTextCSV,
+	}
+)
+
+// RegisterBodyDecoder register a [BodyDecoder] for a media type, overriding existing one
+//
+// Media type is case-insensitive, without parameters like "charset"
+func RegisterBodyDecoder(contentType string, fn BodyDecoder) {
+	bodyDecodersLock.Lock()
+	defer bodyDecodersLock.Unlock()
+
+	bodyDecoders[strings.ToLower(contentType)] = fn
+}
+
+func decodeTextPlain(ctx context.Context, m map[string]any, buf []byte) (err error) {
+	m["text"] = string(buf)
+	return
+}
+
+func decodeApplicationJSON(ctx context.Context, m map[string]any, buf []byte) (err error) {
+	var j map[string]any
+	if err = json.Unmarshal(buf, &j); err != nil {
+		return
+	}
+	for k, v := range j {
+		m[k] = v
+	}
+	return
+}
+
+func decodeFormURLEncoded(ctx context.Context, m map[string]any, buf []byte) (err error) {
+	var q url.Values
+	if q, err = url.ParseQuery(string(buf)); err != nil {
+		return
+	}
+	for k, vs := range q {
+		m[k] = flattenSingleSlice(vs)
+	}
+	return
+}
+
+func decodeMsgpack(ctx context.Context, m map[string]any, buf []byte) (err error) {
+	var j map[string]any
+	if err = msgpack.Unmarshal(buf, &j); err != nil {
+		return
+	}
+	for k, v := range j {
+		m[k] = v
+	}
+	return
+}
+
+// csvCellOverhead estimated bytes of a decoded cell besides key and value, like map entry and string headers
+const csvCellOverhead = 48
+
+// decodeTextCSV decode csv body into "rows", a map per row keyed by header
+//
+// Header keys are repeated in every row, estimated size of rows is limited by [WithMaxBodyBytes], exceeding results in [ErrBodyTooLarge]
+func decodeTextCSV(ctx context.Context, m map[string]any, buf []byte) (err error) {
+	opts := optionsFromContext(ctx)
+
+	r := csv.NewReader(bytes.NewReader(buf))
+	r.Comma = opts.csvComma
+	r.Comment = opts.csvComment
+
+	var header []string
+	if header, err = r.Read(); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = nil
+			m["rows"] = []map[string]any{}
+		}
+		return
+	}
+
+	var size int64
+	rows := []map[string]any{}
+	for {
+		var record []string
+		if record, err = r.Read(); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return
+		}
+		row := make(map[string]any, len(header))
+		for i, k := range header {
+			row[k] = record[i]
+			size += int64(len(k) + len(record[i]) + csvCellOverhead)
+		}
+		if opts.maxBodyBytes > 0 && size > opts.maxBodyBytes {
+			return ErrBodyTooLarge
+		}
+		rows = append(rows, row)
+	}
+	err = nil
+	m["rows"] = rows
+	return
+}
+
+// CollisionPolicy policy of key collisions while extracting header, query and body into a single map for [Context.Bind]
+type CollisionPolicy int
+
+const (
+	// KeepLastOnCollision later value wins, in order of header, query and body
+	KeepLastOnCollision CollisionPolicy = iota
+	// KeepFirstOnCollision earlier value wins, in order of header, query and body
+	KeepFirstOnCollision
+	// ErrorOnCollision return an error on collision
+	ErrorOnCollision
+)
+
 func extractRequest(m map[string]any, req *http.Request) (err error) {
+	opts := optionsFromContext(req.Context())
+
+	set := func(k string, v any) error {
+		if _, ok := m[k]; ok {
+			switch opts.bindCollisionPolicy {
+			case KeepFirstOnCollision:
+				return nil
+			case ErrorOnCollision:
+				return fmt.Errorf("summer: key %q collided in request data", k)
+			}
+		}
+		m[k] = v
+		return nil
+	}
+
 	// header
 	for k, vs := range req.Header {
-		k = "header_" + strings.ToLower(strings.ReplaceAll(k, "-", "_"))
-		m[k] = flattenSingleSlice(vs)
+		k = opts.bindHeaderPrefix + strings.ToLower(strings.ReplaceAll(k, "-", "_"))
+		if err = set(k, flattenSingleSlice(vs)); err != nil {
+			return
+		}
 	}
 
 	// query
 	for k, vs := range req.URL.Query() {
 		v := flattenSingleSlice(vs)
-		m[k] = v
-		m["query_"+k] = v
+		if err = set(k, v); err != nil {
+			return
+		}
+		if err = set(opts.bindQueryPrefix+k, v); err != nil {
+			return
+		}
+	}
+
+	// non-file fields of parsed multipart form, whose body is already consumed
+	if req.MultipartForm != nil {
+		for k, vs := range req.MultipartForm.Value {
+			if err = set(k, flattenSingleSlice(vs)); err != nil {
+				return
+			}
+		}
+		return
 	}
 
 	// body
@@ -52,34 +284,30 @@ func extractRequest(m map[string]any, req *http.Request) (err error) {
 		return
 	}
 
+	// media type without parameters, case-insensitive
 	var contentType string
 	if contentType, _, err = mime.ParseMediaType(req.Header.Get("Content-Type")); err != nil {
 		return
 	}
 
-	switch contentType {
-	case ContentTypeTextPlain:
-		m["text"] = string(buf)
-	case ContentTypeApplicationJSON:
-		var j map[string]any
-		if err = json.Unmarshal(buf, &j); err != nil {
-			return
-		}
-		for k, v := range j {
-			m[k] = v
-		}
-	case ContentTypeFormURLEncoded:
-		var q url.Values
-		if q, err = url.ParseQuery(string(buf)); err != nil {
-			return
-		}
-		for k, vs := range q {
-			m[k] = flattenSingleSlice(vs)
-		}
-	default:
+	bodyDecodersLock.RLock()
+	decoder, ok := bodyDecoders[strings.ToLower(contentType)]
+	bodyDecodersLock.RUnlock()
+
+	if !ok {
 		err = errors.New("unsupported request body type")
 		return
 	}
 
+	body := map[string]any{}
+	if err = decoder(req.Context(), body, buf); err != nil {
+		return
+	}
+	for k, v := range body {
+		if err = set(k, v); err != nil {
+			return
+		}
+	}
+
 	return
 }
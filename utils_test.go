@@ -2,9 +2,13 @@ package summer
 
 import (
 	"bytes"
+	"context"
+	"github.com/guoyk93/rg"
 	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v5"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -13,6 +17,8 @@ func TestRespondInternal(t *testing.T) {
 	respondInternal(rw, "OK", http.StatusTeapot)
 	require.Equal(t, rw.Code, http.StatusTeapot)
 	require.Equal(t, rw.Body.String(), "OK")
+	require.Equal(t, ContentTypeTextPlainUTF8, rw.Header().Get("Content-Type"))
+	require.Equal(t, "nosniff", rw.Header().Get("X-Content-Type-Options"))
 }
 
 func TestFlattenSimpleSlice(t *testing.T) {
@@ -36,6 +42,14 @@ func TestExtractRequest(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, map[string]any{"aaa": "bbb", "header_content_type": "application/json;charset=utf-8", "hello": "world", "query_aaa": "bbb"}, m)
 
+	req = httptest.NewRequest("POST", "https://example.com/post?aaa=bbb", bytes.NewReader([]byte(`{"hello":"world"}`)))
+	req.Header.Set("Content-Type", "Application/JSON; charset=UTF-8")
+
+	m = map[string]any{}
+	err = extractRequest(m, req)
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"aaa": "bbb", "header_content_type": "Application/JSON; charset=UTF-8", "hello": "world", "query_aaa": "bbb"}, m)
+
 	req = httptest.NewRequest("POST", "https://example.com/post?aaa=bbb", bytes.NewReader([]byte(`hello=world`)))
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded;charset=utf-8")
 
@@ -52,6 +66,32 @@ func TestExtractRequest(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, map[string]any{"aaa": "bbb", "header_content_type": "text/plain;charset=utf-8", "query_aaa": "bbb", "text": "hello=world"}, m)
 
+	req = httptest.NewRequest("POST", "https://example.com/post?aaa=bbb", bytes.NewReader(rg.Must(msgpack.Marshal(map[string]any{"hello": "world"}))))
+	req.Header.Set("Content-Type", "application/msgpack")
+
+	m = map[string]any{}
+	err = extractRequest(m, req)
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"aaa": "bbb", "header_content_type": "application/msgpack", "hello": "world", "query_aaa": "bbb"}, m)
+
+	req = httptest.NewRequest("POST", "https://example.com/post?aaa=bbb", bytes.NewReader([]byte("name,age\n# comment\nalice,18\n")))
+	req.Header.Set("Content-Type", "text/csv")
+
+	m = map[string]any{}
+	err = extractRequest(m, req)
+	require.Error(t, err)
+
+	opts := defaultOptions()
+	WithCSVComment('#')(&opts)
+	req = httptest.NewRequest("POST", "https://example.com/post?aaa=bbb", bytes.NewReader([]byte("name,age\n# comment\nalice,18\n")))
+	req = req.WithContext(context.WithValue(req.Context(), contextKeyOptions{}, &opts))
+	req.Header.Set("Content-Type", "text/csv")
+
+	m = map[string]any{}
+	err = extractRequest(m, req)
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"aaa": "bbb", "header_content_type": "text/csv", "query_aaa": "bbb", "rows": []map[string]any{{"name": "alice", "age": "18"}}}, m)
+
 	req = httptest.NewRequest("POST", "https://example.com/post?aaa=bbb", bytes.NewReader([]byte(`hello=world`)))
 	req.Header.Set("Content-Type", "application/x-custom")
 
@@ -59,3 +99,95 @@ func TestExtractRequest(t *testing.T) {
 	err = extractRequest(m, req)
 	require.Error(t, err)
 }
+
+func TestExtractRequestCSVLimit(t *testing.T) {
+	body := strings.Repeat("a", 100) + ",b\n" + strings.Repeat(",\n", 10)
+
+	newRequest := func(maxBodyBytes int64) *http.Request {
+		opts := defaultOptions()
+		WithMaxBodyBytes(maxBodyBytes)(&opts)
+		req := httptest.NewRequest("POST", "https://example.com/post", strings.NewReader(body))
+		req.Header.Set("Content-Type", "text/csv")
+		return req.WithContext(context.WithValue(req.Context(), contextKeyOptions{}, &opts))
+	}
+
+	// header keys repeated in every row
+	m := map[string]any{}
+	require.ErrorIs(t, extractRequest(m, newRequest(1000)), ErrBodyTooLarge)
+
+	m = map[string]any{}
+	require.NoError(t, extractRequest(m, newRequest(0)))
+	require.Len(t, m["rows"], 10)
+
+	req := httptest.NewRequest("POST", "https://example.com/post", strings.NewReader(""))
+	m = map[string]any{}
+	require.NoError(t, decodeTextCSV(req.Context(), m, nil))
+	require.Equal(t, []map[string]any{}, m["rows"])
+}
+
+func TestExtractRequestCollision(t *testing.T) {
+	newRequest := func(opts ...Option) *http.Request {
+		o := defaultOptions()
+		for _, opt := range opts {
+			opt(&o)
+		}
+		req := httptest.NewRequest("POST", "https://example.com/post?aaa=bbb", bytes.NewReader([]byte(`{"query_aaa":"ccc"}`)))
+		req.Header.Set("Content-Type", "application/json")
+		return req.WithContext(context.WithValue(req.Context(), contextKeyOptions{}, &o))
+	}
+
+	m := map[string]any{}
+	require.NoError(t, extractRequest(m, newRequest()))
+	require.Equal(t, "ccc", m["query_aaa"])
+
+	m = map[string]any{}
+	require.NoError(t, extractRequest(m, newRequest(WithBindCollisionPolicy(KeepFirstOnCollision))))
+	require.Equal(t, "bbb", m["query_aaa"])
+
+	m = map[string]any{}
+	require.Error(t, extractRequest(m, newRequest(WithBindCollisionPolicy(ErrorOnCollision))))
+
+	m = map[string]any{}
+	require.NoError(t, extractRequest(m, newRequest(WithBindPrefixes("h_", "q_"), WithBindCollisionPolicy(ErrorOnCollision))))
+	require.Equal(t, map[string]any{"aaa": "bbb", "q_aaa": "bbb", "query_aaa": "ccc", "h_content_type": "application/json"}, m)
+}
+
+func TestExtractClientIP(t *testing.T) {
+	req := httptest.NewRequest("GET", "https://example.com/get", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	require.Equal(t, "10.0.0.1", extractClientIP(req))
+
+	req.Header.Set("X-Real-IP", "10.0.0.2")
+	require.Equal(t, "10.0.0.2", extractClientIP(req))
+
+	req.Header.Set("X-Forwarded-For", " 10.0.0.3 , 10.0.0.4")
+	require.Equal(t, "10.0.0.3", extractClientIP(req))
+}
+
+func TestExtractClientIPNormalize(t *testing.T) {
+	req := httptest.NewRequest("GET", "https://example.com/get", nil)
+	req.RemoteAddr = "[::1]:1234"
+	require.Equal(t, "::1", extractClientIP(req))
+
+	req.Header.Set("X-Forwarded-For", "unknown, [2001:db8::1]:8080, 10.0.0.1")
+	require.Equal(t, "2001:db8::1", extractClientIP(req))
+
+	req.Header.Set("X-Forwarded-For", "10.0.0.5:8080")
+	require.Equal(t, "10.0.0.5", extractClientIP(req))
+
+	req.Header.Set("X-Forwarded-For", "[::1]")
+	require.Equal(t, "::1", extractClientIP(req))
+
+	req.Header.Set("X-Forwarded-For", "garbage")
+	require.Equal(t, "::1", extractClientIP(req))
+}
+
+func TestIsNil(t *testing.T) {
+	var c Context
+	var bc *basicContext
+	require.True(t, isNil(nil))
+	require.True(t, isNil(c))
+	require.True(t, isNil(bc))
+	require.False(t, isNil(1))
+	require.False(t, isNil(&basicContext{}))
+}
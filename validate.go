@@ -0,0 +1,93 @@
+package summer
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// Validator validate data bound by [Context.Bind], set by [WithValidator], only structs and pointers to struct are validated
+//
+// Returning [ValidationErrors] results in 400 listing field errors, other errors result in 400 with message
+//
+// example, with github.com/go-playground/validator/v10:
+//
+//	v := validator.New()
+//	summer.WithValidator(summer.ValidatorFunc(func(data any) error {
+//		err := v.Struct(data)
+//		var ves validator.ValidationErrors
+//		if !errors.As(err, &ves) {
+//			return err
+//		}
+//		var out summer.ValidationErrors
+//		for _, fe := range ves {
+//			out = append(out, summer.FieldError{Field: fe.Namespace(), Message: fe.Error()})
+//		}
+//		return out
+//	}))
+type Validator interface {
+	Validate(data any) error
+}
+
+// ValidatorFunc function implementing [Validator]
+type ValidatorFunc func(data any) error
+
+func (fn ValidatorFunc) Validate(data any) error {
+	return fn(data)
+}
+
+// FieldError validation error of a field
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationErrors field errors returned by [Validator], results in 400 with field errors listed as "errors"
+type ValidationErrors []FieldError
+
+func (e ValidationErrors) Error() string {
+	items := make([]string, 0, len(e))
+	for _, fe := range e {
+		items = append(items, fe.Field+": "+fe.Message)
+	}
+	return "validation failed: " + strings.Join(items, "; ")
+}
+
+func (e ValidationErrors) StatusCode() int {
+	return http.StatusBadRequest
+}
+
+func (e ValidationErrors) ExtractExtras(m map[string]any) {
+	m["errors"] = []FieldError(e)
+}
+
+var (
+	_ withStatusCode = ValidationErrors{}
+	_ withExtract    = ValidationErrors{}
+)
+
+// isValidatable returns true if data is a struct or pointer to struct, other data like maps and slices are not validated
+func isValidatable(data any) bool {
+	t := reflect.TypeOf(data)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t != nil && t.Kind() == reflect.Struct
+}
+
+// validate validate data with v if [isValidatable], errors without status code, even wrapped, result in 400
+func validate(v Validator, data any) error {
+	if !isValidatable(data) {
+		return nil
+	}
+	err := v.Validate(data)
+	if err == nil {
+		return nil
+	}
+	var sc withStatusCode
+	if errors.As(err, &sc) {
+		return err
+	}
+	return NewHaltError(err, HaltWithBadRequest())
+}
@@ -0,0 +1,95 @@
+package summer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestContextBindValidator(t *testing.T) {
+	type args struct {
+		Name string `query:"name"`
+		Age  int    `query:"age"`
+	}
+
+	a := Basic(WithValidator(ValidatorFunc(func(data any) error {
+		o := data.(*args)
+		if o.Age < 0 {
+			return errors.New("bad age")
+		}
+		var errs ValidationErrors
+		if o.Name == "" {
+			errs = append(errs, FieldError{Field: "name", Message: "required"})
+		}
+		if len(errs) > 0 {
+			return errs
+		}
+		return nil
+	})))
+	a.HandleFunc("/test", func(ctx Context) {
+		ctx.Text(Bind[args](ctx).Name)
+	})
+
+	require.Equal(t, "alice", a.TestRequest("GET", "/test?name=alice", nil).Body.String())
+
+	res := a.TestRequest("GET", "/test", nil)
+	require.Equal(t, http.StatusBadRequest, res.Code)
+	var body struct {
+		Message string       `json:"message"`
+		Errors  []FieldError `json:"errors"`
+	}
+	require.NoError(t, json.Unmarshal(res.Body.Bytes(), &body))
+	require.Equal(t, "validation failed: name: required", body.Message)
+	require.Equal(t, []FieldError{{Field: "name", Message: "required"}}, body.Errors)
+
+	res = a.TestRequest("GET", "/test?name=alice&age=-1", nil)
+	require.Equal(t, http.StatusBadRequest, res.Code)
+	require.Contains(t, res.Body.String(), "bad age")
+}
+
+func TestContextBindValidatorTargets(t *testing.T) {
+	var validated []string
+	a := Basic(WithValidator(ValidatorFunc(func(data any) error {
+		validated = append(validated, reflect.TypeOf(data).String())
+		return fmt.Errorf("wrapped: %w", ValidationErrors{{Field: "name", Message: "required"}})
+	})))
+	a.HandleFunc("/map", func(ctx Context) {
+		ctx.JSON(Bind[map[string]any](ctx))
+	})
+	a.HandleFunc("/map-pointer", func(ctx Context) {
+		data := &map[string]any{}
+		ctx.Bind(&data)
+		ctx.JSON(data)
+	})
+	a.HandleFunc("/struct", func(ctx Context) {
+		var data struct {
+			Name string `json:"name"`
+		}
+		ctx.Bind(&data)
+		ctx.JSON(data)
+	})
+
+	post := func(path string, body string) *httptest.ResponseRecorder {
+		rw, req := httptest.NewRecorder(), httptest.NewRequest("POST", path, strings.NewReader(body))
+		req.Header.Set("Content-Type", ContentTypeApplicationJSON)
+		a.ServeHTTP(rw, req)
+		return rw
+	}
+
+	require.Equal(t, http.StatusOK, post("/map", `{"a":1}`).Code)
+	require.Equal(t, http.StatusOK, post("/map-pointer", `{"a":1}`).Code)
+	require.Empty(t, validated)
+
+	// wrapped validation errors keep status code and field errors
+	res := post("/struct", `{}`)
+	require.Equal(t, http.StatusBadRequest, res.Code)
+	require.Contains(t, res.Body.String(), `"errors":[{"field":"name","message":"required"}]`)
+	require.Len(t, validated, 1)
+	require.True(t, strings.HasPrefix(validated[0], "*struct {"))
+}
@@ -0,0 +1,34 @@
+package summer
+
+import (
+	"github.com/gorilla/websocket"
+)
+
+// WebSocketHandlerFunc handler func of a WebSocket connection, see [App.HandleWebSocket]
+type WebSocketHandlerFunc[T Context] func(ctx T, conn *websocket.Conn)
+
+// upgradeWebSocket upgrade current request to a WebSocket connection, with [websocket.Upgrader] set by [WithWebSocketUpgrader]
+//
+// On failure, an error response is already written by the upgrader
+func upgradeWebSocket(c Context) (*websocket.Conn, error) {
+	u := optionsFromContext(c.Req().Context()).webSocketUpgrader
+	if u == nil {
+		u = &websocket.Upgrader{}
+	}
+	return u.Upgrade(c.Res(), c.Req(), nil)
+}
+
+func (a *app[T]) HandleWebSocket(pattern string, fn WebSocketHandlerFunc[T], opts ...Option) {
+	a.HandleFuncUpgrade(pattern, func(ctx T) {
+		conn, err := upgradeWebSocket(ctx)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		a.mWebSockets.Inc()
+		defer a.mWebSockets.Dec()
+
+		fn(ctx, conn)
+	}, opts...)
+}
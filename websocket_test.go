@@ -0,0 +1,53 @@
+package summer
+
+import (
+	"github.com/gorilla/websocket"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAppHandleWebSocket(t *testing.T) {
+	a := Basic(WithPrometheusRegistry(prometheus.NewRegistry()))
+	a.HandleWebSocket("/ws/{name}", func(ctx Context, conn *websocket.Conn) {
+		for {
+			mt, buf, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if err = conn.WriteMessage(mt, append([]byte(ctx.PathParam("name")+": "), buf...)); err != nil {
+				return
+			}
+		}
+	})
+
+	s := httptest.NewServer(a)
+	defer s.Close()
+
+	gauge := a.(*app[Context]).mWebSockets
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(s.URL, "http")+"/ws/bob", nil)
+	require.NoError(t, err)
+
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("hello")))
+	_, buf, err := conn.ReadMessage()
+	require.NoError(t, err)
+	require.Equal(t, "bob: hello", string(buf))
+	require.Equal(t, float64(1), testutil.ToFloat64(gauge))
+
+	require.NoError(t, conn.Close())
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(gauge) == 0
+	}, time.Second, time.Millisecond*10)
+
+	// not a websocket request
+	res, err := http.Get(s.URL + "/ws/bob")
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+}
@@ -0,0 +1,152 @@
+package summer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// ErrWorkerNotRunning error of check of a worker waiting to be restarted, wrapping the last error
+var ErrWorkerNotRunning = errors.New("worker not running")
+
+// WorkerBackoff backoff of restarting failed workers, see [WithWorkerBackoff]
+type WorkerBackoff struct {
+	// Min delay before the first restart, doubled after each consecutive failure, defaults to 1 second
+	Min time.Duration
+	// Max maximum delay, defaults to 1 minute. Failures count is reset if a worker ran longer than Max
+	Max time.Duration
+}
+
+// delay returns delay before restart after consecutive failures
+func (b WorkerBackoff) delay(failures int) time.Duration {
+	d := b.Min
+	for i := 1; i < failures && d < b.Max; i++ {
+		d *= 2
+	}
+	if d > b.Max {
+		d = b.Max
+	}
+	return d
+}
+
+// worker a long-running function started by [App.Go]
+type worker struct {
+	name    string
+	fn      func(ctx context.Context) error
+	backoff WorkerBackoff
+
+	onRunning func(running bool)
+	onFailure func(err error, delay time.Duration)
+
+	mu      sync.Mutex
+	running bool
+	lastErr error
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// call call fn once, panics are recovered as errors
+func (w *worker) call(ctx context.Context) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v\n%s", r, debug.Stack())
+		}
+	}()
+	return w.fn(ctx)
+}
+
+func (w *worker) setRunning(running bool, err error) {
+	w.mu.Lock()
+	w.running, w.lastErr = running, err
+	w.mu.Unlock()
+	w.onRunning(running)
+}
+
+// loop run fn until it returns nil or ctx is done, restarting with backoff on error
+func (w *worker) loop(ctx context.Context) {
+	defer close(w.done)
+
+	var failures int
+	for {
+		w.setRunning(true, nil)
+		start := time.Now()
+		err := w.call(ctx)
+		if ctx.Err() != nil || err == nil {
+			w.setRunning(false, nil)
+			return
+		}
+		if time.Since(start) > w.backoff.Max {
+			failures = 0
+		}
+		failures++
+
+		delay := w.backoff.delay(failures)
+		w.setRunning(false, err)
+		w.onFailure(err, delay)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+func (w *worker) startup(ctx context.Context) error {
+	var wctx context.Context
+	wctx, w.cancel = context.WithCancel(context.Background())
+	w.done = make(chan struct{})
+	go w.loop(wctx)
+	return nil
+}
+
+// check fails with the last error if worker is waiting to be restarted
+func (w *worker) check(ctx context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.running && w.lastErr != nil {
+		return fmt.Errorf("%w: %w", ErrWorkerNotRunning, w.lastErr)
+	}
+	return nil
+}
+
+// shutdown cancel worker and wait until it returns or ctx is done
+func (w *worker) shutdown(ctx context.Context) error {
+	w.cancel()
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("worker %s: %w", w.name, ctx.Err())
+	}
+}
+
+func (a *app[T]) Go(name string, fn func(ctx context.Context) error) {
+	w := &worker{
+		name:    name,
+		fn:      fn,
+		backoff: a.opts.workerBackoff,
+		onRunning: func(running bool) {
+			if running {
+				a.mWorkerRunning.WithLabelValues(name).Set(1)
+			} else {
+				a.mWorkerRunning.WithLabelValues(name).Set(0)
+			}
+		},
+		onFailure: func(err error, delay time.Duration) {
+			a.mWorkerFailures.WithLabelValues(name).Inc()
+			a.opts.logger.Error("worker failed", "worker", name, "error", err.Error(), "restart_in", delay.String())
+		},
+	}
+	a.Component(name).
+		Startup(w.startup).
+		Check(w.check).
+		Shutdown(w.shutdown)
+}
@@ -0,0 +1,87 @@
+package summer
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWorkerBackoff(t *testing.T) {
+	b := WorkerBackoff{Min: time.Second, Max: time.Second * 5}
+	require.Equal(t, time.Second, b.delay(1))
+	require.Equal(t, time.Second*2, b.delay(2))
+	require.Equal(t, time.Second*4, b.delay(3))
+	require.Equal(t, time.Second*5, b.delay(4))
+	require.Equal(t, time.Second*5, b.delay(100))
+}
+
+func TestAppGo(t *testing.T) {
+	a := Basic(WithWorkerBackoff(WorkerBackoff{Min: time.Millisecond * 50, Max: time.Millisecond * 50}))
+
+	var (
+		calls     int32
+		cancelled atomic.Bool
+	)
+	a.Go("test-worker", func(ctx context.Context) error {
+		switch atomic.AddInt32(&calls, 1) {
+		case 1:
+			return errors.New("failed")
+		case 2:
+			panic("boom")
+		}
+		<-ctx.Done()
+		cancelled.Store(true)
+		return ctx.Err()
+	})
+	a.Go("test-finished", func(ctx context.Context) error {
+		return nil
+	})
+
+	check := func(name string) (err error) {
+		a.Check(context.Background(), func(n string, e error) {
+			if n == name {
+				err = e
+			}
+		})
+		return
+	}
+
+	require.NoError(t, a.Startup(context.Background()))
+
+	// failed and waiting to be restarted
+	require.Eventually(t, func() bool {
+		return errors.Is(check("test-worker"), ErrWorkerNotRunning)
+	}, time.Second, time.Millisecond)
+	require.ErrorContains(t, check("test-worker"), "failed")
+
+	// restarted after panic
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt32(&calls) == 3 && check("test-worker") == nil
+	}, time.Second, time.Millisecond*10)
+	require.NoError(t, check("test-finished"))
+
+	res := a.TestRequest("GET", "/debug/metrics", nil)
+	require.Contains(t, res.Body.String(), `summer_worker_running{worker="test-worker"} 1`)
+	require.Contains(t, res.Body.String(), `summer_worker_running{worker="test-finished"} 0`)
+	require.Contains(t, res.Body.String(), `summer_worker_failures_total{worker="test-worker"} 2`)
+
+	require.NoError(t, a.Shutdown(context.Background()))
+	require.True(t, cancelled.Load())
+	require.EqualValues(t, 3, atomic.LoadInt32(&calls))
+}
+
+func TestAppGoShutdownTimeout(t *testing.T) {
+	a := Basic()
+	a.Go("test-stuck", func(ctx context.Context) error {
+		time.Sleep(time.Millisecond * 200)
+		return nil
+	})
+	require.NoError(t, a.Startup(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	require.ErrorIs(t, a.Shutdown(ctx), context.DeadlineExceeded)
+}
@@ -0,0 +1,64 @@
+package summer
+
+import (
+	"net/http"
+	"sync"
+	"unsafe"
+)
+
+// wrappedOperations [Operation] of handlers created by [Wrap], keyed by address of closure
+var wrappedOperations sync.Map
+
+// handlerKey returns address of closure of fn, identifying a func value across copies
+func handlerKey[T Context](fn HandlerFunc[T]) unsafe.Pointer {
+	return *(*unsafe.Pointer)(unsafe.Pointer(&fn))
+}
+
+// routeOperation returns [Operation] of route, set by [WithOperation] in opts, with request and response types of [Wrap] filled if not set
+func routeOperation[T Context](fn HandlerFunc[T], opts []Option) *Operation {
+	op := operationOf(opts)
+	v, ok := wrappedOperations.Load(handlerKey(fn))
+	if !ok {
+		return op
+	}
+	wrapped := v.(Operation)
+	if op == nil {
+		return &wrapped
+	}
+	o := *op
+	if o.Request == nil {
+		o.Request = wrapped.Request
+	}
+	if o.Response == nil {
+		o.Response = wrapped.Response
+	}
+	return &o
+}
+
+// Wrap adapt fn with typed request and response into a [HandlerFunc], for an RPC-like handler style
+//
+// Req, usually a struct, is bound and validated by [Context.Bind], the returned Res is rendered with 200 by [Context.Render].
+// A returned error is responded like a panic recovered by [Context.Perform], with status code from [StatusCodeFromError]
+//
+// Req and Res are documented in OpenAPI document of the route, unless set by [WithOperation].
+// Wrap is meant to be called once per route on registration, the types of every returned handler are kept
+//
+// example:
+//
+//	a.POST("/users", summer.Wrap(func(c summer.Context, req CreateUserRequest) (User, error) {
+//		return createUser(c, req)
+//	}))
+func Wrap[T Context, Req, Res any](fn func(ctx T, req Req) (Res, error)) HandlerFunc[T] {
+	h := HandlerFunc[T](func(ctx T) {
+		var req Req
+		ctx.Bind(&req)
+		res, err := fn(ctx, req)
+		if err != nil {
+			respondError(ctx, err)
+			return
+		}
+		ctx.Render(http.StatusOK, res)
+	})
+	wrappedOperations.Store(handlerKey(h), Operation{Request: *new(Req), Response: *new(Res)})
+	return h
+}
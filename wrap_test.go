@@ -0,0 +1,62 @@
+package summer
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type testWrapRequest struct {
+	ID   string `path:"id"`
+	Name string `json:"name"`
+}
+
+type testWrapResponse struct {
+	ID       string `json:"id"`
+	Greeting string `json:"greeting"`
+}
+
+func TestWrap(t *testing.T) {
+	a := Basic(WithValidator(ValidatorFunc(func(data any) error {
+		if req, ok := data.(*testWrapRequest); ok && req.Name == "" {
+			return errors.New("name is required")
+		}
+		return nil
+	})))
+	a.POST("/users/{id}", Wrap(func(ctx Context, req testWrapRequest) (testWrapResponse, error) {
+		if req.Name == "nobody" {
+			return testWrapResponse{}, NewError(http.StatusNotFound, "user not found").WithCause(errors.New("internal"))
+		}
+		if req.Name == "broken" {
+			return testWrapResponse{}, errors.New("broken")
+		}
+		return testWrapResponse{ID: req.ID, Greeting: "hello " + req.Name}, nil
+	}))
+
+	request := func(body string) *httptest.ResponseRecorder {
+		rw, req := httptest.NewRecorder(), httptest.NewRequest("POST", "https://example.com/users/1", strings.NewReader(body))
+		req.Header.Set("Content-Type", ContentTypeApplicationJSON)
+		a.ServeHTTP(rw, req)
+		return rw
+	}
+
+	rw := request(`{"name":"summer"}`)
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.JSONEq(t, `{"id":"1","greeting":"hello summer"}`, rw.Body.String())
+
+	rw = request(`{}`)
+	require.Equal(t, http.StatusBadRequest, rw.Code)
+
+	rw = request(`{"name":"nobody"}`)
+	require.Equal(t, http.StatusNotFound, rw.Code)
+	var m map[string]any
+	require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &m))
+	require.Equal(t, "user not found", m["message"])
+
+	rw = request(`{"name":"broken"}`)
+	require.Equal(t, http.StatusInternalServerError, rw.Code)
+}